@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	_ "time/tzdata" // quiet hours are read in each user's time zone
 
 	"pbmap_api/src/internal/database"
 	"pbmap_api/src/internal/delivery/http"
@@ -27,13 +28,20 @@ func main() {
 		panic(err)
 	}
 
-	cleanupJobs := worker.StartBackgroundJobs(cfg)
-	defer cleanupJobs()
-
 	fcmRepo, err := repositories.NewFCMRepo(cfg)
 	if err != nil {
 		fmt.Printf("Warning: Failed to initialize FCM Repository: %v\n", err)
 	}
+	resilientFCM := repositories.NewResilientFCM(fcmRepo, cfg)
+
+	apnsRepo, err := repositories.NewAPNsRepo(cfg)
+	if err != nil {
+		fmt.Printf("Warning: Failed to initialize APNs Repository: %v\n", err)
+	}
+	pushSenders := usecase.PushSenders{}
+	if cfg.APNsKeyPath != "" && apnsRepo != nil {
+		pushSenders[usecase.ProviderAPNs] = apnsRepo
+	}
 
 	redisClient, err := redis.NewRedisClient(cfg)
 	if err != nil {
@@ -46,32 +54,126 @@ func main() {
 
 	userRepo := repositories.NewUserRepository(db)
 	deviceRepo := repositories.NewDeviceRepository(db)
-	userUsecase := usecase.NewUserUsecase(userRepo, deviceRepo)
-
-	alarmUsecase := usecase.NewAlarmUsecase(fcmRepo)
-	notificationUsecase := usecase.NewNotificationUsecase(fcmRepo)
+	webPushSubscriptionRepo := repositories.NewWebPushSubscriptionRepository(db)
+	webPushRepo, err := repositories.NewWebPushRepo(cfg, webPushSubscriptionRepo)
+	if err != nil {
+		fmt.Printf("Warning: Failed to initialize Web Push Repository: %v\n", err)
+	}
+	if cfg.VAPIDPrivateKey != "" && webPushRepo != nil {
+		pushSenders[usecase.ProviderWebPush] = webPushRepo
+	}
+	pushRouter := usecase.NewPushRouter(resilientFCM, pushSenders)
+	preferenceRepo := repositories.NewNotificationPreferenceRepository(db)
+	lineChannel := usecase.NewLineChannel(nil, preferenceRepo)
+	lineRepo, err := repositories.NewLineRepo(cfg)
+	if err != nil {
+		fmt.Printf("Warning: Failed to initialize LINE Repository: %v\n", err)
+	}
+	if cfg.LineChannelAccessToken != "" && lineRepo != nil {
+		lineChannel = usecase.NewLineChannel(lineRepo, preferenceRepo)
+	}
+	smsSender, err := repositories.NewSMSSender(cfg)
+	if err != nil {
+		fmt.Printf("Warning: Failed to initialize SMS sender: %v\n", err)
+	}
+	smsChannel := usecase.NewSMSChannel(smsSender, userRepo)
+	userNotificationRepo := repositories.NewUserNotificationRepository(db)
+	deliveryPreferences := usecase.NewDeliveryPreferences(preferenceRepo, cfg)
+	outboxRepo := repositories.NewOutboxRepository(db)
+	outbox := usecase.NewOutbox(outboxRepo, cfg)
+	deliveryRecordRepo := repositories.NewDeliveryRecordRepository(db)
+	deliveryLog := usecase.NewDeliveryLog(deliveryRecordRepo)
+	tm := repositories.NewTransactionManager(db)
+	topicRepo := repositories.NewTopicRepository(db)
+	topicSubscriptionRepo := repositories.NewTopicSubscriptionRepository(db)
+	tokenPruner := usecase.NewTokenPruner(deviceRepo, topicSubscriptionRepo)
+	topicUsecase := usecase.NewTopicUsecase(pushRouter, topicRepo, topicSubscriptionRepo, deviceRepo, preferenceRepo, tokenPruner)
+	userUsecase := usecase.NewUserUsecase(userRepo, deviceRepo, topicUsecase)
 
 	tokenRepo := repositories.NewTokenRepository(redisClient)
+	idempotencyRepo := repositories.NewIdempotencyRepository(redisClient)
+
+	ppRepo := repositories.NewPotentialPointRepository(db)
+	alarmRepo := repositories.NewAlarmRepository(db)
+	alarmTemplateRepo := repositories.NewAlarmTemplateRepository(db)
+	alarmTemplateUsecase := usecase.NewAlarmTemplateUsecase(alarmTemplateRepo, cfg)
+	alarmUsecase := usecase.NewAlarmUsecase(pushRouter, deviceRepo, alarmRepo, idempotencyRepo, ppRepo, alarmTemplateRepo, tokenPruner, lineChannel, smsChannel, userNotificationRepo, deliveryPreferences, outbox, deliveryLog, tm, cfg)
+	capUsecase := usecase.NewCAPUsecase(alarmUsecase, cfg)
+	alarmAckRepo := repositories.NewAlarmAckRepository(db)
+	alarmAckUsecase := usecase.NewAlarmAckUsecase(alarmRepo, alarmAckRepo, deviceRepo, userRepo)
+	drillRepo := repositories.NewDrillEnrollmentRepository(db)
+	drillUsecase := usecase.NewDrillUsecase(drillRepo)
+	notificationUsecase := usecase.NewNotificationUsecase(pushRouter, deviceRepo, userRepo, ppRepo, tokenPruner, lineChannel, userNotificationRepo, deliveryPreferences, outbox, deliveryLog, cfg)
+	jobQueue := worker.NewRedisQueue(redisClient)
+	scheduleUsecase := usecase.NewScheduleUsecase(jobQueue, idempotencyRepo, notificationUsecase, alarmUsecase, cfg)
+
 	jwtService := auth.NewJWTService(cfg.JWTSecret)
 	sessionRepo := repositories.NewSessionRepository(db)
-	tm := repositories.NewTransactionManager(db)
 	authUsecase := usecase.NewAuthService(userUsecase, tokenRepo, sessionRepo, tm, jwtService, cfg)
 
-	ppRepo := repositories.NewPotentialPointRepository(db)
+	webPushUsecase := usecase.NewWebPushUsecase(deviceRepo, webPushSubscriptionRepo, tm, cfg)
+	preferenceUsecase := usecase.NewPreferenceUsecase(userRepo, preferenceRepo, topicUsecase, cfg)
+	phoneVerificationRepo := repositories.NewPhoneVerificationRepository(redisClient)
+	phoneUsecase := usecase.NewPhoneUsecase(smsSender, userRepo, phoneVerificationRepo)
+	inboxUsecase := usecase.NewInboxUsecase(userNotificationRepo)
+	outboxUsecase := usecase.NewOutboxUsecase(outboxRepo, alarmUsecase, notificationUsecase)
+	deliveryUsecase := usecase.NewDeliveryUsecase(deliveryRecordRepo)
+	healthUsecase := usecase.NewHealthUsecase(resilientFCM)
+
 	ppUsecase := usecase.NewPotentialPointUsecase(ppRepo)
 	ppHandler := v1.NewPotentialPointHandler(ppUsecase, v)
 
+	cleanupJobs := worker.StartBackgroundJobs(cfg, worker.Dependencies{
+		Alarm:      alarmUsecase,
+		Schedule:   scheduleUsecase,
+		Queue:      jobQueue,
+		Outbox:     outboxUsecase,
+		OutboxRepo: outboxRepo,
+	})
+	defer cleanupJobs()
+
 	alarmHandler := v1.NewAlarmHandler(alarmUsecase, v)
+	alarmAckHandler := v1.NewAlarmAckHandler(alarmAckUsecase, v)
+	alarmTemplateHandler := v1.NewAlarmTemplateHandler(alarmTemplateUsecase, v)
+	capHandler := v1.NewCAPHandler(capUsecase)
+	drillHandler := v1.NewDrillHandler(drillUsecase, v)
+	scheduleHandler := v1.NewScheduleHandler(scheduleUsecase, v)
+	topicHandler := v1.NewTopicHandler(topicUsecase, v)
 	authHandler := v1.NewAuthHandler(authUsecase, v)
 	userHandler := v1.NewUserHandler(userUsecase, v, jwtService)
 	notificationHandler := v1.NewNotificationHandler(notificationUsecase, v)
+	webPushHandler := v1.NewWebPushHandler(webPushUsecase, v)
+	preferenceHandler := v1.NewPreferenceHandler(preferenceUsecase, v)
+	phoneHandler := v1.NewPhoneHandler(phoneUsecase, v)
+	inboxHandler := v1.NewInboxHandler(inboxUsecase, v)
+	outboxHandler := v1.NewOutboxHandler(outboxUsecase)
+	deliveryHandler := v1.NewDeliveryHandler(deliveryUsecase, v)
+	healthHandler := v1.NewHealthHandler(healthUsecase)
+	var fcmEmulatorHandler *v1.FCMEmulatorHandler
+	if emulator, ok := fcmRepo.(*repositories.FCMEmulator); ok {
+		fcmEmulatorHandler = v1.NewFCMEmulatorHandler(usecase.NewFCMEmulatorUsecase(emulator), v)
+	}
 
 	handlers := &http.Handlers{
 		Alarm:          alarmHandler,
+		AlarmAck:       alarmAckHandler,
+		AlarmTemplate:  alarmTemplateHandler,
+		Drill:          drillHandler,
+		Topic:          topicHandler,
+		Schedule:       scheduleHandler,
+		CAP:            capHandler,
 		Auth:           authHandler,
 		User:           userHandler,
 		Notification:   notificationHandler,
+		WebPush:        webPushHandler,
+		Preference:     preferenceHandler,
+		Phone:          phoneHandler,
+		Inbox:          inboxHandler,
+		Outbox:         outboxHandler,
+		Delivery:       deliveryHandler,
 		PotentialPoint: ppHandler,
+		Health:         healthHandler,
+		FCMEmulator:    fcmEmulatorHandler,
 	}
 
 	app := http.Router(handlers, jwtService, tokenRepo)
@@ -0,0 +1,50 @@
+// Command fakeapns runs the fake APNs server over unencrypted HTTP/2 so the
+// API can be pointed at it with APNS_ENDPOINT=http://localhost:2197.
+//
+// GET  /_fake/requests          lists the notifications received so far.
+// POST /_fake/unregister/:token makes the server answer 410 for that token.
+// POST /_fake/reset             clears both.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"pbmap_api/src/pkg/apns/apnstest"
+)
+
+func main() {
+	addr := os.Getenv("FAKE_APNS_ADDR")
+	if addr == "" {
+		addr = ":2197"
+	}
+
+	fake := apnstest.NewServer()
+	mux := http.NewServeMux()
+	mux.Handle("/3/device/", fake)
+	mux.HandleFunc("GET /_fake/requests", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(fake.Requests())
+	})
+	mux.HandleFunc("POST /_fake/unregister/{token}", func(w http.ResponseWriter, r *http.Request) {
+		fake.Unregister(strings.TrimSpace(r.PathValue("token")))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /_fake/reset", func(w http.ResponseWriter, r *http.Request) {
+		fake.Reset()
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	server := &http.Server{Addr: addr, Handler: mux, Protocols: &protocols}
+
+	fmt.Printf("Fake APNs listening on %s\n", addr)
+	if err := server.ListenAndServe(); err != nil {
+		panic(err)
+	}
+}
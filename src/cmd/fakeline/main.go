@@ -0,0 +1,44 @@
+// Command fakeline runs the fake LINE Messaging API server so the API can be
+// pointed at it with LINE_API_ENDPOINT=http://localhost:2198.
+//
+// GET  /_fake/requests lists the pushes and multicasts received so far.
+// POST /_fake/quota    makes the server answer 429 as if the monthly quota were used up.
+// POST /_fake/reset    clears both.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"pbmap_api/src/pkg/line/linetest"
+)
+
+func main() {
+	addr := os.Getenv("FAKE_LINE_ADDR")
+	if addr == "" {
+		addr = ":2198"
+	}
+
+	fake := linetest.NewServer(os.Getenv("LINE_CHANNEL_ACCESS_TOKEN"))
+	mux := http.NewServeMux()
+	mux.Handle("/v2/bot/message/", fake)
+	mux.HandleFunc("GET /_fake/requests", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(fake.Requests())
+	})
+	mux.HandleFunc("POST /_fake/quota", func(w http.ResponseWriter, r *http.Request) {
+		fake.SetQuotaExhausted(true)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /_fake/reset", func(w http.ResponseWriter, r *http.Request) {
+		fake.Reset()
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	fmt.Printf("Fake LINE Messaging API listening on %s\n", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		panic(err)
+	}
+}
@@ -7,12 +7,36 @@ import (
 )
 
 func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		&entities.User{},
 		&entities.UserSocialAccount{},
 		&entities.SpecialCredential{},
 		&entities.UserDevice{},
 		&entities.UserSession{},
 		&entities.PotentialPoint{},
+		&entities.AlarmTemplate{},
+		&entities.Alarm{},
+		&entities.AlarmAck{},
+		&entities.DrillEnrollment{},
+		&entities.Topic{},
+		&entities.TopicSubscription{},
+		&entities.WebPushSubscription{},
+		&entities.NotificationPreference{},
+		&entities.UserNotification{},
+		&entities.OutboxMessage{},
+		&entities.DeliveryRecord{},
 	)
+	if err != nil {
+		return err
+	}
+	return relabelLegacyPushProviders(db)
+}
+
+// relabelLegacyPushProviders fixes devices registered before push_provider
+// existed, when every non-android device was labelled apns while holding an
+// FCM token. APNs device tokens are 64 hex digits; anything else is FCM's.
+func relabelLegacyPushProviders(db *gorm.DB) error {
+	return db.Model(&entities.UserDevice{}).
+		Where("provider = ? AND push_token !~ ?", "apns", "^[0-9a-fA-F]{64}$").
+		Update("provider", "fcm").Error
 }
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"pbmap_api/src/internal/domain/entities"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole allows the request through only when the authenticated user has
+// one of the given roles. It must run after Protected.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("role").(string)
+		for _, allowed := range roles {
+			if role == allowed {
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(entities.APIResponse{
+			Status:  fiber.StatusForbidden,
+			Message: "Insufficient permissions",
+		})
+	}
+}
@@ -3,32 +3,89 @@ package http
 import (
 	"pbmap_api/src/internal/delivery/http/middleware"
 	v1 "pbmap_api/src/internal/delivery/http/v1"
-	"pbmap_api/src/internal/domain/entities"
 	"pbmap_api/src/internal/domain/repositories"
 	"pbmap_api/src/pkg/auth"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/expvar"
 )
 
 // Handlers holds all v1 HTTP handlers.
 type Handlers struct {
 	Alarm          *v1.AlarmHandler
+	AlarmAck       *v1.AlarmAckHandler
+	AlarmTemplate  *v1.AlarmTemplateHandler
+	Drill          *v1.DrillHandler
+	Topic          *v1.TopicHandler
+	Schedule       *v1.ScheduleHandler
+	CAP            *v1.CAPHandler
 	Auth           *v1.AuthHandler
 	User           *v1.UserHandler
 	Notification   *v1.NotificationHandler
+	WebPush        *v1.WebPushHandler
+	Preference     *v1.PreferenceHandler
+	Phone          *v1.PhoneHandler
+	Inbox          *v1.InboxHandler
+	Outbox         *v1.OutboxHandler
+	Delivery       *v1.DeliveryHandler
 	PotentialPoint *v1.PotentialPointHandler
+	Health         *v1.HealthHandler
+	FCMEmulator    *v1.FCMEmulatorHandler // nil unless FCM is emulated
 }
 
 // Router registers all routes and returns the Fiber app.
 func Router(h *Handlers, jwtService *auth.JWTService, tokenRepo repositories.TokenRepository) *fiber.App {
 	app := fiber.New()
 
+	protected := middleware.Protected(jwtService, tokenRepo)
+	officer := middleware.RequireRole("officer", "admin")
+
+	// Runtime counters such as the push token prune rate, for admins only.
+	app.Use("/debug/vars", protected, middleware.RequireRole("admin"), expvar.New())
+
 	api := app.Group("/api")
-	api.Get("/health", healthCheck)
+	api.Get("/health", h.Health.Check)
 
 	v1Group := api.Group("/v1")
 	dispatch := v1Group.Group("/dispatch")
-	dispatch.Post("/alarm", h.Alarm.Alarm)
+	dispatch.Post("/alarm", protected, officer, h.Alarm.Alarm)
+	dispatch.Get("/alarms", protected, officer, h.Alarm.List)
+	dispatch.Get("/alarms/:id", h.Alarm.Get)
+	dispatch.Put("/alarms/:id", protected, officer, h.Alarm.Update)
+	dispatch.Post("/alarms/:id/approve", protected, officer, h.Alarm.Approve)
+	dispatch.Post("/alarms/:id/reject", protected, officer, h.Alarm.Reject)
+	dispatch.Post("/alarms/:id/cancel", protected, officer, h.Alarm.Cancel)
+	dispatch.Get("/alarms/:id/cap", h.CAP.Export)
+	dispatch.Post("/cap", protected, officer, h.CAP.Ingest)
+	dispatch.Get("/cap/feed", h.CAP.Feed)
+	dispatch.Get("/alarms/:id/acks", protected, officer, h.AlarmAck.Summary)
+	dispatch.Get("/templates", protected, officer, h.AlarmTemplate.List)
+	dispatch.Post("/templates", protected, officer, h.AlarmTemplate.Create)
+	dispatch.Get("/templates/:id", protected, officer, h.AlarmTemplate.Get)
+	dispatch.Put("/templates/:id", protected, officer, h.AlarmTemplate.Update)
+	dispatch.Delete("/templates/:id", protected, officer, h.AlarmTemplate.Delete)
+	dispatch.Get("/scheduled", protected, officer, h.Schedule.List)
+	dispatch.Get("/scheduled/dead", protected, officer, h.Schedule.ListDead)
+	dispatch.Post("/scheduled/broadcasts", protected, officer, h.Schedule.ScheduleBroadcast)
+	dispatch.Post("/scheduled/alarms", protected, officer, h.Schedule.ScheduleAlarm)
+	dispatch.Put("/scheduled/:id", protected, officer, h.Schedule.Reschedule)
+	dispatch.Delete("/scheduled/:id", protected, officer, h.Schedule.Cancel)
+	dispatch.Post("/scheduled/:id/approve", protected, officer, h.Schedule.Approve)
+	dispatch.Get("/outbox/:id", protected, officer, h.Outbox.Get)
+	dispatch.Get("/deliveries/summary", protected, officer, h.Delivery.Summary)
+	dispatch.Get("/drill-group", protected, officer, h.Drill.List)
+	dispatch.Post("/drill-group", protected, officer, h.Drill.Enroll)
+	dispatch.Delete("/drill-group/:id", protected, officer, h.Drill.Unenroll)
+
+	topics := v1Group.Group("/topics")
+	topics.Get("/", h.Topic.List)
+	topics.Get("/:id", h.Topic.Get)
+	topics.Post("/", protected, officer, h.Topic.Create)
+	topics.Put("/:id", protected, officer, h.Topic.Update)
+	topics.Delete("/:id", protected, officer, h.Topic.Delete)
+
+	alarms := v1Group.Group("/alarms")
+	alarms.Post("/:id/ack", protected, h.AlarmAck.Acknowledge)
 
 	authGroup := api.Group("/auth")
 	authGroup.Post("/login", h.Auth.LoginWithSocial)
@@ -39,14 +96,34 @@ func Router(h *Handlers, jwtService *auth.JWTService, tokenRepo repositories.Tok
 	users.Post("/", h.User.Create)
 	users.Get("/", h.User.List)
 	users.Get("/me", middleware.Protected(jwtService, tokenRepo), h.User.Me)
+	users.Put("/me/devices/:id/location", protected, h.User.UpdateDeviceLocation)
+	users.Put("/me/devices/:id/push-token", protected, h.User.UpdatePushToken)
+	users.Post("/me/web-push", protected, h.WebPush.Subscribe)
+	users.Delete("/me/web-push/:id", protected, h.WebPush.Unsubscribe)
+	users.Put("/me/phone", protected, h.Phone.RequestVerification)
+	users.Post("/me/phone/verify", protected, h.Phone.Verify)
+	users.Delete("/me/phone", protected, h.Phone.Remove)
+	users.Put("/me/home-location", protected, h.User.UpdateHomeLocation)
+	users.Delete("/me/home-location", protected, h.User.DeleteHomeLocation)
+	users.Get("/me/notifications", protected, h.Inbox.List)
+	users.Get("/me/notifications/unread-count", protected, h.Inbox.UnreadCount)
+	users.Post("/me/notifications/read-all", protected, h.Inbox.MarkAllRead)
+	users.Post("/me/notifications/:id/read", protected, h.Inbox.MarkRead)
+	users.Get("/me/preferences", protected, h.Preference.Get)
+	users.Put("/me/preferences", protected, h.Preference.Update)
+	users.Get("/me/topics", protected, h.Topic.ListSubscriptions)
+	users.Post("/me/topics/:id", protected, h.Topic.Subscribe)
+	users.Delete("/me/topics/:id", protected, h.Topic.Unsubscribe)
 	users.Get("/:id", h.User.Get)
 	users.Put("/:id", h.User.Update)
 	users.Delete("/:id", h.User.Delete)
 
 	notifications := api.Group("/notifications")
 	notifications.Post("/broadcast", h.Notification.Broadcast)
+	notifications.Post("/send", protected, officer, h.Notification.Send)
 	notifications.Post("/subscribe", h.Notification.Subscribe)
 	notifications.Post("/unsubscribe", h.Notification.Unsubscribe)
+	notifications.Get("/web-push/public-key", h.WebPush.PublicKey)
 
 	pps := v1Group.Group("/potential-points")
 	pps.Post("/", middleware.Protected(jwtService, tokenRepo), h.PotentialPoint.Create)
@@ -55,12 +132,14 @@ func Router(h *Handlers, jwtService *auth.JWTService, tokenRepo repositories.Tok
 	pps.Put("/:id", middleware.Protected(jwtService, tokenRepo), h.PotentialPoint.Update)
 	pps.Delete("/:id", middleware.Protected(jwtService, tokenRepo), h.PotentialPoint.Delete)
 
-	return app
-}
+	// What the emulator recorded includes push tokens and alarm content, so
+	// even outside production it is for admins only.
+	if h.FCMEmulator != nil {
+		emulator := v1Group.Group("/dev/fcm", protected, middleware.RequireRole("admin"))
+		emulator.Get("/", h.FCMEmulator.Inspect)
+		emulator.Delete("/", h.FCMEmulator.Reset)
+		emulator.Post("/unregister", h.FCMEmulator.Unregister)
+	}
 
-func healthCheck(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
-		Status:  fiber.StatusOK,
-		Message: "OK",
-	})
+	return app
 }
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"errors"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/internal/usecase"
+	"pbmap_api/src/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AlarmAckHandler handles alarm acknowledgements.
+type AlarmAckHandler struct {
+	ackUsecase usecase.AlarmAckUsecase
+	validator  *validator.Wrapper
+}
+
+// NewAlarmAckHandler creates the alarm acknowledgement HTTP handler.
+func NewAlarmAckHandler(ackUsecase usecase.AlarmAckUsecase, v *validator.Wrapper) *AlarmAckHandler {
+	return &AlarmAckHandler{ackUsecase: ackUsecase, validator: v}
+}
+
+// Acknowledge handles POST /api/v1/alarms/:id/ack.
+func (h *AlarmAckHandler) Acknowledge(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	var req dto.AlarmAckRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	ack, err := h.ackUsecase.Acknowledge(c.Context(), c.Params("id"), userID, &req)
+	if err != nil {
+		return alarmAckErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Alarm acknowledged successfully",
+		Data:    ack,
+	})
+}
+
+// Summary handles GET /api/v1/dispatch/alarms/:id/acks.
+func (h *AlarmAckHandler) Summary(c *fiber.Ctx) error {
+	summary, err := h.ackUsecase.Summary(c.Context(), c.Params("id"))
+	if err != nil {
+		return alarmAckErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Alarm acknowledgements retrieved successfully",
+		Data:    summary,
+	})
+}
+
+func alarmAckErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrAlarmNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrAlarmAckNotTracked),
+		errors.Is(err, usecase.ErrDeviceNotFound):
+		status = fiber.StatusBadRequest
+	}
+	return c.Status(status).JSON(entities.APIResponse{
+		Status:  status,
+		Message: err.Error(),
+	})
+}
@@ -1,12 +1,16 @@
 package v1
 
 import (
+	"errors"
+	"time"
+
 	"pbmap_api/src/internal/domain/entities"
 	"pbmap_api/src/internal/dto"
 	"pbmap_api/src/internal/usecase"
 	"pbmap_api/src/pkg/validator"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 // AlarmHandler handles alarm dispatch.
@@ -39,23 +43,248 @@ func (h *AlarmHandler) Alarm(c *fiber.Ctx) error {
 	}
 
 	payload := &dto.AlarmDispatchRequest{
-		AlarmID: req.AlarmID,
-		Urgency: req.Urgency,
-		Center:  dto.AlarmCenter{Lat: req.Center.Lat, Lng: req.Center.Lng, Radius: req.Center.Radius},
-		Signal:  req.Signal,
-		Content: req.Content,
+		AlarmID:    req.AlarmID,
+		TemplateID: req.TemplateID,
+		Variables:  req.Variables,
+		Urgency:    req.Urgency,
+		Mode:       req.Mode,
+		HazardType: req.HazardType,
+		Center:     req.Center,
+		Areas:      req.Areas,
+		Signal:     req.Signal,
+		Content:    req.Content,
+		ExpiresAt:  req.ExpiresAt,
+	}
+
+	var dispatchedBy *uuid.UUID
+	if userID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		dispatchedBy = &userID
+	}
+
+	result, err := h.alarmUsecase.DispatchAlarm(c.Context(), payload, dispatchedBy)
+	if errors.Is(err, usecase.ErrAlarmNotActive) && result != nil {
+		return c.Status(fiber.StatusConflict).JSON(entities.APIResponse{
+			Status:  fiber.StatusConflict,
+			Message: err.Error(),
+			Data:    result,
+		})
+	}
+	if err != nil {
+		return alarmErrorResponse(c, err)
+	}
+
+	if result.Status == usecase.AlarmStatusPendingApproval {
+		return c.Status(fiber.StatusAccepted).JSON(entities.APIResponse{
+			Status:  fiber.StatusAccepted,
+			Message: "Alarm drafted and awaiting approval by a second officer",
+			Data:    result,
+		})
+	}
+
+	if result.DispatchStatus == usecase.AlarmDispatchQueued {
+		return c.Status(fiber.StatusAccepted).JSON(entities.APIResponse{
+			Status:  fiber.StatusAccepted,
+			Message: "Alarm queued for dispatch",
+			Data:    result,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Alarm dispatched successfully",
+		Data:    result,
+	})
+}
+
+// List handles GET /api/v1/dispatch/alarms.
+func (h *AlarmHandler) List(c *fiber.Ctx) error {
+	var query dto.AlarmListQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(query); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	// Drills are kept out of the alarm history unless asked for explicitly.
+	mode := query.Mode
+	if mode == "" {
+		mode = usecase.AlarmModeLive
+	}
+
+	filter := dto.AlarmFilter{
+		Urgency: query.Urgency,
+		Mode:    mode,
+		Status:  query.Status,
+		Lat:     query.Lat,
+		Lng:     query.Lng,
+		Radius:  query.Radius,
+		Limit:   query.Limit,
+		Offset:  query.Offset,
+	}
+	if query.From != "" {
+		from, _ := time.Parse(time.RFC3339, query.From)
+		filter.From = &from
+	}
+	if query.To != "" {
+		to, _ := time.Parse(time.RFC3339, query.To)
+		filter.To = &to
 	}
 
-	if err := h.alarmUsecase.DispatchAlarm(c.Context(), payload); err != nil {
+	alarms, err := h.alarmUsecase.FindAll(c.Context(), filter)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(entities.APIResponse{
 			Status:  fiber.StatusInternalServerError,
 			Message: err.Error(),
 		})
 	}
 
+	response := make([]dto.AlarmResponse, 0, len(alarms))
+	for _, alarm := range alarms {
+		response = append(response, dto.ToAlarmResponse(&alarm))
+	}
+
 	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
 		Status:  fiber.StatusOK,
-		Message: "Alarm dispatched successfully",
-		Data:    map[string]string{"alarm_id": req.AlarmID},
+		Message: "Alarms retrieved successfully",
+		Data:    response,
+	})
+}
+
+// Get handles GET /api/v1/dispatch/alarms/:id. It is public, as the app
+// fetches the areas of an alarm it was pushed here, so only alarms that have
+// gone out are shown and without the officers behind them.
+func (h *AlarmHandler) Get(c *fiber.Ctx) error {
+	alarm, err := h.alarmUsecase.FindPublished(c.Context(), c.Params("id"))
+	if err != nil {
+		return alarmErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Alarm retrieved successfully",
+		Data:    dto.ToPublicAlarmResponse(alarm),
+	})
+}
+
+// Update handles PUT /api/v1/dispatch/alarms/:id.
+func (h *AlarmHandler) Update(c *fiber.Ctx) error {
+	var req dto.AlarmUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	result, err := h.alarmUsecase.UpdateAlarm(c.Context(), c.Params("id"), &req)
+	if err != nil {
+		return alarmErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(entities.APIResponse{
+		Status:  fiber.StatusAccepted,
+		Message: "Alarm updated; update queued for sending",
+		Data:    result,
+	})
+}
+
+// Cancel handles POST /api/v1/dispatch/alarms/:id/cancel.
+func (h *AlarmHandler) Cancel(c *fiber.Ctx) error {
+	result, err := h.alarmUsecase.CancelAlarm(c.Context(), c.Params("id"))
+	if err != nil {
+		return alarmErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(entities.APIResponse{
+		Status:  fiber.StatusAccepted,
+		Message: "Alarm cancelled; all clear queued for sending",
+		Data:    result,
+	})
+}
+
+// Approve releases a drafted immediate or high alarm (POST /api/v1/dispatch/alarms/:id/approve).
+func (h *AlarmHandler) Approve(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	result, err := h.alarmUsecase.ApproveAlarm(c.Context(), c.Params("id"), userID)
+	if err != nil {
+		return alarmErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(entities.APIResponse{
+		Status:  fiber.StatusAccepted,
+		Message: "Alarm approved and queued for dispatch",
+		Data:    result,
+	})
+}
+
+// Reject discards a drafted alarm without sending it (POST /api/v1/dispatch/alarms/:id/reject).
+func (h *AlarmHandler) Reject(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	result, err := h.alarmUsecase.RejectAlarm(c.Context(), c.Params("id"), userID)
+	if err != nil {
+		return alarmErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Alarm rejected",
+		Data:    result,
+	})
+}
+
+func alarmErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrAlarmNotFound),
+		errors.Is(err, usecase.ErrAlarmTemplateNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidArea),
+		errors.Is(err, usecase.ErrMissingVariables),
+		errors.Is(err, usecase.ErrInvalidContent),
+		errors.Is(err, usecase.ErrAlarmEscalation):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, usecase.ErrAlarmSelfApproval):
+		status = fiber.StatusForbidden
+	case errors.Is(err, usecase.ErrAlarmNotActive),
+		errors.Is(err, usecase.ErrAlarmConflict),
+		errors.Is(err, usecase.ErrAlarmInProgress),
+		errors.Is(err, usecase.ErrAlarmNotPending),
+		errors.Is(err, usecase.ErrAlarmApprovalExpiry):
+		status = fiber.StatusConflict
+	}
+	return c.Status(status).JSON(entities.APIResponse{
+		Status:  status,
+		Message: err.Error(),
 	})
 }
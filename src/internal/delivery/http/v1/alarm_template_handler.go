@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"errors"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/internal/usecase"
+	"pbmap_api/src/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AlarmTemplateHandler handles alarm template CRUD.
+type AlarmTemplateHandler struct {
+	usecase   usecase.AlarmTemplateUsecase
+	validator *validator.Wrapper
+}
+
+// NewAlarmTemplateHandler creates the alarm template HTTP handler.
+func NewAlarmTemplateHandler(usecase usecase.AlarmTemplateUsecase, v *validator.Wrapper) *AlarmTemplateHandler {
+	return &AlarmTemplateHandler{usecase: usecase, validator: v}
+}
+
+// Create handles POST /api/v1/dispatch/templates
+func (h *AlarmTemplateHandler) Create(c *fiber.Ctx) error {
+	var req dto.CreateAlarmTemplateInput
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	var creatorID *uuid.UUID
+	if userID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		creatorID = &userID
+	}
+
+	template, err := h.usecase.Create(c.Context(), req, creatorID)
+	if err != nil {
+		return alarmTemplateErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(entities.APIResponse{
+		Status:  fiber.StatusCreated,
+		Message: "Alarm template created successfully",
+		Data:    dto.ToAlarmTemplateResponse(template),
+	})
+}
+
+// Get handles GET /api/v1/dispatch/templates/:id
+func (h *AlarmTemplateHandler) Get(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Invalid ID format",
+		})
+	}
+
+	template, err := h.usecase.FindByID(c.Context(), id)
+	if err != nil {
+		return alarmTemplateErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Alarm template retrieved successfully",
+		Data:    dto.ToAlarmTemplateResponse(template),
+	})
+}
+
+// Update handles PUT /api/v1/dispatch/templates/:id
+func (h *AlarmTemplateHandler) Update(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Invalid ID format",
+		})
+	}
+
+	var req dto.UpdateAlarmTemplateInput
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	template, err := h.usecase.Update(c.Context(), id, req)
+	if err != nil {
+		return alarmTemplateErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Alarm template updated successfully",
+		Data:    dto.ToAlarmTemplateResponse(template),
+	})
+}
+
+// Delete handles DELETE /api/v1/dispatch/templates/:id
+func (h *AlarmTemplateHandler) Delete(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Invalid ID format",
+		})
+	}
+
+	if err := h.usecase.Delete(c.Context(), id); err != nil {
+		return alarmTemplateErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Alarm template deleted successfully",
+	})
+}
+
+// List handles GET /api/v1/dispatch/templates
+func (h *AlarmTemplateHandler) List(c *fiber.Ctx) error {
+	templates, err := h.usecase.FindAll(c.Context())
+	if err != nil {
+		return alarmTemplateErrorResponse(c, err)
+	}
+
+	response := make([]dto.AlarmTemplateResponse, 0, len(templates))
+	for i := range templates {
+		response = append(response, dto.ToAlarmTemplateResponse(&templates[i]))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Alarm templates retrieved successfully",
+		Data:    response,
+	})
+}
+
+func alarmTemplateErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrAlarmTemplateNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidAlarmTemplate):
+		status = fiber.StatusBadRequest
+	}
+	return c.Status(status).JSON(entities.APIResponse{
+		Status:  status,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"encoding/xml"
+	"errors"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// CAPHandler handles Common Alerting Protocol ingest and export.
+type CAPHandler struct {
+	capUsecase usecase.CAPUsecase
+}
+
+// NewCAPHandler creates the CAP HTTP handler.
+func NewCAPHandler(capUsecase usecase.CAPUsecase) *CAPHandler {
+	return &CAPHandler{capUsecase: capUsecase}
+}
+
+// Ingest handles POST /api/v1/dispatch/cap.
+func (h *CAPHandler) Ingest(c *fiber.Ctx) error {
+	var alert dto.CAPAlert
+	if err := xml.Unmarshal(c.Body(), &alert); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Cannot parse CAP XML",
+		})
+	}
+
+	var dispatchedBy *uuid.UUID
+	if userID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		dispatchedBy = &userID
+	}
+
+	result, err := h.capUsecase.Ingest(c.Context(), &alert, dispatchedBy)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidCAP) {
+			return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+				Status:  fiber.StatusBadRequest,
+				Message: err.Error(),
+			})
+		}
+		return alarmErrorResponse(c, err)
+	}
+
+	status := fiber.StatusOK
+	if result.DispatchStatus == usecase.AlarmDispatchQueued {
+		status = fiber.StatusAccepted
+	}
+	return c.Status(status).JSON(entities.APIResponse{
+		Status:  status,
+		Message: "CAP alert processed successfully",
+		Data:    result,
+	})
+}
+
+// Export handles GET /api/v1/dispatch/alarms/:id/cap.
+func (h *CAPHandler) Export(c *fiber.Ctx) error {
+	alert, err := h.capUsecase.Export(c.Context(), c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(entities.APIResponse{
+			Status:  fiber.StatusNotFound,
+			Message: "Alarm not found",
+		})
+	}
+
+	return sendXML(c, "application/cap+xml", alert)
+}
+
+// Feed handles GET /api/v1/dispatch/cap/feed.
+func (h *CAPHandler) Feed(c *fiber.Ctx) error {
+	feed, err := h.capUsecase.ActiveFeed(c.Context(), c.BaseURL())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(entities.APIResponse{
+			Status:  fiber.StatusInternalServerError,
+			Message: err.Error(),
+		})
+	}
+
+	return sendXML(c, "application/atom+xml", feed)
+}
+
+func sendXML(c *fiber.Ctx, contentType string, v any) error {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(entities.APIResponse{
+			Status:  fiber.StatusInternalServerError,
+			Message: err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, contentType+"; charset=utf-8")
+	return c.Status(fiber.StatusOK).Send(append([]byte(xml.Header), body...))
+}
@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/internal/usecase"
+	"pbmap_api/src/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// DeliveryHandler reports delivery outcomes of alarms and notifications.
+type DeliveryHandler struct {
+	usecase   usecase.DeliveryUsecase
+	validator *validator.Wrapper
+}
+
+// NewDeliveryHandler creates the delivery report HTTP handler.
+func NewDeliveryHandler(usecase usecase.DeliveryUsecase, v *validator.Wrapper) *DeliveryHandler {
+	return &DeliveryHandler{usecase: usecase, validator: v}
+}
+
+// Summary handles GET /api/v1/dispatch/deliveries/summary. It can be narrowed
+// to one alarm (alarm_id), one send (tracking_id) and a time range.
+func (h *DeliveryHandler) Summary(c *fiber.Ctx) error {
+	var query dto.DeliverySummaryQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(query); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	filter := dto.DeliveryFilter{AlarmID: query.AlarmID}
+	if query.TrackingID != "" {
+		id, _ := uuid.Parse(query.TrackingID)
+		filter.OutboxMessageID = &id
+	}
+	if query.From != "" {
+		from, _ := time.Parse(time.RFC3339, query.From)
+		filter.From = &from
+	}
+	if query.To != "" {
+		to, _ := time.Parse(time.RFC3339, query.To)
+		filter.To = &to
+	}
+
+	summary, err := h.usecase.Summary(c.Context(), filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(entities.APIResponse{
+			Status:  fiber.StatusInternalServerError,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Delivery summary retrieved successfully",
+		Data:    summary,
+	})
+}
@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/internal/usecase"
+	"pbmap_api/src/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// DrillHandler manages drill group enrollment.
+type DrillHandler struct {
+	usecase   usecase.DrillUsecase
+	validator *validator.Wrapper
+}
+
+// NewDrillHandler creates the drill group HTTP handler.
+func NewDrillHandler(usecase usecase.DrillUsecase, v *validator.Wrapper) *DrillHandler {
+	return &DrillHandler{usecase: usecase, validator: v}
+}
+
+// Enroll handles POST /api/v1/dispatch/drill-group
+func (h *DrillHandler) Enroll(c *fiber.Ctx) error {
+	var req dto.DrillEnrollmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	var enrolledBy *uuid.UUID
+	if userID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		enrolledBy = &userID
+	}
+
+	enrollment, err := h.usecase.Enroll(c.Context(), req, enrolledBy)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(entities.APIResponse{
+			Status:  fiber.StatusInternalServerError,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(entities.APIResponse{
+		Status:  fiber.StatusCreated,
+		Message: "Enrolled in drill group successfully",
+		Data:    enrollment,
+	})
+}
+
+// List handles GET /api/v1/dispatch/drill-group
+func (h *DrillHandler) List(c *fiber.Ctx) error {
+	enrollments, err := h.usecase.FindAll(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(entities.APIResponse{
+			Status:  fiber.StatusInternalServerError,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Drill group retrieved successfully",
+		Data:    enrollments,
+	})
+}
+
+// Unenroll handles DELETE /api/v1/dispatch/drill-group/:id
+func (h *DrillHandler) Unenroll(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Invalid ID format",
+		})
+	}
+
+	if err := h.usecase.Unenroll(c.Context(), id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(entities.APIResponse{
+			Status:  fiber.StatusNotFound,
+			Message: "Drill enrollment not found",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Removed from drill group successfully",
+	})
+}
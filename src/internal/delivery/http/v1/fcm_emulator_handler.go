@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/internal/usecase"
+	"pbmap_api/src/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FCMEmulatorHandler lets integration tests see what the FCM emulator would
+// have sent. It is only routed while FCM_PROVIDER selects the emulator.
+type FCMEmulatorHandler struct {
+	usecase   usecase.FCMEmulatorUsecase
+	validator *validator.Wrapper
+}
+
+// NewFCMEmulatorHandler creates the FCM emulator HTTP handler.
+func NewFCMEmulatorHandler(usecase usecase.FCMEmulatorUsecase, v *validator.Wrapper) *FCMEmulatorHandler {
+	return &FCMEmulatorHandler{usecase: usecase, validator: v}
+}
+
+// Inspect handles GET /api/v1/dev/fcm, optionally filtered by kind, token and topic.
+func (h *FCMEmulatorHandler) Inspect(c *fiber.Ctx) error {
+	var query dto.FCMEmulatorQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(query); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "FCM emulator state retrieved successfully",
+		Data:    h.usecase.Inspect(query),
+	})
+}
+
+// Unregister handles POST /api/v1/dev/fcm/unregister.
+func (h *FCMEmulatorHandler) Unregister(c *fiber.Ctx) error {
+	var req dto.FCMUnregisterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	h.usecase.Unregister(req.Tokens)
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Tokens unregistered successfully",
+	})
+}
+
+// Reset handles DELETE /api/v1/dev/fcm.
+func (h *FCMEmulatorHandler) Reset(c *fiber.Ctx) error {
+	if err := h.usecase.Reset(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(entities.APIResponse{
+			Status:  fiber.StatusInternalServerError,
+			Message: err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "FCM emulator reset successfully",
+	})
+}
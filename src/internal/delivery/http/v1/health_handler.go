@@ -0,0 +1,34 @@
+package v1
+
+import (
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HealthHandler answers health checks.
+type HealthHandler struct {
+	usecase usecase.HealthUsecase
+}
+
+// NewHealthHandler creates the health check HTTP handler.
+func NewHealthHandler(usecase usecase.HealthUsecase) *HealthHandler {
+	return &HealthHandler{usecase: usecase}
+}
+
+// Check handles GET /api/health. A degraded API still answers 200, so that
+// an open breaker does not take instances out of rotation.
+func (h *HealthHandler) Check(c *fiber.Ctx) error {
+	health := h.usecase.Status()
+	message := "OK"
+	if health.Status == dto.HealthDegraded {
+		message = "Degraded"
+	}
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: message,
+		Data:    health,
+	})
+}
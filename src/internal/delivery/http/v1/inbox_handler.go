@@ -0,0 +1,148 @@
+package v1
+
+import (
+	"errors"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/internal/usecase"
+	"pbmap_api/src/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// InboxHandler handles the signed-in user's notification inbox.
+type InboxHandler struct {
+	usecase   usecase.InboxUsecase
+	validator *validator.Wrapper
+}
+
+// NewInboxHandler creates the inbox HTTP handler.
+func NewInboxHandler(usecase usecase.InboxUsecase, v *validator.Wrapper) *InboxHandler {
+	return &InboxHandler{usecase: usecase, validator: v}
+}
+
+// List handles GET /api/users/me/notifications.
+func (h *InboxHandler) List(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	var query dto.InboxQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(query); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	inbox, err := h.usecase.List(c.Context(), userID, query)
+	if err != nil {
+		return inboxErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Notifications retrieved successfully",
+		Data:    inbox,
+	})
+}
+
+// MarkRead handles POST /api/users/me/notifications/:id/read.
+func (h *InboxHandler) MarkRead(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Invalid ID format",
+		})
+	}
+
+	if err := h.usecase.MarkRead(c.Context(), userID, id); err != nil {
+		return inboxErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Notification marked as read",
+	})
+}
+
+// MarkAllRead handles POST /api/users/me/notifications/read-all.
+func (h *InboxHandler) MarkAllRead(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	updated, err := h.usecase.MarkAllRead(c.Context(), userID)
+	if err != nil {
+		return inboxErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "All notifications marked as read",
+		Data:    dto.MarkAllReadResponse{Updated: updated},
+	})
+}
+
+// UnreadCount handles GET /api/users/me/notifications/unread-count.
+func (h *InboxHandler) UnreadCount(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	count, err := h.usecase.UnreadCount(c.Context(), userID)
+	if err != nil {
+		return inboxErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Unread count retrieved successfully",
+		Data:    dto.UnreadCountResponse{UnreadCount: count},
+	})
+}
+
+func inboxErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrNotificationNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidCursor):
+		status = fiber.StatusBadRequest
+	}
+	return c.Status(status).JSON(entities.APIResponse{
+		Status:  status,
+		Message: err.Error(),
+	})
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"errors"
+
 	"pbmap_api/src/internal/domain/entities"
 	"pbmap_api/src/internal/dto"
 	"pbmap_api/src/internal/usecase"
@@ -38,16 +40,59 @@ func (h *NotificationHandler) Broadcast(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.notificationUsecase.Broadcast(c.Context(), req.Title, req.Body); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(entities.APIResponse{
-			Status:  fiber.StatusInternalServerError,
+	result, err := h.notificationUsecase.Broadcast(c.Context(), &req)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, usecase.ErrInvalidContent) {
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(entities.APIResponse{
+			Status:  status,
 			Message: err.Error(),
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
-		Status:  fiber.StatusOK,
-		Message: "Broadcast sent successfully",
+	return c.Status(fiber.StatusAccepted).JSON(entities.APIResponse{
+		Status:  fiber.StatusAccepted,
+		Message: "Broadcast queued for sending",
+		Data:    result,
+	})
+}
+
+// Send handles POST /api/notifications/send.
+func (h *NotificationHandler) Send(c *fiber.Ctx) error {
+	var req dto.TargetedNotificationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	result, err := h.notificationUsecase.SendToUsers(c.Context(), &req)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, usecase.ErrInvalidContent) {
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(entities.APIResponse{
+			Status:  status,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(entities.APIResponse{
+		Status:  fiber.StatusAccepted,
+		Message: "Notification queued for sending",
+		Data:    result,
 	})
 }
 
@@ -69,7 +114,7 @@ func (h *NotificationHandler) Subscribe(c *fiber.Ctx) error {
 		})
 	}
 
-	result, err := h.notificationUsecase.SubscribeToTopic(c.Context(), req.Tokens, "all_devices")
+	result, err := h.notificationUsecase.SubscribeToTopic(c.Context(), req.Tokens, usecase.BroadcastTopic)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(entities.APIResponse{
 			Status:  fiber.StatusInternalServerError,
@@ -102,7 +147,7 @@ func (h *NotificationHandler) Unsubscribe(c *fiber.Ctx) error {
 		})
 	}
 
-	result, err := h.notificationUsecase.UnsubscribeFromTopic(c.Context(), req.Tokens, "all_devices")
+	result, err := h.notificationUsecase.UnsubscribeFromTopic(c.Context(), req.Tokens, usecase.BroadcastTopic)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(entities.APIResponse{
 			Status:  fiber.StatusInternalServerError,
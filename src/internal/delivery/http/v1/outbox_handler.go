@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"errors"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// OutboxHandler reports on queued alarm and notification sends.
+type OutboxHandler struct {
+	usecase usecase.OutboxUsecase
+}
+
+// NewOutboxHandler creates the outbox HTTP handler.
+func NewOutboxHandler(usecase usecase.OutboxUsecase) *OutboxHandler {
+	return &OutboxHandler{usecase: usecase}
+}
+
+// Get handles GET /api/v1/dispatch/outbox/:id, where id is the tracking ID
+// returned when the send was accepted.
+func (h *OutboxHandler) Get(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Invalid ID format",
+		})
+	}
+
+	message, err := h.usecase.FindByID(c.Context(), id)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, usecase.ErrOutboxMessageNotFound) {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(entities.APIResponse{
+			Status:  status,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Send status retrieved successfully",
+		Data:    message,
+	})
+}
@@ -0,0 +1,137 @@
+package v1
+
+import (
+	"errors"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/internal/usecase"
+	"pbmap_api/src/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// PhoneHandler handles verification of the signed-in user's phone number.
+type PhoneHandler struct {
+	usecase   usecase.PhoneUsecase
+	validator *validator.Wrapper
+}
+
+// NewPhoneHandler creates the phone HTTP handler.
+func NewPhoneHandler(usecase usecase.PhoneUsecase, v *validator.Wrapper) *PhoneHandler {
+	return &PhoneHandler{usecase: usecase, validator: v}
+}
+
+// RequestVerification handles PUT /api/users/me/phone.
+func (h *PhoneHandler) RequestVerification(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	var req dto.RequestPhoneVerificationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	if err := h.usecase.RequestVerification(c.Context(), userID, req.PhoneNumber); err != nil {
+		return phoneErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(entities.APIResponse{
+		Status:  fiber.StatusAccepted,
+		Message: "Verification code sent",
+	})
+}
+
+// Verify handles POST /api/users/me/phone/verify.
+func (h *PhoneHandler) Verify(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	var req dto.VerifyPhoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	user, err := h.usecase.Verify(c.Context(), userID, req.Code)
+	if err != nil {
+		return phoneErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Phone number verified successfully",
+		Data:    user,
+	})
+}
+
+// Remove handles DELETE /api/users/me/phone.
+func (h *PhoneHandler) Remove(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	if err := h.usecase.Remove(c.Context(), userID); err != nil {
+		return phoneErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Phone number removed successfully",
+	})
+}
+
+func phoneErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrSMSNotConfigured):
+		status = fiber.StatusServiceUnavailable
+	case errors.Is(err, usecase.ErrVerificationNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidVerificationCode):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, usecase.ErrTooManyAttempts), errors.Is(err, usecase.ErrVerificationCooldown),
+		errors.Is(err, usecase.ErrVerificationLimit):
+		status = fiber.StatusTooManyRequests
+	}
+	return c.Status(status).JSON(entities.APIResponse{
+		Status:  status,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"errors"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/internal/usecase"
+	"pbmap_api/src/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// PreferenceHandler handles the signed-in user's notification preferences.
+type PreferenceHandler struct {
+	usecase   usecase.PreferenceUsecase
+	validator *validator.Wrapper
+}
+
+// NewPreferenceHandler creates the preference HTTP handler.
+func NewPreferenceHandler(usecase usecase.PreferenceUsecase, v *validator.Wrapper) *PreferenceHandler {
+	return &PreferenceHandler{usecase: usecase, validator: v}
+}
+
+// Get handles GET /api/users/me/preferences.
+func (h *PreferenceHandler) Get(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	preferences, err := h.usecase.Get(c.Context(), userID)
+	if err != nil {
+		return preferenceErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Preferences retrieved successfully",
+		Data:    preferences,
+	})
+}
+
+// Update handles PUT /api/users/me/preferences.
+func (h *PreferenceHandler) Update(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	var req dto.UpdatePreferencesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	preferences, err := h.usecase.Update(c.Context(), userID, &req)
+	if err != nil {
+		return preferenceErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Preferences updated successfully",
+		Data:    preferences,
+	})
+}
+
+func preferenceErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrLineNotLinked):
+		status = fiber.StatusConflict
+	case errors.Is(err, usecase.ErrTopicNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidQuietHours):
+		status = fiber.StatusBadRequest
+	}
+	return c.Status(status).JSON(entities.APIResponse{
+		Status:  status,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,203 @@
+package v1
+
+import (
+	"errors"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/internal/usecase"
+	"pbmap_api/src/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ScheduleHandler handles scheduled broadcasts and alarms.
+type ScheduleHandler struct {
+	usecase   usecase.ScheduleUsecase
+	validator *validator.Wrapper
+}
+
+// NewScheduleHandler creates the schedule HTTP handler.
+func NewScheduleHandler(usecase usecase.ScheduleUsecase, v *validator.Wrapper) *ScheduleHandler {
+	return &ScheduleHandler{usecase: usecase, validator: v}
+}
+
+// ScheduleBroadcast handles POST /api/v1/dispatch/scheduled/broadcasts
+func (h *ScheduleHandler) ScheduleBroadcast(c *fiber.Ctx) error {
+	var req dto.ScheduleBroadcastRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	job, err := h.usecase.ScheduleBroadcast(c.Context(), &req, currentUserID(c))
+	if err != nil {
+		return scheduleErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(entities.APIResponse{
+		Status:  fiber.StatusCreated,
+		Message: "Broadcast scheduled successfully",
+		Data:    job,
+	})
+}
+
+// ScheduleAlarm handles POST /api/v1/dispatch/scheduled/alarms
+func (h *ScheduleHandler) ScheduleAlarm(c *fiber.Ctx) error {
+	var req dto.ScheduleAlarmRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	job, err := h.usecase.ScheduleAlarm(c.Context(), &req, currentUserID(c))
+	if err != nil {
+		return scheduleErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(entities.APIResponse{
+		Status:  fiber.StatusCreated,
+		Message: "Alarm scheduled successfully",
+		Data:    job,
+	})
+}
+
+// List handles GET /api/v1/dispatch/scheduled
+func (h *ScheduleHandler) List(c *fiber.Ctx) error {
+	jobs, err := h.usecase.ListScheduled(c.Context())
+	if err != nil {
+		return scheduleErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Scheduled jobs retrieved successfully",
+		Data:    jobs,
+	})
+}
+
+// ListDead handles GET /api/v1/dispatch/scheduled/dead
+func (h *ScheduleHandler) ListDead(c *fiber.Ctx) error {
+	jobs, err := h.usecase.ListDead(c.Context())
+	if err != nil {
+		return scheduleErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Failed jobs retrieved successfully",
+		Data:    jobs,
+	})
+}
+
+// Reschedule handles PUT /api/v1/dispatch/scheduled/:id
+func (h *ScheduleHandler) Reschedule(c *fiber.Ctx) error {
+	var req dto.RescheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	job, err := h.usecase.Reschedule(c.Context(), c.Params("id"), req.RunAt)
+	if err != nil {
+		return scheduleErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Job rescheduled successfully",
+		Data:    job,
+	})
+}
+
+// Approve handles POST /api/v1/dispatch/scheduled/:id/approve
+func (h *ScheduleHandler) Approve(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	job, err := h.usecase.Approve(c.Context(), c.Params("id"), userID)
+	if err != nil {
+		return scheduleErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Scheduled alarm approved",
+		Data:    job,
+	})
+}
+
+// Cancel handles DELETE /api/v1/dispatch/scheduled/:id
+func (h *ScheduleHandler) Cancel(c *fiber.Ctx) error {
+	if err := h.usecase.Cancel(c.Context(), c.Params("id")); err != nil {
+		return scheduleErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Scheduled job cancelled successfully",
+	})
+}
+
+func currentUserID(c *fiber.Ctx) *uuid.UUID {
+	if userID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		return &userID
+	}
+	return nil
+}
+
+func scheduleErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrScheduledJobNotFound),
+		errors.Is(err, usecase.ErrAlarmTemplateNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidContent),
+		errors.Is(err, usecase.ErrInvalidArea),
+		errors.Is(err, usecase.ErrMissingVariables),
+		errors.Is(err, usecase.ErrJobNotApprovable):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, usecase.ErrAlarmSelfApproval):
+		status = fiber.StatusForbidden
+	}
+	return c.Status(status).JSON(entities.APIResponse{
+		Status:  status,
+		Message: err.Error(),
+	})
+}
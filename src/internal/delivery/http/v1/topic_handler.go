@@ -0,0 +1,239 @@
+package v1
+
+import (
+	"context"
+	"errors"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/internal/usecase"
+	"pbmap_api/src/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// TopicHandler handles topic CRUD and device subscriptions.
+type TopicHandler struct {
+	usecase   usecase.TopicUsecase
+	validator *validator.Wrapper
+}
+
+// NewTopicHandler creates the topic HTTP handler.
+func NewTopicHandler(usecase usecase.TopicUsecase, v *validator.Wrapper) *TopicHandler {
+	return &TopicHandler{usecase: usecase, validator: v}
+}
+
+// Create handles POST /api/v1/topics
+func (h *TopicHandler) Create(c *fiber.Ctx) error {
+	var req dto.CreateTopicInput
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	var creatorID *uuid.UUID
+	if userID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		creatorID = &userID
+	}
+
+	topic, err := h.usecase.Create(c.Context(), req, creatorID)
+	if err != nil {
+		return topicErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(entities.APIResponse{
+		Status:  fiber.StatusCreated,
+		Message: "Topic created successfully",
+		Data:    topic,
+	})
+}
+
+// Get handles GET /api/v1/topics/:id
+func (h *TopicHandler) Get(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Invalid ID format",
+		})
+	}
+
+	topic, err := h.usecase.FindByID(c.Context(), id)
+	if err != nil {
+		return topicErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Topic retrieved successfully",
+		Data:    topic,
+	})
+}
+
+// Update handles PUT /api/v1/topics/:id
+func (h *TopicHandler) Update(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Invalid ID format",
+		})
+	}
+
+	var req dto.UpdateTopicInput
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	topic, err := h.usecase.Update(c.Context(), id, req)
+	if err != nil {
+		return topicErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Topic updated successfully",
+		Data:    topic,
+	})
+}
+
+// Delete handles DELETE /api/v1/topics/:id
+func (h *TopicHandler) Delete(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Invalid ID format",
+		})
+	}
+
+	if err := h.usecase.Delete(c.Context(), id); err != nil {
+		return topicErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Topic deleted successfully",
+	})
+}
+
+// List handles GET /api/v1/topics?category=
+func (h *TopicHandler) List(c *fiber.Ctx) error {
+	topics, err := h.usecase.FindAll(c.Context(), c.Query("category"))
+	if err != nil {
+		return topicErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Topics retrieved successfully",
+		Data:    topics,
+	})
+}
+
+// Subscribe handles POST /api/users/me/topics/:id
+func (h *TopicHandler) Subscribe(c *fiber.Ctx) error {
+	return h.manageSubscription(c, h.usecase.Subscribe, "Subscribed successfully")
+}
+
+// Unsubscribe handles DELETE /api/users/me/topics/:id
+func (h *TopicHandler) Unsubscribe(c *fiber.Ctx) error {
+	return h.manageSubscription(c, h.usecase.Unsubscribe, "Unsubscribed successfully")
+}
+
+func (h *TopicHandler) manageSubscription(c *fiber.Ctx, manage func(ctx context.Context, userID, topicID uuid.UUID, deviceIDs []uuid.UUID) (*dto.TopicSubscriptionResponse, error), message string) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	topicID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Invalid ID format",
+		})
+	}
+
+	var req dto.TopicSubscriptionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+				Status:  fiber.StatusBadRequest,
+				Message: err.Error(),
+			})
+		}
+	}
+
+	result, err := manage(c.Context(), userID, topicID, req.DeviceIDs)
+	if err != nil {
+		return topicErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: message,
+		Data:    result,
+	})
+}
+
+// ListSubscriptions handles GET /api/users/me/topics
+func (h *TopicHandler) ListSubscriptions(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	topics, err := h.usecase.ListSubscriptions(c.Context(), userID)
+	if err != nil {
+		return topicErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Subscriptions retrieved successfully",
+		Data:    topics,
+	})
+}
+
+func topicErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrTopicNotFound), errors.Is(err, usecase.ErrDeviceNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidTopic):
+		status = fiber.StatusBadRequest
+	}
+	return c.Status(status).JSON(entities.APIResponse{
+		Status:  status,
+		Message: err.Error(),
+	})
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"errors"
+
 	"pbmap_api/src/internal/domain/entities"
 	"pbmap_api/src/internal/dto"
 	"pbmap_api/src/internal/usecase"
@@ -183,3 +185,167 @@ func (h *UserHandler) Me(c *fiber.Ctx) error {
 		Data:    user,
 	})
 }
+
+// UpdateDeviceLocation handles PUT /api/users/me/devices/:id/location.
+func (h *UserHandler) UpdateDeviceLocation(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	deviceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Invalid ID format",
+		})
+	}
+
+	var req dto.UpdateDeviceLocationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	if err := h.usecase.UpdateDeviceLocation(c.Context(), userID, deviceID, req.Lat, req.Lng); err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, usecase.ErrDeviceNotFound) {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(entities.APIResponse{
+			Status:  status,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Device location updated successfully",
+	})
+}
+
+// UpdateHomeLocation handles PUT /api/users/me/home-location.
+func (h *UserHandler) UpdateHomeLocation(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	var req dto.UpdateHomeLocationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	if err := h.usecase.UpdateHomeLocation(c.Context(), userID, &req.Lat, &req.Lng); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(entities.APIResponse{
+			Status:  fiber.StatusInternalServerError,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Home location updated successfully",
+	})
+}
+
+// DeleteHomeLocation handles DELETE /api/users/me/home-location.
+func (h *UserHandler) DeleteHomeLocation(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	if err := h.usecase.UpdateHomeLocation(c.Context(), userID, nil, nil); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(entities.APIResponse{
+			Status:  fiber.StatusInternalServerError,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Home location removed successfully",
+	})
+}
+
+// UpdatePushToken handles PUT /api/users/me/devices/:id/push-token.
+func (h *UserHandler) UpdatePushToken(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	deviceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Invalid ID format",
+		})
+	}
+
+	var req dto.UpdatePushTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	if err := h.usecase.RotatePushToken(c.Context(), userID, deviceID, req.PushToken); err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, usecase.ErrDeviceNotFound) {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(entities.APIResponse{
+			Status:  status,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Push token updated successfully",
+	})
+}
@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"errors"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/internal/usecase"
+	"pbmap_api/src/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// WebPushHandler handles browser Web Push registration.
+type WebPushHandler struct {
+	usecase   usecase.WebPushUsecase
+	validator *validator.Wrapper
+}
+
+// NewWebPushHandler creates the Web Push HTTP handler.
+func NewWebPushHandler(usecase usecase.WebPushUsecase, v *validator.Wrapper) *WebPushHandler {
+	return &WebPushHandler{usecase: usecase, validator: v}
+}
+
+// PublicKey handles GET /api/notifications/web-push/public-key.
+func (h *WebPushHandler) PublicKey(c *fiber.Ctx) error {
+	key, err := h.usecase.PublicKey()
+	if err != nil {
+		return webPushErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "VAPID public key retrieved successfully",
+		Data:    dto.VAPIDPublicKeyResponse{PublicKey: key},
+	})
+}
+
+// Subscribe handles POST /api/users/me/web-push.
+func (h *WebPushHandler) Subscribe(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	var req dto.WebPushSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	if errors := h.validator.Validate(req); len(errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Validation failed",
+			Data:    errors,
+		})
+	}
+
+	device, err := h.usecase.Subscribe(c.Context(), userID, &req)
+	if err != nil {
+		return webPushErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Web push subscription saved successfully",
+		Data:    device,
+	})
+}
+
+// Unsubscribe handles DELETE /api/users/me/web-push/:id.
+func (h *WebPushHandler) Unsubscribe(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(entities.APIResponse{
+			Status:  fiber.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	deviceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(entities.APIResponse{
+			Status:  fiber.StatusBadRequest,
+			Message: "Invalid ID format",
+		})
+	}
+
+	if err := h.usecase.Unsubscribe(c.Context(), userID, deviceID); err != nil {
+		return webPushErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entities.APIResponse{
+		Status:  fiber.StatusOK,
+		Message: "Web push subscription removed successfully",
+	})
+}
+
+func webPushErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrWebPushNotConfigured):
+		status = fiber.StatusServiceUnavailable
+	case errors.Is(err, usecase.ErrInvalidSubscription):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, usecase.ErrDeviceNotFound):
+		status = fiber.StatusNotFound
+	}
+	return c.Status(status).JSON(entities.APIResponse{
+		Status:  status,
+		Message: err.Error(),
+	})
+}
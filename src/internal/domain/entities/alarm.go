@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+type Alarm struct {
+	ID               uuid.UUID                             `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	AlarmID          string                                `gorm:"type:varchar(255);uniqueIndex;not null"`
+	Urgency          string                                `gorm:"type:varchar(20);index;not null;comment:immediate, high, normal, low"`
+	Mode             string                                `gorm:"type:varchar(10);index;not null;default:live;comment:live, drill"`
+	TemplateID       *uuid.UUID                            `gorm:"type:uuid;comment:template the alarm was rendered from"`
+	HazardType       string                                `gorm:"type:varchar(50);index;comment:e.g. flood, fire; users can opt out by hazard type"`
+	Latitude         float64                               `gorm:"type:decimal(10,8);not null"`
+	Longitude        float64                               `gorm:"type:decimal(11,8);not null"`
+	Radius           int                                   `gorm:"not null;comment:meters"`
+	CircleTargeted   bool                                  `gorm:"not null;default:true;comment:false when the circle only covers Areas"`
+	Areas            datatypes.JSON                        `gorm:"type:jsonb;comment:GeoJSON Polygon/MultiPolygon geometries"`
+	Signal           string                                `gorm:"type:varchar(255);not null"`
+	Content          string                                `gorm:"type:text;not null;comment:content in the default locale"`
+	LocalizedContent datatypes.JSONType[map[string]string] `gorm:"type:jsonb;comment:locale -> content"`
+	DispatchedBy     *uuid.UUID                            `gorm:"type:uuid"`
+	PayloadHash      string                                `gorm:"type:varchar(64);comment:sha256 of the dispatch request"`
+	DispatchStatus   string                                `gorm:"type:varchar(20);index;comment:queued, sent, partial, failed"`
+	DispatchError    string                                `gorm:"type:text"`
+	TargetedDevices  int                                   `gorm:"not null;default:0"`
+	SuccessCount     int                                   `gorm:"not null;default:0"`
+	FailureCount     int                                   `gorm:"not null;default:0"`
+	MessageIDs       datatypes.JSONSlice[string]           `gorm:"type:jsonb"`
+	DispatchedAt     *time.Time                            `gorm:"index"`
+	TrackingID       *uuid.UUID                            `gorm:"type:uuid;comment:outbox message of the initial send"`
+	Status           string                                `gorm:"type:varchar(20);index;not null;default:active;comment:pending_approval, active, cancelled, expired, rejected, approval_expired"`
+	ExpiresAt        *time.Time                            `gorm:"index"`
+	CancelledAt      *time.Time
+
+	// Two-person approval for immediate and high urgency alarms. DispatchedBy
+	// is the officer who drafted the alarm; ApprovedBy must be someone else.
+	ApprovalExpiresAt *time.Time `gorm:"index"`
+	ApprovedBy        *uuid.UUID `gorm:"type:uuid"`
+	ApprovedAt        *time.Time
+	RejectedBy        *uuid.UUID `gorm:"type:uuid"`
+	RejectedAt        *time.Time
+
+	Dispatcher *User     `gorm:"foreignKey:DispatchedBy"`
+	Approver   *User     `gorm:"foreignKey:ApprovedBy"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AlarmAck struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	AlarmID        uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_alarm_acks_alarm_user" json:"alarm_id"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_alarm_acks_alarm_user" json:"user_id"`
+	DeviceID       *uuid.UUID `gorm:"type:uuid" json:"device_id,omitempty"`
+	ReceivedAt     *time.Time `json:"received_at,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	SafetyStatus   string     `gorm:"type:varchar(20);comment:safe, need_help" json:"safety_status,omitempty"` // safe, need_help
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relations
+	User  *User  `gorm:"foreignKey:UserID" json:"-"`
+	Alarm *Alarm `gorm:"foreignKey:AlarmID" json:"-"`
+}
@@ -0,0 +1,24 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// AlarmTemplate holds the defaults for a recurring hazard. Signal and Content
+// may contain {{placeholders}} that are filled in at dispatch time.
+type AlarmTemplate struct {
+	ID            uuid.UUID                             `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Name          string                                `gorm:"type:varchar(255);uniqueIndex;not null"`
+	Urgency       string                                `gorm:"type:varchar(20);not null;comment:immediate, high, normal, low"`
+	HazardType    string                                `gorm:"type:varchar(50)"`
+	Signal        string                                `gorm:"type:varchar(255);not null"`
+	Content       datatypes.JSONType[map[string]string] `gorm:"type:jsonb;not null;comment:locale -> content"`
+	DefaultRadius int                                   `gorm:"not null;default:0;comment:meters"`
+	CreatedBy     *uuid.UUID                            `gorm:"type:uuid"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
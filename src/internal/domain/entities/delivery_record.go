@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeliveryRecord is the outcome of sending one outbox message to one target:
+// a push token, an FCM topic, a LINE user or a phone. Every attempt is kept, so a message
+// retried by the outbox has a record per attempt.
+type DeliveryRecord struct {
+	ID                uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	OutboxMessageID   uuid.UUID  `gorm:"type:uuid;not null;index;comment:tracking ID of the send" json:"tracking_id"`
+	Kind              string     `gorm:"type:varchar(20);not null;comment:alarm, broadcast, targeted" json:"kind"`
+	AlarmID           *string    `gorm:"index" json:"alarm_id,omitempty"`
+	AlarmStatus       string     `gorm:"type:varchar(20);comment:new, update, cancel, expired" json:"alarm_status,omitempty"`
+	Attempt           int        `gorm:"not null" json:"attempt"`
+	Provider          string     `gorm:"type:varchar(20);not null;comment:fcm, apns, webpush, line, sms" json:"provider"`
+	TargetType        string     `gorm:"type:varchar(20);not null;comment:token, topic, user" json:"target_type"`
+	Target            string     `gorm:"type:text;not null;comment:push token, topic, LINE user ID or phone number" json:"target"`
+	UserID            *uuid.UUID `gorm:"type:uuid" json:"user_id,omitempty"`
+	DeviceID          *uuid.UUID `gorm:"type:uuid" json:"device_id,omitempty"`
+	DeviceType        string     `gorm:"type:varchar(20);comment:ios, android, web; empty for LINE and SMS" json:"device_type,omitempty"`
+	ProviderMessageID string     `gorm:"type:text" json:"provider_message_id,omitempty"`
+	Status            string     `gorm:"type:varchar(20);not null;comment:sent, failed" json:"status"`
+	ErrorCode         string     `gorm:"type:varchar(50)" json:"error_code,omitempty"`
+	ErrorReason       string     `gorm:"type:text" json:"error_reason,omitempty"`
+	SentAt            *time.Time `json:"sent_at,omitempty"`
+	FailedAt          *time.Time `json:"failed_at,omitempty"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
+}
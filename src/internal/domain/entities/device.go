@@ -7,10 +7,16 @@ import (
 )
 
 type UserDevice struct {
-	ID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	UserID     uuid.UUID `json:"user_id"`
-	PushToken  string    `gorm:"unique;comment:Generic token for FCM/APNs" json:"push_token"`
-	Provider   string    `gorm:"comment:fcm, apns" json:"provider"`            // fcm, apns
-	DeviceType string    `gorm:"comment:ios, android, web" json:"device_type"` // ios, android, web
-	LastSeen   time.Time `gorm:"default:now()" json:"last_seen"`
+	ID                uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID            uuid.UUID  `json:"user_id"`
+	PushToken         string     `gorm:"unique;comment:Generic token for FCM/APNs" json:"push_token"`
+	Provider          string     `gorm:"comment:fcm, apns" json:"provider"`            // fcm, apns
+	DeviceType        string     `gorm:"comment:ios, android, web" json:"device_type"` // ios, android, web
+	LastSeen          time.Time  `gorm:"default:now()" json:"last_seen"`
+	Locale            string     `gorm:"type:varchar(10);comment:preferred content locale, e.g. th, en, my, lo" json:"locale,omitempty"`
+	Latitude          *float64   `gorm:"type:decimal(10,8);index:idx_user_devices_location" json:"latitude,omitempty"`
+	Longitude         *float64   `gorm:"type:decimal(11,8);index:idx_user_devices_location" json:"longitude,omitempty"`
+	LocationUpdatedAt *time.Time `json:"location_updated_at,omitempty"`
+	InvalidatedAt     *time.Time `gorm:"index;comment:set when the provider reported the push token dead" json:"invalidated_at,omitempty"`
+	InvalidReason     string     `gorm:"type:varchar(50)" json:"invalid_reason,omitempty"`
 }
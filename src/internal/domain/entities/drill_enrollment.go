@@ -0,0 +1,17 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DrillEnrollment puts a user (all of their devices) or a single device in the
+// drill group. Drill alarms are delivered to the drill group only.
+type DrillEnrollment struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID     *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"user_id,omitempty"`
+	DeviceID   *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"device_id,omitempty"`
+	EnrolledBy *uuid.UUID `gorm:"type:uuid" json:"enrolled_by,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
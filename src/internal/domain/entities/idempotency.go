@@ -0,0 +1,8 @@
+package entities
+
+// IdempotencyRecord is the stored outcome of a request keyed by an idempotency key.
+// Response is nil while the original request is still being processed.
+type IdempotencyRecord struct {
+	PayloadHash string `json:"payload_hash"`
+	Response    []byte `json:"response,omitempty"`
+}
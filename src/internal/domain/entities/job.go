@@ -0,0 +1,25 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job is a send scheduled on the delayed job queue. Payload holds the request
+// for the job's Type, e.g. a broadcast or alarm dispatch request.
+type Job struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"` // broadcast, alarm
+	Payload     json.RawMessage `json:"payload"`
+	RunAt       time.Time       `json:"run_at"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	CreatedBy   *uuid.UUID      `json:"created_by,omitempty"`
+	ApprovedBy  *uuid.UUID      `json:"approved_by,omitempty"`
+	ApprovedAt  *time.Time      `json:"approved_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	FailedAt    *time.Time      `json:"failed_at,omitempty"` // set once the job is moved to the dead-letter list
+}
@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// NotificationPreference holds how a user wants to be notified. Users without
+// a row get the defaults: push and SMS on, LINE off, nothing muted and no
+// quiet hours.
+type NotificationPreference struct {
+	UserID        uuid.UUID                      `gorm:"type:uuid;primaryKey" json:"user_id"`
+	PushEnabled   bool                           `gorm:"not null;default:true" json:"push_enabled"`
+	LineEnabled   bool                           `gorm:"not null;default:false;comment:send alarms and broadcasts as LINE messages" json:"line_enabled"`
+	SMSEnabled    bool                           `gorm:"not null;default:true;comment:send alarms by SMS when no device can receive push" json:"sms_enabled"`
+	MutedTopicIDs datatypes.JSONSlice[uuid.UUID] `gorm:"type:jsonb;comment:topics the user's devices are kept out of" json:"muted_topic_ids"`
+	MutedHazards  datatypes.JSONSlice[string]    `gorm:"type:jsonb;comment:hazard types of alarms the user does not want" json:"muted_hazards"`
+	QuietStart    string                         `gorm:"type:varchar(5);comment:HH:MM in TimeZone; empty when quiet hours are off" json:"quiet_start,omitempty"`
+	QuietEnd      string                         `gorm:"type:varchar(5);comment:HH:MM in TimeZone" json:"quiet_end,omitempty"`
+	TimeZone      string                         `gorm:"type:varchar(64);comment:IANA time zone of the quiet hours" json:"time_zone,omitempty"`
+	CreatedAt     time.Time                      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time                      `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
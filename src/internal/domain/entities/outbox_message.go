@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// OutboxMessage is a send waiting to be made, written in the same transaction
+// as the record it belongs to so that an accepted request is never lost. The
+// worker delivers it, retrying with backoff; its ID is the tracking ID handed
+// back to the caller.
+type OutboxMessage struct {
+	ID            uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Kind          string         `gorm:"type:varchar(20);not null;comment:alarm, broadcast, targeted" json:"kind"`
+	Payload       datatypes.JSON `gorm:"type:jsonb;not null" json:"-"`
+	Status        string         `gorm:"type:varchar(20);not null;default:pending;index:idx_outbox_messages_due,priority:1;comment:pending, sent, failed" json:"status"`
+	Attempts      int            `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts   int            `gorm:"not null" json:"max_attempts"`
+	NextAttemptAt time.Time      `gorm:"not null;index:idx_outbox_messages_due,priority:2" json:"next_attempt_at"`
+	LockedUntil   *time.Time     `gorm:"comment:lease of the worker delivering the message" json:"-"`
+	LastError     string         `gorm:"type:text" json:"last_error,omitempty"`
+	Result        datatypes.JSON `gorm:"type:jsonb;comment:outcome of the send once delivered" json:"result,omitempty"`
+	CreatedBy     *uuid.UUID     `gorm:"type:uuid" json:"created_by,omitempty"`
+	SentAt        *time.Time     `json:"sent_at,omitempty"`
+	FailedAt      *time.Time     `json:"failed_at,omitempty"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
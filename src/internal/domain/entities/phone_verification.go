@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+// PhoneVerification is a code sent by SMS to a number a user wants to add.
+// Only a hash of the code is kept.
+type PhoneVerification struct {
+	PhoneNumber string    `json:"phone_number"`
+	CodeHash    string    `json:"code_hash"`
+	Attempts    int       `json:"attempts"`
+	SentAt      time.Time `json:"sent_at"`
+}
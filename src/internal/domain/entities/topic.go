@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Topic is a named FCM topic, e.g. per province, hazard type or language.
+type Topic struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name        string     `gorm:"type:varchar(100);uniqueIndex;not null;comment:FCM topic name" json:"name"`
+	Category    string     `gorm:"type:varchar(20);index;comment:province, hazard, language, general" json:"category"` // province, hazard, language, general
+	DisplayName string     `json:"display_name"`
+	Description string     `json:"description,omitempty"`
+	CreatedBy   *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TopicSubscription records that a device is subscribed to a topic, so the
+// subscription can be moved to a new push token when the old one rotates.
+type TopicSubscription struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	TopicID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_topic_subscriptions_topic_device" json:"topic_id"`
+	DeviceID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_topic_subscriptions_topic_device;index" json:"device_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relations
+	Topic  *Topic      `gorm:"foreignKey:TopicID;constraint:OnDelete:CASCADE" json:"topic,omitempty"`
+	Device *UserDevice `gorm:"foreignKey:DeviceID;constraint:OnDelete:CASCADE" json:"device,omitempty"`
+}
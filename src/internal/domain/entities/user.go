@@ -7,13 +7,17 @@ import (
 )
 
 type User struct {
-	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	Email       *string    `gorm:"unique" json:"email"`
-	DisplayName string     `json:"display_name"`
-	Role        string     `gorm:"type:varchar(20);comment:citizen, officer, admin" json:"role"` // citizen, officer, admin
-	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
-	DeletedAt   *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+	ID              uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Email           *string    `gorm:"unique" json:"email"`
+	DisplayName     string     `json:"display_name"`
+	Role            string     `gorm:"type:varchar(20);comment:citizen, officer, admin" json:"role"` // citizen, officer, admin
+	PhoneNumber     *string    `gorm:"type:varchar(20);index;comment:E.164, set once verified" json:"phone_number,omitempty"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty"`
+	HomeLatitude    *float64   `gorm:"type:decimal(10,8);index:idx_users_home_location;comment:places users without a located device in alarm areas" json:"home_latitude,omitempty"`
+	HomeLongitude   *float64   `gorm:"type:decimal(11,8);index:idx_users_home_location" json:"home_longitude,omitempty"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt       *time.Time `gorm:"index" json:"deleted_at,omitempty"`
 
 	// Relations
 	SocialAccounts    []UserSocialAccount `gorm:"foreignKey:UserID" json:"social_accounts,omitempty"`
@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserNotification is a message kept in a user's inbox, so it can be read
+// after the push notification itself was dismissed.
+type UserNotification struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index:idx_user_notifications_inbox,priority:1;index:idx_user_notifications_unread,where:read_at IS NULL" json:"-"`
+	Kind        string     `gorm:"type:varchar(20);not null;comment:broadcast, alarm, targeted" json:"kind"`
+	Title       string     `gorm:"type:text;not null" json:"title"`
+	Body        string     `gorm:"type:text;not null" json:"body"`
+	Locale      string     `gorm:"type:varchar(10)" json:"locale"`
+	AlarmID     *string    `gorm:"type:varchar(255);index;comment:alarm_id for alarm notifications" json:"alarm_id,omitempty"`
+	AlarmStatus string     `gorm:"type:varchar(20);comment:new, update, cancel, expired" json:"alarm_status,omitempty"`
+	ReadAt      *time.Time `json:"read_at"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime;index:idx_user_notifications_inbox,priority:2,sort:desc" json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebPushSubscription holds a browser's PushSubscription for a web device. The
+// endpoint doubles as the device's push token.
+type WebPushSubscription struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	DeviceID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"device_id"`
+	Endpoint  string    `gorm:"not null;uniqueIndex" json:"endpoint"`
+	P256dh    string    `gorm:"not null;comment:base64url user agent public key" json:"-"`
+	Auth      string    `gorm:"not null;comment:base64url authentication secret" json:"-"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relations
+	Device *UserDevice `gorm:"foreignKey:DeviceID;constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type AlarmAckRepository interface {
+	FindByAlarmAndUser(ctx context.Context, alarmID, userID uuid.UUID) (*entities.AlarmAck, error)
+	Save(ctx context.Context, ack *entities.AlarmAck) error
+	FindByAlarm(ctx context.Context, alarmID uuid.UUID) ([]entities.AlarmAck, error)
+}
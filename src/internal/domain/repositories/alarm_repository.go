@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AlarmRepository interface {
+	Create(ctx context.Context, alarm *entities.Alarm) error
+	Update(ctx context.Context, alarm *entities.Alarm) error
+	// UpdateIfStatus saves alarm only while its stored status is still status,
+	// reporting whether it did.
+	UpdateIfStatus(ctx context.Context, alarm *entities.Alarm, status string) (bool, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*entities.Alarm, error)
+	FindByAlarmID(ctx context.Context, alarmID string) (*entities.Alarm, error)
+	FindAll(ctx context.Context, filter dto.AlarmFilter) ([]entities.Alarm, error)
+	FindExpired(ctx context.Context, now time.Time) ([]entities.Alarm, error)
+	FindExpiredApprovals(ctx context.Context, now time.Time) ([]entities.Alarm, error)
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type AlarmTemplateRepository interface {
+	Create(ctx context.Context, template *entities.AlarmTemplate) error
+	FindByID(ctx context.Context, id uuid.UUID) (*entities.AlarmTemplate, error)
+	Update(ctx context.Context, template *entities.AlarmTemplate) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindAll(ctx context.Context) ([]entities.AlarmTemplate, error)
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+type DeliveryRecordRepository interface {
+	CreateBatch(ctx context.Context, records []entities.DeliveryRecord) error
+	// FindSent returns the provider and target of every record sent for the
+	// outbox message, over all attempts.
+	FindSent(ctx context.Context, outboxMessageID uuid.UUID) ([]entities.DeliveryRecord, error)
+	// Summarize counts the records matching filter per group, one of the
+	// dto.DeliveryGroup values.
+	Summarize(ctx context.Context, filter dto.DeliveryFilter, group string) ([]dto.DeliveryStatsRow, error)
+}
@@ -3,8 +3,20 @@ package repositories
 import (
 	"context"
 	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/pkg/geo"
+
+	"github.com/google/uuid"
 )
 
 type DeviceRepository interface {
 	UpsertDevice(ctx context.Context, device *entities.UserDevice) error
+	UpdateLocation(ctx context.Context, userID, deviceID uuid.UUID, lat, lng float64) error
+	UpdatePushToken(ctx context.Context, deviceID uuid.UUID, token string) error
+	InvalidatePushTokens(ctx context.Context, tokens []string, reason string) ([]uuid.UUID, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*entities.UserDevice, error)
+	FindWithinBounds(ctx context.Context, bounds geo.Bounds) ([]entities.UserDevice, error)
+	FindDrillEnrolled(ctx context.Context) ([]entities.UserDevice, error)
+	// FindByPushTokens returns the devices still holding any of tokens.
+	FindByPushTokens(ctx context.Context, tokens []string) ([]entities.UserDevice, error)
+	FindByUserIDs(ctx context.Context, userIDs []uuid.UUID) ([]entities.UserDevice, error)
 }
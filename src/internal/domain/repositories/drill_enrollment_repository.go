@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type DrillEnrollmentRepository interface {
+	Create(ctx context.Context, enrollment *entities.DrillEnrollment) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindAll(ctx context.Context) ([]entities.DrillEnrollment, error)
+}
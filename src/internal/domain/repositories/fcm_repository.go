@@ -6,8 +6,26 @@ import (
 )
 
 type FCMRepository interface {
-	BroadcastNotification(ctx context.Context, title, body string) error
-	SendAlarm(ctx context.Context, req *dto.AlarmDispatchRequest) error
+	PushSender
+	// SendNotificationToTopic sends msg to every token subscribed to topic
+	// and returns the FCM message ID.
+	SendNotificationToTopic(ctx context.Context, msg *dto.NotificationMessage, topic string) (string, error)
 	SubscribeToTopic(ctx context.Context, tokens []string, topic string) (*dto.TopicManagementResponse, error)
 	UnsubscribeFromTopic(ctx context.Context, tokens []string, topic string) (*dto.TopicManagementResponse, error)
 }
+
+// CircuitBreakerReporter is implemented by senders guarded by a circuit breaker.
+type CircuitBreakerReporter interface {
+	BreakerStatus() dto.BreakerStatus
+}
+
+// FCMInspector exposes what an emulated FCM was asked to do, so that tests
+// can assert on what would have been sent.
+type FCMInspector interface {
+	Calls(query dto.FCMEmulatorQuery) []dto.FCMEmulatorCall
+	Topics() map[string][]string
+	// Unregister makes later sends to tokens fail as unregistered.
+	Unregister(tokens []string)
+	// Reset forgets every call, subscription and unregistered token.
+	Reset() error
+}
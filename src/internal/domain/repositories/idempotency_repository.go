@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"time"
+)
+
+type IdempotencyRepository interface {
+	// Reserve claims key for payloadHash. It returns nil when the key was free
+	// and is now held by the caller, or the existing record otherwise. The
+	// reservation lapses after ttl unless Complete replaces it.
+	Reserve(ctx context.Context, key, payloadHash string, ttl time.Duration) (*entities.IdempotencyRecord, error)
+	// Complete stores the outcome under key, kept for ttl.
+	Complete(ctx context.Context, key string, record *entities.IdempotencyRecord, ttl time.Duration) error
+	Release(ctx context.Context, key string) error
+}
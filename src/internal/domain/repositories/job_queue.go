@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"pbmap_api/src/internal/domain/entities"
+	"time"
+)
+
+var ErrJobNotFound = errors.New("scheduled job not found")
+
+// JobQueue is the delayed job queue as seen by the API. Jobs are consumed by
+// the worker package, which also provides the Redis implementation.
+type JobQueue interface {
+	Enqueue(ctx context.Context, job *entities.Job) error
+	// Get returns a job that is still waiting to run, or ErrJobNotFound.
+	Get(ctx context.Context, id string) (*entities.Job, error)
+	// Update stores changes to a waiting job other than its run time.
+	Update(ctx context.Context, job *entities.Job) error
+	Reschedule(ctx context.Context, id string, runAt time.Time) error
+	Cancel(ctx context.Context, id string) error
+	// ListScheduled returns the jobs waiting to run and those being run.
+	ListScheduled(ctx context.Context) ([]entities.Job, error)
+	ListDead(ctx context.Context) ([]entities.Job, error)
+}
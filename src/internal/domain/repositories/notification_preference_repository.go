@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+type NotificationPreferenceRepository interface {
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*entities.NotificationPreference, error)
+	// FindByUserIDs returns the stored preferences of the given users; users
+	// on the defaults have none.
+	FindByUserIDs(ctx context.Context, userIDs []uuid.UUID) ([]entities.NotificationPreference, error)
+	Upsert(ctx context.Context, preference *entities.NotificationPreference) error
+	UnmuteTopic(ctx context.Context, userID, topicID uuid.UUID) error
+	// FindLineRecipients returns the LINE accounts of the given users who
+	// enabled LINE delivery.
+	FindLineRecipients(ctx context.Context, userIDs []uuid.UUID) ([]dto.LineRecipient, error)
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxRepository interface {
+	Create(ctx context.Context, message *entities.OutboxMessage) error
+	Update(ctx context.Context, message *entities.OutboxMessage) error
+	FindByID(ctx context.Context, id uuid.UUID) (*entities.OutboxMessage, error)
+	// Claim leases the next due pending message, alarms first, and counts
+	// the attempt, so that no other worker picks it up until the lease runs
+	// out. It returns nil when no message is due.
+	Claim(ctx context.Context, lease time.Duration) (*entities.OutboxMessage, error)
+	// ExtendLease pushes the lease on a message being delivered back to
+	// lease from now.
+	ExtendLease(ctx context.Context, id uuid.UUID, lease time.Duration) error
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PhoneVerificationRepository keeps the pending phone verification of each user.
+type PhoneVerificationRepository interface {
+	// Save replaces the user's pending verification.
+	Save(ctx context.Context, userID uuid.UUID, verification *entities.PhoneVerification, ttl time.Duration) error
+	Find(ctx context.Context, userID uuid.UUID) (*entities.PhoneVerification, error)
+	// IncrementAttempts records an attempt at the code and returns the
+	// attempts made so far, or 0 when there is no pending verification.
+	IncrementAttempts(ctx context.Context, userID uuid.UUID) (int, error)
+	Delete(ctx context.Context, userID uuid.UUID) error
+	// CountSend records a code sent under key, e.g. a phone number, and
+	// returns the codes sent under it in the current window.
+	CountSend(ctx context.Context, key string, window time.Duration) (int, error)
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/pkg/geo"
 
 	"github.com/google/uuid"
 )
@@ -13,4 +14,6 @@ type PotentialPointRepository interface {
 	Update(ctx context.Context, pp *entities.PotentialPoint) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	FindAll(ctx context.Context) ([]entities.PotentialPoint, error)
+	FindWithinBounds(ctx context.Context, bounds geo.Bounds) ([]entities.PotentialPoint, error)
+	FindCreatorIDsByType(ctx context.Context, ppType string) ([]uuid.UUID, error)
 }
@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/dto"
+)
+
+// PushSender delivers notifications and alarms to the push tokens of one
+// provider (FCM, APNs, ...).
+type PushSender interface {
+	SendNotification(ctx context.Context, msg *dto.NotificationMessage, tokens []string) (*dto.MulticastResponse, error)
+	SendAlarm(ctx context.Context, msg *dto.AlarmMessage, tokens []string) (*dto.MulticastResponse, error)
+}
@@ -0,0 +1,9 @@
+package repositories
+
+import "context"
+
+// SMSSender delivers text messages to phone numbers in E.164 format.
+type SMSSender interface {
+	// Send delivers text to the number and returns the gateway's message ID.
+	Send(ctx context.Context, to, text string) (string, error)
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type TopicRepository interface {
+	Create(ctx context.Context, topic *entities.Topic) error
+	FindByID(ctx context.Context, id uuid.UUID) (*entities.Topic, error)
+	Update(ctx context.Context, topic *entities.Topic) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindAll(ctx context.Context, category string) ([]entities.Topic, error)
+}
+
+type TopicSubscriptionRepository interface {
+	Create(ctx context.Context, subscriptions []entities.TopicSubscription) error
+	Delete(ctx context.Context, topicID uuid.UUID, deviceIDs []uuid.UUID) error
+	DeleteByDevices(ctx context.Context, deviceIDs []uuid.UUID) error
+	FindByTopic(ctx context.Context, topicID uuid.UUID) ([]entities.TopicSubscription, error)
+	FindByDevice(ctx context.Context, deviceID uuid.UUID) ([]entities.TopicSubscription, error)
+	FindByUser(ctx context.Context, userID uuid.UUID) ([]entities.TopicSubscription, error)
+}
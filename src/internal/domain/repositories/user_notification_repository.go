@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+type UserNotificationRepository interface {
+	CreateBatch(ctx context.Context, notifications []entities.UserNotification) error
+	// FindByUser returns the user's notifications newest first.
+	FindByUser(ctx context.Context, userID uuid.UUID, filter dto.InboxFilter) ([]entities.UserNotification, error)
+	MarkRead(ctx context.Context, userID, id uuid.UUID) error
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int64, error)
+}
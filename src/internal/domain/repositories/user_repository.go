@@ -3,6 +3,8 @@ package repositories
 import (
 	"context"
 	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/pkg/geo"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -13,5 +15,19 @@ type UserRepository interface {
 	Update(ctx context.Context, user *entities.User) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	FindAll(ctx context.Context) ([]entities.User, error)
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]entities.User, error)
+	FindIDsByRoles(ctx context.Context, roles []string) ([]uuid.UUID, error)
+	// FindIDsAfter returns up to limit user IDs greater than after, in order,
+	// for walking every user a page at a time.
+	FindIDsAfter(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error)
 	FindBySocialID(ctx context.Context, provider, providerID string) (*entities.User, error)
+	// UpdatePhone sets or, with a nil phone, clears the user's phone number.
+	UpdatePhone(ctx context.Context, id uuid.UUID, phone *string, verifiedAt *time.Time) error
+	UpdateHomeLocation(ctx context.Context, id uuid.UUID, lat, lng *float64) error
+	// FindSMSRecipientsWithinBounds returns users with a verified phone and a
+	// home inside bounds who have no device that can receive push.
+	FindSMSRecipientsWithinBounds(ctx context.Context, bounds geo.Bounds) ([]entities.User, error)
+	// FindSMSDrillRecipients returns drill-enrolled users with a verified
+	// phone who have no device that can receive push.
+	FindSMSDrillRecipients(ctx context.Context) ([]entities.User, error)
 }
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+type WebPushSubscriptionRepository interface {
+	Upsert(ctx context.Context, subscription *entities.WebPushSubscription) error
+	DeleteByDevice(ctx context.Context, deviceID uuid.UUID) error
+	FindByEndpoints(ctx context.Context, endpoints []string) ([]entities.WebPushSubscription, error)
+}
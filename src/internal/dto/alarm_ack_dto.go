@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+)
+
+type AlarmAckRequest struct {
+	DeviceID     *uuid.UUID `json:"device_id"`
+	Event        string     `json:"event" validate:"required,oneof=received acknowledged"`
+	SafetyStatus string     `json:"safety_status" validate:"omitempty,oneof=safe need_help"`
+}
+
+type AlarmAckUser struct {
+	UserID       uuid.UUID `json:"user_id"`
+	DisplayName  string    `json:"display_name"`
+	SafetyStatus string    `json:"safety_status,omitempty"`
+}
+
+type AlarmAckSummary struct {
+	AlarmID      string         `json:"alarm_id"`
+	Recipients   int            `json:"recipients"`
+	Received     int            `json:"received"`
+	Acknowledged int            `json:"acknowledged"`
+	Safe         int            `json:"safe"`
+	NeedHelp     int            `json:"need_help"`
+	NeedHelpBy   []AlarmAckUser `json:"need_help_users"`
+	NotResponded []AlarmAckUser `json:"not_responded_users"`
+}
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/pkg/placeholder"
+)
+
+type CreateAlarmTemplateInput struct {
+	Name          string            `json:"name" validate:"required,max=255"`
+	Urgency       string            `json:"urgency" validate:"required,oneof=immediate high normal low"`
+	HazardType    string            `json:"hazard_type" validate:"omitempty,max=50,lowercase"`
+	Signal        string            `json:"signal" validate:"required,max=255"`
+	Content       map[string]string `json:"content" validate:"required,min=1,dive,keys,min=2,max=10,endkeys,required,max=600"` // locale -> content
+	DefaultRadius int               `json:"default_radius" validate:"min=0"`
+}
+
+type UpdateAlarmTemplateInput struct {
+	Name          *string           `json:"name" validate:"omitempty,min=1,max=255"`
+	Urgency       *string           `json:"urgency" validate:"omitempty,oneof=immediate high normal low"`
+	HazardType    *string           `json:"hazard_type" validate:"omitempty,max=50,lowercase"`
+	Signal        *string           `json:"signal" validate:"omitempty,min=1,max=255"`
+	Content       map[string]string `json:"content" validate:"omitempty,min=1,dive,keys,min=2,max=10,endkeys,required,max=600"`
+	DefaultRadius *int              `json:"default_radius" validate:"omitempty,min=0"`
+}
+
+type AlarmTemplateResponse struct {
+	ID            uuid.UUID         `json:"id"`
+	Name          string            `json:"name"`
+	Urgency       string            `json:"urgency"`
+	HazardType    string            `json:"hazard_type,omitempty"`
+	Signal        string            `json:"signal"`
+	Content       map[string]string `json:"content"`
+	DefaultRadius int               `json:"default_radius"`
+	Variables     []string          `json:"variables"`
+	CreatedBy     *uuid.UUID        `json:"created_by,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+func ToAlarmTemplateResponse(t *entities.AlarmTemplate) AlarmTemplateResponse {
+	content := t.Content.Data()
+	texts := []string{t.Signal}
+	for _, text := range content {
+		texts = append(texts, text)
+	}
+
+	return AlarmTemplateResponse{
+		ID:            t.ID,
+		Name:          t.Name,
+		Urgency:       t.Urgency,
+		HazardType:    t.HazardType,
+		Signal:        t.Signal,
+		Content:       content,
+		DefaultRadius: t.DefaultRadius,
+		Variables:     placeholder.Names(texts...),
+		CreatedBy:     t.CreatedBy,
+		CreatedAt:     t.CreatedAt,
+		UpdatedAt:     t.UpdatedAt,
+	}
+}
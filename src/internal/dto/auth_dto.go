@@ -6,11 +6,13 @@ type LoginRequest struct {
 }
 
 type SocialLoginRequest struct {
-	Provider    string `json:"provider" validate:"required,oneof=google line"` // google, line
-	AccessToken string `json:"access_token" validate:"required"`
-	DeviceID    string `json:"device_id"`   // Optional: Client can send known device_id
-	DeviceType  string `json:"device_type"` // Optional: ios, android, web
-	PushToken   string `json:"push_token"`  // Optional: FCM token
+	Provider     string `json:"provider" validate:"required,oneof=google line"` // google, line
+	AccessToken  string `json:"access_token" validate:"required"`
+	DeviceID     string `json:"device_id"`                                         // Optional: Client can send known device_id
+	DeviceType   string `json:"device_type"`                                       // Optional: ios, android, web
+	PushToken    string `json:"push_token"`                                        // Optional: FCM or APNs token
+	PushProvider string `json:"push_provider" validate:"omitempty,oneof=fcm apns"` // Optional: issuer of PushToken; defaults to fcm, apns only when given
+	Locale       string `json:"locale" validate:"omitempty,max=10"`                // Optional: preferred content locale, e.g. th, en, my, lo
 }
 
 type LoginResponse struct {
@@ -0,0 +1,66 @@
+package dto
+
+import "encoding/xml"
+
+// CAPNamespace is the XML namespace of Common Alerting Protocol 1.2 documents.
+const CAPNamespace = "urn:oasis:names:tc:emergency:cap:1.2"
+
+// CAPAlert is a CAP 1.2 <alert> document. Only the elements the dispatch
+// flow understands are modelled; unknown elements are ignored on input.
+type CAPAlert struct {
+	XMLName    xml.Name  `xml:"alert"`
+	Xmlns      string    `xml:"xmlns,attr,omitempty"`
+	Identifier string    `xml:"identifier"`
+	Sender     string    `xml:"sender"`
+	Sent       string    `xml:"sent"`
+	Status     string    `xml:"status"`  // Actual, Exercise, System, Test, Draft
+	MsgType    string    `xml:"msgType"` // Alert, Update, Cancel, Ack, Error
+	Scope      string    `xml:"scope"`
+	References string    `xml:"references,omitempty"`
+	Info       []CAPInfo `xml:"info"`
+}
+
+type CAPInfo struct {
+	Language    string    `xml:"language,omitempty"`
+	Category    []string  `xml:"category"`
+	Event       string    `xml:"event"`
+	Urgency     string    `xml:"urgency"`   // Immediate, Expected, Future, Past, Unknown
+	Severity    string    `xml:"severity"`  // Extreme, Severe, Moderate, Minor, Unknown
+	Certainty   string    `xml:"certainty"` // Observed, Likely, Possible, Unlikely, Unknown
+	Expires     string    `xml:"expires,omitempty"`
+	SenderName  string    `xml:"senderName,omitempty"`
+	Headline    string    `xml:"headline,omitempty"`
+	Description string    `xml:"description,omitempty"`
+	Instruction string    `xml:"instruction,omitempty"`
+	Area        []CAPArea `xml:"area"`
+}
+
+type CAPArea struct {
+	AreaDesc string   `xml:"areaDesc"`
+	Polygon  []string `xml:"polygon,omitempty"` // "lat,lon lat,lon ..." with the first and last pair equal
+	Circle   []string `xml:"circle,omitempty"`  // "lat,lon radius" with radius in kilometers
+}
+
+// AtomFeed is the Atom index of active alerts, one entry per CAP document.
+type AtomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    []AtomLink  `xml:"link"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+type AtomEntry struct {
+	ID      string     `xml:"id"`
+	Title   string     `xml:"title"`
+	Updated string     `xml:"updated"`
+	Summary string     `xml:"summary,omitempty"`
+	Link    []AtomLink `xml:"link"`
+}
+
+type AtomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
@@ -0,0 +1,76 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Groupings of delivery records in a summary.
+const (
+	DeliveryGroupAlarm    = "alarm"    // per alarm, across its follow-ups
+	DeliveryGroupMessage  = "message"  // per outbox message
+	DeliveryGroupPlatform = "platform" // per provider and device type
+)
+
+type DeliverySummaryQuery struct {
+	AlarmID    string `query:"alarm_id" validate:"omitempty,max=255"`
+	TrackingID string `query:"tracking_id" validate:"omitempty,uuid"`
+	From       string `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To         string `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// DeliveryFilter selects the delivery records a summary is made of.
+type DeliveryFilter struct {
+	AlarmID         string
+	OutboxMessageID *uuid.UUID
+	Kinds           []string
+	From            *time.Time
+	To              *time.Time
+}
+
+// DeliveryStatsRow is one group of delivery records. Only the columns of its
+// grouping are set. A target counts as delivered when any attempt to send it
+// the message succeeded.
+type DeliveryStatsRow struct {
+	AlarmID         *string
+	OutboxMessageID *uuid.UUID
+	Kind            string
+	Provider        string
+	DeviceType      string
+	Attempts        int64
+	Targets         int64
+	Delivered       int64
+}
+
+type DeliveryStats struct {
+	Targets     int64   `json:"targets"`
+	Delivered   int64   `json:"delivered"`
+	Failed      int64   `json:"failed"`
+	Attempts    int64   `json:"attempts"`
+	SuccessRate float64 `json:"success_rate"` // delivered / targets, 0 when nothing was sent
+}
+
+type AlarmDeliveryStats struct {
+	AlarmID string `json:"alarm_id"`
+	DeliveryStats
+}
+
+type BroadcastDeliveryStats struct {
+	TrackingID uuid.UUID `json:"tracking_id"`
+	Kind       string    `json:"kind"`
+	DeliveryStats
+}
+
+type PlatformDeliveryStats struct {
+	Provider   string `json:"provider"`
+	DeviceType string `json:"device_type,omitempty"`
+	DeliveryStats
+}
+
+type DeliverySummaryResponse struct {
+	Overall    DeliveryStats            `json:"overall"`
+	Alarms     []AlarmDeliveryStats     `json:"alarms"`
+	Broadcasts []BroadcastDeliveryStats `json:"broadcasts"`
+	Platforms  []PlatformDeliveryStats  `json:"platforms"`
+}
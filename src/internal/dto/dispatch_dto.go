@@ -1,15 +1,226 @@
 package dto
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pbmap_api/src/internal/domain/entities"
+)
+
 type AlarmCenter struct {
-	Lat    float64 `json:"lat" validate:"required,latitude"`
-	Lng    float64 `json:"lng" validate:"required,longitude"`
-	Radius int     `json:"radius" validate:"required,min=0"` // meters
+	Lat    float64 `json:"lat" validate:"latitude"`
+	Lng    float64 `json:"lng" validate:"longitude"`
+	Radius int     `json:"radius" validate:"min=0"` // meters; falls back to the template's default radius
+}
+
+// GeoJSONGeometry is a GeoJSON Polygon or MultiPolygon geometry object.
+type GeoJSONGeometry struct {
+	Type        string          `json:"type" validate:"required,oneof=Polygon MultiPolygon"`
+	Coordinates json.RawMessage `json:"coordinates" validate:"required"`
 }
 
+// AlarmDispatchRequest describes an alarm to dispatch. Content is either a
+// single string in the default locale or a map of locale to content. With
+// TemplateID set, urgency, signal and content may be left out and are
+// rendered from the template using Variables. The limits on AlarmID, Signal
+// and Content keep the pushed alarm within FCM's 4KB payload.
 type AlarmDispatchRequest struct {
-	AlarmID string      `json:"alarm_id" validate:"required"`
-	Urgency string      `json:"urgency" validate:"required,oneof=immediate high normal low"`
-	Center  AlarmCenter `json:"center" validate:"required"`
-	Signal  string      `json:"signal" validate:"required"`
-	Content string      `json:"content" validate:"required"`
+	AlarmID    string            `json:"alarm_id" validate:"required,max=64"`
+	TemplateID *uuid.UUID        `json:"template_id,omitempty"`
+	Variables  map[string]string `json:"variables,omitempty"`
+	Urgency    string            `json:"urgency" validate:"required_without=TemplateID,omitempty,oneof=immediate high normal low"`
+	Mode       string            `json:"mode,omitempty" validate:"omitempty,oneof=live drill"`
+	HazardType string            `json:"hazard_type,omitempty" validate:"omitempty,max=50,lowercase"`
+	Center     *AlarmCenter      `json:"center" validate:"required_without=Areas,omitempty"`
+	Areas      []GeoJSONGeometry `json:"areas" validate:"required_without=Center,omitempty,dive"`
+	Signal     string            `json:"signal" validate:"required_without=TemplateID,max=255"`
+	Content    LocalizedText     `json:"content" validate:"required_without=TemplateID,omitempty,dive,keys,max=10,endkeys,required,max=600"`
+	ExpiresAt  *time.Time        `json:"expires_at" validate:"omitempty,gt"`
+}
+
+type AlarmUpdateRequest struct {
+	Urgency   *string       `json:"urgency" validate:"omitempty,oneof=immediate high normal low"`
+	Signal    *string       `json:"signal" validate:"omitempty,min=1,max=255"`
+	Content   LocalizedText `json:"content" validate:"omitempty,dive,keys,max=10,endkeys,required,max=600"`
+	ExpiresAt *time.Time    `json:"expires_at" validate:"omitempty,gt"`
+}
+
+// AlarmMessage is the data payload pushed to devices for an alarm and its follow-ups.
+type AlarmMessage struct {
+	AlarmID   string
+	Status    string // new, update, cancel, expired
+	Urgency   string
+	Mode      string      // live, drill
+	Center    AlarmCenter // circle covering the whole target area
+	HasAreas  bool        // targeted at polygons, which clients fetch from the alarm endpoint
+	Signal    string
+	Content   string
+	Locale    string // locale of Content
+	ExpiresAt *time.Time
+}
+
+func ToAlarmMessage(a *entities.Alarm, status string) *AlarmMessage {
+	return &AlarmMessage{
+		AlarmID: a.AlarmID,
+		Status:  status,
+		Urgency: a.Urgency,
+		Mode:    a.Mode,
+		Center: AlarmCenter{
+			Lat:    a.Latitude,
+			Lng:    a.Longitude,
+			Radius: a.Radius,
+		},
+		HasAreas:  len(a.Areas) > 0,
+		Signal:    a.Signal,
+		Content:   a.Content,
+		ExpiresAt: a.ExpiresAt,
+	}
+}
+
+type AlarmDispatchResponse struct {
+	AlarmID                 string                   `json:"alarm_id"`
+	Status                  string                   `json:"status"`
+	DispatchStatus          string                   `json:"dispatch_status,omitempty"` // queued until the worker has sent it
+	TrackingID              *uuid.UUID               `json:"tracking_id,omitempty"`     // outbox message of the send
+	TargetedDevices         int                      `json:"targeted_devices"`
+	SuccessCount            int                      `json:"success_count"`
+	FailureCount            int                      `json:"failure_count"`
+	LineRecipients          int                      `json:"line_recipients,omitempty"` // LINE users sent this time; not kept on the alarm
+	SMSRecipients           int                      `json:"sms_recipients,omitempty"`  // SMS accepted this time; not kept on the alarm
+	AffectedPotentialPoints []AffectedPotentialPoint `json:"affected_potential_points"`
+}
+
+type AffectedPotentialPoint struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Type string    `json:"type"`
+}
+
+func ToAlarmDispatchResponse(a *entities.Alarm) AlarmDispatchResponse {
+	return AlarmDispatchResponse{
+		AlarmID:         a.AlarmID,
+		Status:          a.Status,
+		DispatchStatus:  a.DispatchStatus,
+		TrackingID:      a.TrackingID,
+		TargetedDevices: a.TargetedDevices,
+		SuccessCount:    a.SuccessCount,
+		FailureCount:    a.FailureCount,
+	}
+}
+
+type AlarmFilter struct {
+	Urgency string
+	Mode    string
+	Status  string
+	From    *time.Time
+	To      *time.Time
+	Lat     *float64
+	Lng     *float64
+	Radius  int // meters
+	Limit   int
+	Offset  int
+}
+
+type AlarmListQuery struct {
+	Urgency string   `query:"urgency" validate:"omitempty,oneof=immediate high normal low"`
+	Mode    string   `query:"mode" validate:"omitempty,oneof=live drill"`
+	Status  string   `query:"status" validate:"omitempty,oneof=pending_approval active cancelled expired rejected approval_expired"`
+	From    string   `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To      string   `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Lat     *float64 `query:"lat" validate:"required_with=Lng,omitempty,latitude"`
+	Lng     *float64 `query:"lng" validate:"required_with=Lat,omitempty,longitude"`
+	Radius  int      `query:"radius" validate:"min=0"`
+	Limit   int      `query:"limit" validate:"min=0,max=100"`
+	Offset  int      `query:"offset" validate:"min=0"`
+}
+
+type AlarmResponse struct {
+	ID               uuid.UUID         `json:"id"`
+	AlarmID          string            `json:"alarm_id"`
+	Urgency          string            `json:"urgency"`
+	Mode             string            `json:"mode"`
+	TemplateID       *uuid.UUID        `json:"template_id,omitempty"`
+	HazardType       string            `json:"hazard_type,omitempty"`
+	Center           AlarmCenter       `json:"center"`
+	CircleTargeted   bool              `json:"circle_targeted"`
+	Areas            []GeoJSONGeometry `json:"areas,omitempty"`
+	Signal           string            `json:"signal"`
+	Content          string            `json:"content"`
+	LocalizedContent map[string]string `json:"localized_content,omitempty"`
+	DispatchedBy     *uuid.UUID        `json:"dispatched_by,omitempty"`
+	DispatchStatus   string            `json:"dispatch_status"`
+	DispatchError    string            `json:"dispatch_error,omitempty"`
+	TargetedDevices  int               `json:"targeted_devices"`
+	SuccessCount     int               `json:"success_count"`
+	FailureCount     int               `json:"failure_count"`
+	MessageIDs       []string          `json:"message_ids"`
+	DispatchedAt     *time.Time        `json:"dispatched_at,omitempty"`
+	TrackingID       *uuid.UUID        `json:"tracking_id,omitempty"`
+	Status           string            `json:"status"`
+	ExpiresAt        *time.Time        `json:"expires_at,omitempty"`
+	CancelledAt      *time.Time        `json:"cancelled_at,omitempty"`
+
+	ApprovalExpiresAt *time.Time `json:"approval_expires_at,omitempty"`
+	ApprovedBy        *uuid.UUID `json:"approved_by,omitempty"`
+	ApprovedAt        *time.Time `json:"approved_at,omitempty"`
+	RejectedBy        *uuid.UUID `json:"rejected_by,omitempty"`
+	RejectedAt        *time.Time `json:"rejected_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// ToPublicAlarmResponse is ToAlarmResponse without the officers who handled
+// the alarm, for callers who are not officers.
+func ToPublicAlarmResponse(a *entities.Alarm) AlarmResponse {
+	resp := ToAlarmResponse(a)
+	resp.DispatchedBy = nil
+	resp.ApprovedBy = nil
+	resp.RejectedBy = nil
+	return resp
+}
+
+func ToAlarmResponse(a *entities.Alarm) AlarmResponse {
+	var areas []GeoJSONGeometry
+	if len(a.Areas) > 0 {
+		_ = json.Unmarshal(a.Areas, &areas)
+	}
+
+	return AlarmResponse{
+		ID:         a.ID,
+		AlarmID:    a.AlarmID,
+		Urgency:    a.Urgency,
+		Mode:       a.Mode,
+		TemplateID: a.TemplateID,
+		HazardType: a.HazardType,
+		Center: AlarmCenter{
+			Lat:    a.Latitude,
+			Lng:    a.Longitude,
+			Radius: a.Radius,
+		},
+		CircleTargeted:   a.CircleTargeted,
+		Areas:            areas,
+		Signal:           a.Signal,
+		Content:          a.Content,
+		LocalizedContent: a.LocalizedContent.Data(),
+		DispatchedBy:     a.DispatchedBy,
+		DispatchStatus:   a.DispatchStatus,
+		DispatchError:    a.DispatchError,
+		TargetedDevices:  a.TargetedDevices,
+		SuccessCount:     a.SuccessCount,
+		FailureCount:     a.FailureCount,
+		MessageIDs:       a.MessageIDs,
+		DispatchedAt:     a.DispatchedAt,
+		TrackingID:       a.TrackingID,
+		Status:           a.Status,
+		ExpiresAt:        a.ExpiresAt,
+		CancelledAt:      a.CancelledAt,
+
+		ApprovalExpiresAt: a.ApprovalExpiresAt,
+		ApprovedBy:        a.ApprovedBy,
+		ApprovedAt:        a.ApprovedAt,
+		RejectedBy:        a.RejectedBy,
+		RejectedAt:        a.RejectedAt,
+		CreatedAt:         a.CreatedAt,
+	}
 }
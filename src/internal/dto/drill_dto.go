@@ -0,0 +1,11 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+)
+
+// DrillEnrollmentRequest enrolls either a user or a single device in the drill group.
+type DrillEnrollmentRequest struct {
+	UserID   *uuid.UUID `json:"user_id" validate:"required_without=DeviceID,excluded_with=DeviceID"`
+	DeviceID *uuid.UUID `json:"device_id" validate:"required_without=UserID,excluded_with=UserID"`
+}
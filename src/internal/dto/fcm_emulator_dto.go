@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"time"
+)
+
+// Kinds of calls recorded by the FCM emulator.
+const (
+	FCMCallNotification = "notification"
+	FCMCallAlarm        = "alarm"
+	FCMCallSubscribe    = "subscribe"
+	FCMCallUnsubscribe  = "unsubscribe"
+)
+
+// FCMEmulatorCall is one call the FCM emulator received in place of FCM. For
+// sends, Title, Body and Data are the FCM message as it would have been sent.
+type FCMEmulatorCall struct {
+	ID            string                 `json:"id"`
+	Kind          string                 `json:"kind"`
+	Tokens        []string               `json:"tokens"`
+	Topic         string                 `json:"topic,omitempty"`
+	Title         string                 `json:"title,omitempty"`
+	Body          string                 `json:"body,omitempty"`
+	Data          map[string]string      `json:"data,omitempty"`
+	MessageIDs    []string               `json:"message_ids,omitempty"`
+	FailureTokens []TopicManagementError `json:"failure_tokens,omitempty"`
+	At            time.Time              `json:"at"`
+}
+
+// FCMEmulatorQuery narrows the recorded calls to those of a kind, reaching a
+// token or about a topic.
+type FCMEmulatorQuery struct {
+	Kind  string `query:"kind" validate:"omitempty,oneof=notification alarm subscribe unsubscribe"`
+	Token string `query:"token" validate:"omitempty,max=4096"`
+	Topic string `query:"topic" validate:"omitempty,max=100"`
+}
+
+type FCMEmulatorState struct {
+	Calls  []FCMEmulatorCall   `json:"calls"`
+	Topics map[string][]string `json:"topics"` // tokens subscribed to each topic
+}
+
+// FCMUnregisterRequest makes the emulator answer sends to Tokens as FCM does
+// for uninstalled apps.
+type FCMUnregisterRequest struct {
+	Tokens []string `json:"tokens" validate:"required,min=1,dive,required"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"time"
+)
+
+// Overall states reported by the health endpoint.
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded" // serving, but a dependency is failing fast
+)
+
+type HealthResponse struct {
+	Status   string          `json:"status"`
+	Breakers []BreakerStatus `json:"breakers"`
+}
+
+// BreakerStatus is the state of the circuit breaker guarding a dependency.
+type BreakerStatus struct {
+	Name                string     `json:"name"`
+	State               string     `json:"state"` // closed, open, half_open
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
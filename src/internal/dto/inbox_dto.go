@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"pbmap_api/src/internal/domain/entities"
+)
+
+// Kinds of inbox notifications.
+const (
+	InboxKindBroadcast = "broadcast"
+	InboxKindAlarm     = "alarm"
+	InboxKindTargeted  = "targeted"
+)
+
+type InboxQuery struct {
+	Cursor string `query:"cursor" validate:"omitempty,max=100"`
+	Limit  int    `query:"limit" validate:"min=0,max=100"`
+	Unread bool   `query:"unread"` // only unread notifications
+}
+
+// InboxFilter selects a page of a user's inbox. With Before set, only
+// notifications older than (Before, BeforeID) are returned.
+type InboxFilter struct {
+	Before     *time.Time
+	BeforeID   uuid.UUID
+	UnreadOnly bool
+	Limit      int
+}
+
+type InboxResponse struct {
+	Notifications []entities.UserNotification `json:"notifications"`
+	NextCursor    string                      `json:"next_cursor,omitempty"`
+	UnreadCount   int64                       `json:"unread_count"`
+}
+
+type UnreadCountResponse struct {
+	UnreadCount int64 `json:"unread_count"`
+}
+
+type MarkAllReadResponse struct {
+	Updated int64 `json:"updated"`
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// LocalizedText maps a locale such as "th" or "en" to text. A bare JSON
+// string is accepted for single-language content; it is kept under the empty
+// key until Resolve assigns it to the default locale.
+type LocalizedText map[string]string
+
+func (t *LocalizedText) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*t = LocalizedText{"": text}
+		return nil
+	}
+
+	var texts map[string]string
+	if err := json.Unmarshal(data, &texts); err != nil {
+		return err
+	}
+	*t = texts
+	return nil
+}
+
+// MarshalJSON writes single-language text back as a bare string so that
+// payload hashes of requests sent before content was localized stay stable.
+func (t LocalizedText) MarshalJSON() ([]byte, error) {
+	if text, ok := t[""]; ok && len(t) == 1 {
+		return json.Marshal(text)
+	}
+	return json.Marshal(map[string]string(t))
+}
+
+// Resolve returns the text per normalized locale ("en_US" becomes "en-us"),
+// with bare text assigned to defaultLocale.
+func (t LocalizedText) Resolve(defaultLocale string) map[string]string {
+	texts := make(map[string]string, len(t))
+	for locale, text := range t {
+		if locale == "" {
+			locale = defaultLocale
+		}
+		texts[strings.ReplaceAll(strings.ToLower(locale), "_", "-")] = text
+	}
+	return texts
+}
@@ -1,20 +1,83 @@
 package dto
 
+import (
+	"github.com/google/uuid"
+)
+
+// BroadcastRequest is sent to every registered device. Title and Body are
+// either single strings in the default locale or maps of locale to text, short
+// enough to fit FCM's 4KB payload.
 type BroadcastRequest struct {
-	Title string `json:"title" validate:"required"`
-	Body  string `json:"body" validate:"required"`
+	Title LocalizedText `json:"title" validate:"required,dive,keys,max=10,endkeys,required,max=100"`
+	Body  LocalizedText `json:"body" validate:"required,dive,keys,max=10,endkeys,required,max=800"`
+}
+
+type BroadcastResponse struct {
+	TargetedDevices int `json:"targeted_devices"`
+	SuccessCount    int `json:"success_count"`
+	FailureCount    int `json:"failure_count"`
+	LineRecipients  int `json:"line_recipients,omitempty"`
+}
+
+// NotificationMessage is a visible push notification in a single locale.
+type NotificationMessage struct {
+	Title  string
+	Body   string
+	Locale string
+}
+
+// TargetedNotificationRequest sends a notification to the union of the given
+// users, the users with the given roles and the users matching Segment.
+type TargetedNotificationRequest struct {
+	UserIDs []uuid.UUID          `json:"user_ids" validate:"required_without_all=Roles Segment"`
+	Roles   []string             `json:"roles" validate:"omitempty,dive,oneof=citizen officer admin"`
+	Segment *NotificationSegment `json:"segment" validate:"omitempty"`
+	Title   LocalizedText        `json:"title" validate:"required,dive,keys,max=10,endkeys,required,max=100"`
+	Body    LocalizedText        `json:"body" validate:"required,dive,keys,max=10,endkeys,required,max=800"`
+}
+
+// NotificationSegment selects users by what they have contributed.
+type NotificationSegment struct {
+	PotentialPointType string `json:"potential_point_type" validate:"required"` // users who created a potential point of this type
+}
+
+type TargetedNotificationResponse struct {
+	TargetedUsers   int                    `json:"targeted_users"`
+	TargetedDevices int                    `json:"targeted_devices"`
+	SuccessTokens   []string               `json:"success_tokens"`
+	FailureTokens   []TopicManagementError `json:"failure_tokens"`
 }
 
 type SubscribeRequest struct {
 	Tokens []string `json:"tokens" validate:"required,min=1"`
 }
 
+// Per-token error codes that mean the push token is dead and should no longer
+// be sent to.
+const (
+	TokenErrorUnregistered    = "registration-token-not-registered"
+	TokenErrorInvalidArgument = "invalid-argument"
+)
+
+// TokenErrorTransient is the code of a per-token failure that may succeed
+// when retried, such as FCM being unavailable. The token is kept.
+const TokenErrorTransient = "transient"
+
 type TopicManagementError struct {
 	Token  string `json:"token"`
 	Reason string `json:"reason"`
+	Code   string `json:"code,omitempty"`
 }
 
 type TopicManagementResponse struct {
 	SuccessTokens []string               `json:"success_tokens"`
 	FailureTokens []TopicManagementError `json:"failure_tokens"`
 }
+
+type MulticastResponse struct {
+	SuccessCount  int                    `json:"success_count"`
+	FailureCount  int                    `json:"failure_count"`
+	MessageIDs    []string               `json:"message_ids"` // provider message ID of each of SuccessTokens, in order
+	SuccessTokens []string               `json:"success_tokens"`
+	FailureTokens []TopicManagementError `json:"failure_tokens"`
+}
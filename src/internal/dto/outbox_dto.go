@@ -0,0 +1,12 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+)
+
+// DispatchReceipt acknowledges a send that was queued in the outbox. Its
+// outcome can be followed at /api/v1/dispatch/outbox/{tracking_id}.
+type DispatchReceipt struct {
+	TrackingID uuid.UUID `json:"tracking_id"`
+	Status     string    `json:"status"`
+}
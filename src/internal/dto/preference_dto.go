@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+)
+
+// UpdatePreferencesRequest changes the fields that are set and leaves the rest.
+// MutedTopicIDs and MutedHazards replace the stored lists as a whole.
+type UpdatePreferencesRequest struct {
+	PushEnabled   *bool              `json:"push_enabled"`
+	LineEnabled   *bool              `json:"line_enabled"`
+	SMSEnabled    *bool              `json:"sms_enabled"`
+	MutedTopicIDs *[]uuid.UUID       `json:"muted_topic_ids" validate:"omitempty,max=100"`
+	MutedHazards  *[]string          `json:"muted_hazards" validate:"omitempty,max=50,dive,required,max=50,lowercase"`
+	QuietHours    *QuietHoursRequest `json:"quiet_hours"`
+}
+
+// QuietHoursRequest sets the daily window, in TimeZone, during which only
+// immediate alarms are delivered. Start after End wraps past midnight.
+type QuietHoursRequest struct {
+	Enabled  bool   `json:"enabled"`
+	Start    string `json:"start" validate:"required_if=Enabled true,omitempty,datetime=15:04"`
+	End      string `json:"end" validate:"required_if=Enabled true,omitempty,datetime=15:04"`
+	TimeZone string `json:"time_zone" validate:"omitempty,timezone"` // IANA name; the server default when empty
+}
+
+type PreferencesResponse struct {
+	PushEnabled   bool        `json:"push_enabled"`
+	LineEnabled   bool        `json:"line_enabled"`
+	LineLinked    bool        `json:"line_linked"` // whether the user signed in with LINE and can receive LINE messages
+	SMSEnabled    bool        `json:"sms_enabled"`
+	MutedTopicIDs []uuid.UUID `json:"muted_topic_ids"`
+	MutedHazards  []string    `json:"muted_hazards"`
+	QuietHours    QuietHours  `json:"quiet_hours"`
+}
+
+type QuietHours struct {
+	Enabled  bool   `json:"enabled"`
+	Start    string `json:"start,omitempty"`
+	End      string `json:"end,omitempty"`
+	TimeZone string `json:"time_zone"`
+}
+
+// LineRecipient is a user reachable through the LINE Messaging API.
+type LineRecipient struct {
+	UserID     uuid.UUID
+	LineUserID string
+}
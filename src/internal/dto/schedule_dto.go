@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"time"
+)
+
+type ScheduleBroadcastRequest struct {
+	RunAt     time.Time        `json:"run_at" validate:"required,gt"`
+	Broadcast BroadcastRequest `json:"broadcast" validate:"required"`
+}
+
+// ScheduleAlarmRequest schedules an alarm dispatch. Immediate and high alarms
+// should be approved by a second officer before RunAt; otherwise they are
+// drafted for approval when they come due.
+type ScheduleAlarmRequest struct {
+	RunAt time.Time            `json:"run_at" validate:"required,gt"`
+	Alarm AlarmDispatchRequest `json:"alarm" validate:"required"`
+}
+
+type RescheduleRequest struct {
+	RunAt time.Time `json:"run_at" validate:"required,gt"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+
+	"pbmap_api/src/internal/domain/entities"
+)
+
+// CreateTopicInput creates a topic. Name is the FCM topic name and cannot be
+// changed afterwards.
+type CreateTopicInput struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Category    string `json:"category" validate:"required,oneof=province hazard language general"`
+	DisplayName string `json:"display_name" validate:"required,max=255"`
+	Description string `json:"description" validate:"max=1000"`
+}
+
+type UpdateTopicInput struct {
+	Category    *string `json:"category" validate:"omitempty,oneof=province hazard language general"`
+	DisplayName *string `json:"display_name" validate:"omitempty,min=1,max=255"`
+	Description *string `json:"description" validate:"omitempty,max=1000"`
+}
+
+// TopicSubscriptionRequest selects which of the caller's devices to
+// (un)subscribe. An empty list selects all of them.
+type TopicSubscriptionRequest struct {
+	DeviceIDs []uuid.UUID `json:"device_ids"`
+}
+
+type TopicSubscriptionResponse struct {
+	TopicID       uuid.UUID              `json:"topic_id"`
+	Topic         string                 `json:"topic"`
+	DeviceIDs     []uuid.UUID            `json:"device_ids"`
+	FailureTokens []TopicManagementError `json:"failure_tokens"`
+}
+
+// SubscribedTopic is a topic together with the caller's devices subscribed to it.
+type SubscribedTopic struct {
+	entities.Topic
+	DeviceIDs []uuid.UUID `json:"device_ids"`
+}
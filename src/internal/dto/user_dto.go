@@ -10,3 +10,29 @@ type UpdateUserRequest struct {
 	DisplayName string `json:"display_name" validate:"omitempty,min=3,max=50"`
 	Role        string `json:"role" validate:"omitempty,oneof=citizen officer admin"`
 }
+
+type UpdateDeviceLocationRequest struct {
+	Lat float64 `json:"lat" validate:"latitude"`
+	Lng float64 `json:"lng" validate:"longitude"`
+}
+
+// UpdatePushTokenRequest replaces a device's push token after the provider rotated it.
+type UpdatePushTokenRequest struct {
+	PushToken string `json:"push_token" validate:"required,max=4096"`
+}
+
+// UpdateHomeLocationRequest sets where the user lives, which places users
+// without a located device inside alarm areas for SMS delivery.
+type UpdateHomeLocationRequest struct {
+	Lat float64 `json:"lat" validate:"latitude"`
+	Lng float64 `json:"lng" validate:"longitude"`
+}
+
+// RequestPhoneVerificationRequest sends a verification code to PhoneNumber.
+type RequestPhoneVerificationRequest struct {
+	PhoneNumber string `json:"phone_number" validate:"required,e164"`
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+)
+
+// WebPushSubscriptionRequest registers a browser's PushSubscription, in the
+// shape PushSubscription.toJSON() produces, as a web device of the caller.
+type WebPushSubscriptionRequest struct {
+	DeviceID *uuid.UUID  `json:"device_id"` // Optional: replace the subscription of this web device
+	Endpoint string      `json:"endpoint" validate:"required,url,max=2048"`
+	Keys     WebPushKeys `json:"keys" validate:"required"`
+	Locale   string      `json:"locale" validate:"omitempty,max=10"`
+}
+
+type WebPushKeys struct {
+	P256dh string `json:"p256dh" validate:"required"`
+	Auth   string `json:"auth" validate:"required"`
+}
+
+type VAPIDPublicKeyResponse struct {
+	PublicKey string `json:"public_key"`
+}
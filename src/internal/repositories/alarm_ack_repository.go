@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type alarmAckRepository struct {
+	db *gorm.DB
+}
+
+func NewAlarmAckRepository(db *gorm.DB) repositories.AlarmAckRepository {
+	return &alarmAckRepository{db: db}
+}
+
+func (r *alarmAckRepository) FindByAlarmAndUser(ctx context.Context, alarmID, userID uuid.UUID) (*entities.AlarmAck, error) {
+	var ack entities.AlarmAck
+	if err := GetDB(ctx, r.db).Where("alarm_id = ? AND user_id = ?", alarmID, userID).First(&ack).Error; err != nil {
+		return nil, err
+	}
+	return &ack, nil
+}
+
+func (r *alarmAckRepository) Save(ctx context.Context, ack *entities.AlarmAck) error {
+	if ack.ID == uuid.Nil {
+		return GetDB(ctx, r.db).Create(ack).Error
+	}
+	return GetDB(ctx, r.db).Save(ack).Error
+}
+
+func (r *alarmAckRepository) FindByAlarm(ctx context.Context, alarmID uuid.UUID) ([]entities.AlarmAck, error) {
+	var acks []entities.AlarmAck
+	err := GetDB(ctx, r.db).Preload("User").Where("alarm_id = ?", alarmID).Find(&acks).Error
+	return acks, err
+}
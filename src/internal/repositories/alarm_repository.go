@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/geo"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type alarmRepository struct {
+	db *gorm.DB
+}
+
+func NewAlarmRepository(db *gorm.DB) repositories.AlarmRepository {
+	return &alarmRepository{db: db}
+}
+
+func (r *alarmRepository) Create(ctx context.Context, alarm *entities.Alarm) error {
+	return GetDB(ctx, r.db).Create(alarm).Error
+}
+
+func (r *alarmRepository) Update(ctx context.Context, alarm *entities.Alarm) error {
+	return GetDB(ctx, r.db).Save(alarm).Error
+}
+
+func (r *alarmRepository) UpdateIfStatus(ctx context.Context, alarm *entities.Alarm, status string) (bool, error) {
+	result := GetDB(ctx, r.db).Model(alarm).Where("status = ?", status).Select("*").Updates(alarm)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *alarmRepository) FindByID(ctx context.Context, id uuid.UUID) (*entities.Alarm, error) {
+	var alarm entities.Alarm
+	if err := GetDB(ctx, r.db).First(&alarm, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &alarm, nil
+}
+
+func (r *alarmRepository) FindByAlarmID(ctx context.Context, alarmID string) (*entities.Alarm, error) {
+	var alarm entities.Alarm
+	if err := GetDB(ctx, r.db).Where("alarm_id = ?", alarmID).First(&alarm).Error; err != nil {
+		return nil, err
+	}
+	return &alarm, nil
+}
+
+func (r *alarmRepository) FindAll(ctx context.Context, filter dto.AlarmFilter) ([]entities.Alarm, error) {
+	query := GetDB(ctx, r.db).Model(&entities.Alarm{})
+
+	if filter.Urgency != "" {
+		query = query.Where("urgency = ?", filter.Urgency)
+	}
+	if filter.Mode != "" {
+		query = query.Where("mode = ?", filter.Mode)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.From != nil {
+		query = query.Where("dispatched_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("dispatched_at <= ?", *filter.To)
+	}
+	if filter.Lat != nil && filter.Lng != nil {
+		// Keep alarms whose circle overlaps the requested area.
+		query = query.Where(
+			"2 * ? * asin(LEAST(1, sqrt(power(sin(radians(latitude - ?) / 2), 2) + cos(radians(?)) * cos(radians(latitude)) * power(sin(radians(longitude - ?) / 2), 2)))) <= radius + ?",
+			geo.EarthRadius, *filter.Lat, *filter.Lat, *filter.Lng, filter.Radius,
+		)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	var alarms []entities.Alarm
+	err := query.Order("created_at DESC").Limit(limit).Offset(filter.Offset).Find(&alarms).Error
+	return alarms, err
+}
+
+func (r *alarmRepository) FindExpired(ctx context.Context, now time.Time) ([]entities.Alarm, error) {
+	var alarms []entities.Alarm
+	err := GetDB(ctx, r.db).
+		Where("status = ? AND expires_at IS NOT NULL AND expires_at <= ?", "active", now).
+		Find(&alarms).Error
+	return alarms, err
+}
+
+func (r *alarmRepository) FindExpiredApprovals(ctx context.Context, now time.Time) ([]entities.Alarm, error) {
+	var alarms []entities.Alarm
+	err := GetDB(ctx, r.db).
+		Where("status = ? AND approval_expires_at <= ?", "pending_approval", now).
+		Find(&alarms).Error
+	return alarms, err
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type alarmTemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewAlarmTemplateRepository(db *gorm.DB) repositories.AlarmTemplateRepository {
+	return &alarmTemplateRepository{db: db}
+}
+
+func (r *alarmTemplateRepository) Create(ctx context.Context, template *entities.AlarmTemplate) error {
+	return GetDB(ctx, r.db).Create(template).Error
+}
+
+func (r *alarmTemplateRepository) FindByID(ctx context.Context, id uuid.UUID) (*entities.AlarmTemplate, error) {
+	var template entities.AlarmTemplate
+	if err := GetDB(ctx, r.db).First(&template, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *alarmTemplateRepository) Update(ctx context.Context, template *entities.AlarmTemplate) error {
+	return GetDB(ctx, r.db).Save(template).Error
+}
+
+func (r *alarmTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := GetDB(ctx, r.db).Delete(&entities.AlarmTemplate{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *alarmTemplateRepository) FindAll(ctx context.Context) ([]entities.AlarmTemplate, error) {
+	var templates []entities.AlarmTemplate
+	err := GetDB(ctx, r.db).Order("name").Find(&templates).Error
+	return templates, err
+}
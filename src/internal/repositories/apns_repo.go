@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/apns"
+	"pbmap_api/src/pkg/config"
+)
+
+// Ensure apnsRepo implements repositories.PushSender.
+var _ repositories.PushSender = (*apnsRepo)(nil)
+
+// apnsConcurrency is the number of notifications in flight at once. APNs takes
+// one device per request, multiplexed over a single HTTP/2 connection.
+const apnsConcurrency = 16
+
+type apnsRepo struct {
+	client *apns.Client
+}
+
+// NewAPNsRepo creates the APNs sender from the .p8 key in cfg.APNsKeyPath.
+func NewAPNsRepo(cfg *config.Config) (repositories.PushSender, error) {
+	if cfg.APNsKeyPath == "" {
+		return &apnsRepo{}, nil
+	}
+
+	key, err := os.ReadFile(cfg.APNsKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading apns key: %v", err)
+	}
+	client, err := apns.NewClient(apns.Config{
+		Endpoint: cfg.APNsEndpoint,
+		KeyID:    cfg.APNsKeyID,
+		TeamID:   cfg.APNsTeamID,
+		Topic:    cfg.APNsTopic,
+		Key:      key,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &apnsRepo{client: client}, nil
+}
+
+// NewAPNsRepoWithClient creates the APNs sender around an existing client,
+// e.g. one pointed at apnstest.
+func NewAPNsRepoWithClient(client *apns.Client) repositories.PushSender {
+	return &apnsRepo{client: client}
+}
+
+func (s *apnsRepo) SendNotification(ctx context.Context, msg *dto.NotificationMessage, tokens []string) (*dto.MulticastResponse, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("apns client is not initialized")
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{"title": msg.Title, "body": msg.Body},
+			"sound": "default",
+		},
+		"type":   "notification",
+		"locale": msg.Locale,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notification: %v", err)
+	}
+
+	result := s.pushAll(ctx, &apns.Notification{
+		PushType: apns.PushTypeAlert,
+		Priority: apns.PriorityImmediate,
+		Payload:  payload,
+	}, tokens)
+	fmt.Printf("Sent APNs notification (%s): %d succeeded, %d failed\n", msg.Locale, result.SuccessCount, result.FailureCount)
+	return result, nil
+}
+
+func (s *apnsRepo) SendAlarm(ctx context.Context, msg *dto.AlarmMessage, tokens []string) (*dto.MulticastResponse, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("apns client is not initialized")
+	}
+
+	aps := map[string]any{
+		"alert":              map[string]string{"title": msg.Signal, "body": msg.Content},
+		"sound":              "default",
+		"interruption-level": interruptionLevel(msg.Urgency),
+	}
+	if msg.Urgency == "immediate" && msg.Status != "cancel" && msg.Status != "expired" {
+		aps["sound"] = map[string]any{"critical": 1, "name": "default", "volume": 1.0}
+	}
+	payload := map[string]any{
+		"aps":      aps,
+		"type":     "alarm",
+		"alarm_id": msg.AlarmID,
+		"status":   msg.Status,
+		"urgency":  msg.Urgency,
+		"mode":     msg.Mode,
+		"center":   msg.Center,
+		"locale":   msg.Locale,
+	}
+	if msg.HasAreas {
+		payload["has_areas"] = true
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode alarm: %v", err)
+	}
+
+	result := s.pushAll(ctx, &apns.Notification{
+		PushType:   apns.PushTypeAlert,
+		Priority:   apns.PriorityImmediate,
+		Expiration: msg.ExpiresAt,
+		CollapseID: collapseID(msg.AlarmID),
+		Payload:    body,
+	}, tokens)
+	fmt.Printf("Sent APNs alarm %s (%s, %s): %d succeeded, %d failed\n", msg.AlarmID, msg.Status, msg.Locale, result.SuccessCount, result.FailureCount)
+	return result, nil
+}
+
+// pushAll sends a copy of n to every token.
+func (s *apnsRepo) pushAll(ctx context.Context, n *apns.Notification, tokens []string) *dto.MulticastResponse {
+	return pushEach(tokens, apnsConcurrency, func(token string) pushOutcome {
+		notification := *n
+		notification.DeviceToken = token
+		resp, err := s.client.Push(ctx, &notification)
+		if err != nil {
+			return pushOutcome{Reason: err.Error()}
+		}
+		if !resp.Sent() {
+			return pushOutcome{Reason: resp.Reason, Code: apnsErrorCode(resp.Reason)}
+		}
+		return pushOutcome{Sent: true, MessageID: resp.ID}
+	})
+}
+
+// apnsErrorCode maps APNs rejection reasons onto the dead-token codes.
+// BadDeviceToken is left out: APNs also gives it for a sound token sent to
+// the wrong environment, e.g. a development build's token in production.
+func apnsErrorCode(reason string) string {
+	switch reason {
+	case apns.ReasonUnregistered:
+		return dto.TokenErrorUnregistered
+	case apns.ReasonDeviceTokenNotForTopic:
+		return dto.TokenErrorInvalidArgument
+	}
+	return ""
+}
+
+// collapseID fits an alarm ID into the apns-collapse-id header, replacing IDs
+// longer than APNs allows with their SHA-256.
+func collapseID(alarmID string) string {
+	if len(alarmID) <= apns.MaxCollapseIDLength {
+		return alarmID
+	}
+	sum := sha256.Sum256([]byte(alarmID))
+	return hex.EncodeToString(sum[:])
+}
+
+// interruptionLevel maps alarm urgency onto iOS interruption levels.
+func interruptionLevel(urgency string) string {
+	switch urgency {
+	case "immediate":
+		return "critical"
+	case "high":
+		return "time-sensitive"
+	}
+	return "active"
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/apns"
+	"pbmap_api/src/pkg/apns/apnstest"
+)
+
+const (
+	apnsToken      = "a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"
+	apnsGoneToken  = "0000000000000000000000000000000000000000000000000000000000000001"
+	apnsBadToken   = "not-hex"
+	apnsTestTopic  = "com.example.pbmap"
+	apnsTestTeamID = "TEAM123"
+)
+
+func newTestAPNsRepo(t *testing.T) (repositories.PushSender, *apnstest.Server) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := apnstest.NewServer()
+	srv := fake.StartTLS()
+	t.Cleanup(srv.Close)
+	client, err := apns.NewClient(apns.Config{
+		Endpoint: srv.URL,
+		KeyID:    "KEY123",
+		TeamID:   apnsTestTeamID,
+		Topic:    apnsTestTopic,
+		Key:      pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+	}, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewAPNsRepoWithClient(client), fake
+}
+
+func TestAPNsSendAlarm(t *testing.T) {
+	sender, fake := newTestAPNsRepo(t)
+	fake.Unregister(apnsGoneToken)
+
+	alarmID := strings.Repeat("x", 100)
+	result, err := sender.SendAlarm(context.Background(), &dto.AlarmMessage{
+		AlarmID:  alarmID,
+		Status:   "new",
+		Urgency:  "immediate",
+		Mode:     "live",
+		HasAreas: true,
+		Signal:   "Evacuate",
+		Content:  "Leave now",
+		Locale:   "en",
+	}, []string{apnsToken, apnsGoneToken, apnsBadToken})
+	if err != nil {
+		t.Fatalf("SendAlarm: %v", err)
+	}
+
+	if result.SuccessCount != 1 || result.FailureCount != 2 {
+		t.Fatalf("result = %+v, want 1 sent and 2 failed", result)
+	}
+	codes := make(map[string]string)
+	for _, f := range result.FailureTokens {
+		codes[f.Token] = f.Code
+	}
+	if codes[apnsGoneToken] != dto.TokenErrorUnregistered {
+		t.Errorf("unregistered token code = %q, want %q", codes[apnsGoneToken], dto.TokenErrorUnregistered)
+	}
+	if code, ok := codes[apnsBadToken]; !ok || code != "" {
+		t.Errorf("bad device token code = %q, want no dead-token code", code)
+	}
+
+	requests := fake.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	if len(req.CollapseID) != apns.MaxCollapseIDLength || req.CollapseID != collapseID(alarmID) {
+		t.Errorf("collapse ID = %q, want the 64-byte hash of the alarm ID", req.CollapseID)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(req.Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := payload["areas"]; ok {
+		t.Error("payload carries the alarm geometry")
+	}
+	if payload["has_areas"] != true {
+		t.Errorf("has_areas = %v, want true", payload["has_areas"])
+	}
+	aps := payload["aps"].(map[string]any)
+	if aps["interruption-level"] != "critical" {
+		t.Errorf("interruption-level = %v, want critical", aps["interruption-level"])
+	}
+}
+
+func TestCollapseID(t *testing.T) {
+	if got := collapseID("alarm-1"); got != "alarm-1" {
+		t.Errorf("collapseID(short) = %q, want it unchanged", got)
+	}
+	exact := strings.Repeat("a", apns.MaxCollapseIDLength)
+	if got := collapseID(exact); got != exact {
+		t.Errorf("collapseID(64 bytes) = %q, want it unchanged", got)
+	}
+	long := strings.Repeat("a", apns.MaxCollapseIDLength+1)
+	if got := collapseID(long); len(got) != apns.MaxCollapseIDLength || got == long[:apns.MaxCollapseIDLength] {
+		t.Errorf("collapseID(65 bytes) = %q, want a 64-byte hash", got)
+	}
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// deliveryRecordBatchSize keeps inserts for large broadcasts under Postgres'
+// bind parameter limit.
+const deliveryRecordBatchSize = 1000
+
+// deliveryTarget identifies one target of one message, whatever the attempt.
+const deliveryTarget = "(outbox_message_id, provider, target)"
+
+type deliveryRecordRepository struct {
+	db *gorm.DB
+}
+
+func NewDeliveryRecordRepository(db *gorm.DB) repositories.DeliveryRecordRepository {
+	return &deliveryRecordRepository{db: db}
+}
+
+func (r *deliveryRecordRepository) CreateBatch(ctx context.Context, records []entities.DeliveryRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return GetDB(ctx, r.db).CreateInBatches(records, deliveryRecordBatchSize).Error
+}
+
+func (r *deliveryRecordRepository) FindSent(ctx context.Context, outboxMessageID uuid.UUID) ([]entities.DeliveryRecord, error) {
+	var records []entities.DeliveryRecord
+	err := GetDB(ctx, r.db).Model(&entities.DeliveryRecord{}).
+		Distinct("provider", "target").
+		Where("outbox_message_id = ? AND status = ?", outboxMessageID, "sent").
+		Find(&records).Error
+	return records, err
+}
+
+func (r *deliveryRecordRepository) Summarize(ctx context.Context, filter dto.DeliveryFilter, group string) ([]dto.DeliveryStatsRow, error) {
+	query := GetDB(ctx, r.db).Model(&entities.DeliveryRecord{})
+
+	var columns, order string
+	switch group {
+	case dto.DeliveryGroupAlarm:
+		columns, order = "alarm_id", "MIN(created_at)"
+		query = query.Where("alarm_id IS NOT NULL")
+	case dto.DeliveryGroupMessage:
+		columns, order = "outbox_message_id, kind", "MIN(created_at)"
+	case dto.DeliveryGroupPlatform:
+		columns, order = "provider, device_type", "provider, device_type"
+	default:
+		return nil, fmt.Errorf("unknown delivery grouping %q", group)
+	}
+
+	if filter.AlarmID != "" {
+		query = query.Where("alarm_id = ?", filter.AlarmID)
+	}
+	if filter.OutboxMessageID != nil {
+		query = query.Where("outbox_message_id = ?", *filter.OutboxMessageID)
+	}
+	if len(filter.Kinds) > 0 {
+		query = query.Where("kind IN ?", filter.Kinds)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+
+	var rows []dto.DeliveryStatsRow
+	err := query.
+		Select(columns+", COUNT(*) AS attempts, "+
+			"COUNT(DISTINCT "+deliveryTarget+") AS targets, "+
+			"COUNT(DISTINCT "+deliveryTarget+") FILTER (WHERE status = ?) AS delivered", "sent").
+		Group(columns).
+		Order(order).
+		Scan(&rows).Error
+	return rows, err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"pbmap_api/src/internal/domain/entities"
 	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/pkg/geo"
 	"time"
 
 	"github.com/google/uuid"
@@ -36,6 +37,12 @@ func (r *deviceRepository) UpsertDevice(ctx context.Context, device *entities.Us
 			existing.LastSeen = time.Now()
 			existing.DeviceType = device.DeviceType
 			existing.Provider = device.Provider
+			if device.Locale != "" {
+				existing.Locale = device.Locale
+			}
+			// Registering the token again means the client holds it as valid.
+			existing.InvalidatedAt = nil
+			existing.InvalidReason = ""
 			device.ID = existing.ID
 			return GetDB(ctx, r.db).Save(&existing).Error
 		}
@@ -43,3 +50,114 @@ func (r *deviceRepository) UpsertDevice(ctx context.Context, device *entities.Us
 
 	return GetDB(ctx, r.db).Create(device).Error
 }
+
+func (r *deviceRepository) UpdateLocation(ctx context.Context, userID, deviceID uuid.UUID, lat, lng float64) error {
+	now := time.Now()
+	result := GetDB(ctx, r.db).Model(&entities.UserDevice{}).
+		Where("id = ? AND user_id = ?", deviceID, userID).
+		Updates(map[string]interface{}{
+			"latitude":            lat,
+			"longitude":           lng,
+			"location_updated_at": now,
+			"last_seen":           now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *deviceRepository) UpdatePushToken(ctx context.Context, deviceID uuid.UUID, token string) error {
+	result := GetDB(ctx, r.db).Model(&entities.UserDevice{}).
+		Where("id = ?", deviceID).
+		Updates(map[string]interface{}{
+			"push_token":     token,
+			"last_seen":      time.Now(),
+			"invalidated_at": nil,
+			"invalid_reason": "",
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// InvalidatePushTokens marks the devices holding tokens as invalid and returns
+// the IDs of the devices that were still valid.
+func (r *deviceRepository) InvalidatePushTokens(ctx context.Context, tokens []string, reason string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if len(tokens) == 0 {
+		return ids, nil
+	}
+
+	err := GetDB(ctx, r.db).Model(&entities.UserDevice{}).
+		Where("push_token IN ? AND invalidated_at IS NULL", tokens).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return ids, err
+	}
+
+	err = GetDB(ctx, r.db).Model(&entities.UserDevice{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"invalidated_at": time.Now(),
+			"invalid_reason": reason,
+		}).Error
+	return ids, err
+}
+
+func (r *deviceRepository) FindByID(ctx context.Context, id uuid.UUID) (*entities.UserDevice, error) {
+	var device entities.UserDevice
+	if err := GetDB(ctx, r.db).First(&device, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *deviceRepository) FindWithinBounds(ctx context.Context, bounds geo.Bounds) ([]entities.UserDevice, error) {
+	var devices []entities.UserDevice
+	err := GetDB(ctx, r.db).
+		Where("push_token <> '' AND invalidated_at IS NULL").
+		Where("latitude BETWEEN ? AND ?", bounds.MinLat, bounds.MaxLat).
+		Where("longitude BETWEEN ? AND ?", bounds.MinLng, bounds.MaxLng).
+		Find(&devices).Error
+	return devices, err
+}
+
+// FindDrillEnrolled returns devices enrolled in the drill group directly or
+// through their user.
+func (r *deviceRepository) FindDrillEnrolled(ctx context.Context) ([]entities.UserDevice, error) {
+	var devices []entities.UserDevice
+	err := GetDB(ctx, r.db).
+		Where("push_token <> '' AND invalidated_at IS NULL").
+		Where("id IN (?) OR user_id IN (?)",
+			GetDB(ctx, r.db).Model(&entities.DrillEnrollment{}).Select("device_id").Where("device_id IS NOT NULL"),
+			GetDB(ctx, r.db).Model(&entities.DrillEnrollment{}).Select("user_id").Where("user_id IS NOT NULL"),
+		).
+		Find(&devices).Error
+	return devices, err
+}
+
+func (r *deviceRepository) FindByPushTokens(ctx context.Context, tokens []string) ([]entities.UserDevice, error) {
+	var devices []entities.UserDevice
+	if len(tokens) == 0 {
+		return devices, nil
+	}
+	err := GetDB(ctx, r.db).Where("invalidated_at IS NULL AND push_token IN ?", tokens).Find(&devices).Error
+	return devices, err
+}
+
+func (r *deviceRepository) FindByUserIDs(ctx context.Context, userIDs []uuid.UUID) ([]entities.UserDevice, error) {
+	var devices []entities.UserDevice
+	if len(userIDs) == 0 {
+		return devices, nil
+	}
+	err := GetDB(ctx, r.db).Where("push_token <> '' AND invalidated_at IS NULL").Where("user_id IN ?", userIDs).Find(&devices).Error
+	return devices, err
+}
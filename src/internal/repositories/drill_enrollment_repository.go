@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type drillEnrollmentRepository struct {
+	db *gorm.DB
+}
+
+func NewDrillEnrollmentRepository(db *gorm.DB) repositories.DrillEnrollmentRepository {
+	return &drillEnrollmentRepository{db: db}
+}
+
+func (r *drillEnrollmentRepository) Create(ctx context.Context, enrollment *entities.DrillEnrollment) error {
+	return GetDB(ctx, r.db).Create(enrollment).Error
+}
+
+func (r *drillEnrollmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := GetDB(ctx, r.db).Delete(&entities.DrillEnrollment{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *drillEnrollmentRepository) FindAll(ctx context.Context) ([]entities.DrillEnrollment, error) {
+	var enrollments []entities.DrillEnrollment
+	err := GetDB(ctx, r.db).Order("created_at DESC").Find(&enrollments).Error
+	return enrollments, err
+}
@@ -0,0 +1,346 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+
+	"firebase.google.com/go/v4/messaging"
+	"github.com/google/uuid"
+)
+
+// Ensure FCMEmulator implements repositories.FCMRepository and repositories.FCMInspector.
+var (
+	_ repositories.FCMRepository = (*FCMEmulator)(nil)
+	_ repositories.FCMInspector  = (*FCMEmulator)(nil)
+)
+
+// FCMEmulator stands in for FCM in development and integration tests. Every
+// send and topic change succeeds, except for tokens marked unregistered and
+// messages over FCM's size limits, and is recorded instead of reaching a
+// device. With a log file the calls are
+// also appended to it as JSON lines and replayed on start, so they survive a
+// restart; unregistered tokens are kept in memory only.
+type FCMEmulator struct {
+	mu           sync.Mutex
+	calls        []dto.FCMEmulatorCall
+	topics       map[string]map[string]bool
+	unregistered map[string]bool
+	log          *os.File
+}
+
+// NewFCMEmulator creates the emulator, keeping calls in memory only when
+// logPath is empty.
+func NewFCMEmulator(logPath string) (*FCMEmulator, error) {
+	e := &FCMEmulator{
+		topics:       make(map[string]map[string]bool),
+		unregistered: make(map[string]bool),
+	}
+	if logPath == "" {
+		return e, nil
+	}
+
+	log, err := os.OpenFile(logPath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open FCM emulator log: %v", err)
+	}
+	decoder := json.NewDecoder(log)
+	for {
+		var call dto.FCMEmulatorCall
+		if err := decoder.Decode(&call); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			log.Close()
+			return nil, fmt.Errorf("failed to read FCM emulator log: %v", err)
+		}
+		e.apply(call)
+	}
+	e.log = log
+	return e, nil
+}
+
+func (e *FCMEmulator) SendNotification(ctx context.Context, msg *dto.NotificationMessage, tokens []string) (*dto.MulticastResponse, error) {
+	return e.send(dto.FCMCallNotification, notificationMulticast(msg), tokens)
+}
+
+func (e *FCMEmulator) SendAlarm(ctx context.Context, msg *dto.AlarmMessage, tokens []string) (*dto.MulticastResponse, error) {
+	return e.send(dto.FCMCallAlarm, alarmMulticast(msg), tokens)
+}
+
+// SendNotificationToTopic records a notification reaching the tokens
+// subscribed to topic at the time.
+func (e *FCMEmulator) SendNotificationToTopic(ctx context.Context, msg *dto.NotificationMessage, topic string) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	tokens := make([]string, 0, len(e.topics[topic]))
+	for token := range e.topics[topic] {
+		tokens = append(tokens, token)
+	}
+	slices.Sort(tokens)
+
+	message := notificationMulticast(msg)
+	if err := checkPayloadSize(message); err != nil {
+		return "", fmt.Errorf("failed to send notification to topic %s: %v", topic, err)
+	}
+	id := "projects/emulator/messages/" + uuid.NewString()
+	call := dto.FCMEmulatorCall{
+		Kind:       dto.FCMCallNotification,
+		Tokens:     tokens,
+		Topic:      topic,
+		Title:      message.Notification.Title,
+		Body:       message.Notification.Body,
+		Data:       message.Data,
+		MessageIDs: []string{id},
+	}
+	if err := e.record(call); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (e *FCMEmulator) SubscribeToTopic(ctx context.Context, tokens []string, topic string) (*dto.TopicManagementResponse, error) {
+	return e.manageTopic(dto.FCMCallSubscribe, tokens, topic)
+}
+
+func (e *FCMEmulator) UnsubscribeFromTopic(ctx context.Context, tokens []string, topic string) (*dto.TopicManagementResponse, error) {
+	return e.manageTopic(dto.FCMCallUnsubscribe, tokens, topic)
+}
+
+// send splits tokens into requests of at most fcmMaxTokens, as fcmRepo
+// does, recording each request as a call.
+func (e *FCMEmulator) send(kind string, message *messaging.MulticastMessage, tokens []string) (*dto.MulticastResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result := &dto.MulticastResponse{
+		MessageIDs:    make([]string, 0),
+		SuccessTokens: make([]string, 0),
+		FailureTokens: make([]dto.TopicManagementError, 0),
+	}
+	for i := 0; i < len(tokens); i += fcmMaxTokens {
+		sent, err := e.multicast(kind, message, tokens[i:min(i+fcmMaxTokens, len(tokens))])
+		if err != nil {
+			return nil, err
+		}
+		result.SuccessCount += sent.SuccessCount
+		result.FailureCount += sent.FailureCount
+		result.MessageIDs = append(result.MessageIDs, sent.MessageIDs...)
+		result.SuccessTokens = append(result.SuccessTokens, sent.SuccessTokens...)
+		result.FailureTokens = append(result.FailureTokens, sent.FailureTokens...)
+	}
+	return result, nil
+}
+
+// multicast answers one multicast request as FCM would: more than
+// fcmMaxTokens tokens fail the request, and a payload over the size limit
+// fails every token with invalid-argument. e.mu must be held.
+func (e *FCMEmulator) multicast(kind string, message *messaging.MulticastMessage, tokens []string) (*dto.MulticastResponse, error) {
+	if len(tokens) > fcmMaxTokens {
+		return nil, fmt.Errorf("tokens must not contain more than %d tokens", fcmMaxTokens)
+	}
+	tooBig := checkPayloadSize(message)
+
+	result := &dto.MulticastResponse{
+		MessageIDs:    make([]string, 0),
+		SuccessTokens: make([]string, 0),
+		FailureTokens: make([]dto.TopicManagementError, 0),
+	}
+	for _, token := range tokens {
+		switch {
+		case tooBig != nil:
+			result.FailureCount++
+			result.FailureTokens = append(result.FailureTokens, dto.TopicManagementError{
+				Token:  token,
+				Reason: tooBig.Error(),
+				Code:   dto.TokenErrorInvalidArgument,
+			})
+		case e.unregistered[token]:
+			result.FailureCount++
+			result.FailureTokens = append(result.FailureTokens, dto.TopicManagementError{
+				Token:  token,
+				Reason: "Requested entity was not found.",
+				Code:   dto.TokenErrorUnregistered,
+			})
+		default:
+			result.SuccessCount++
+			result.SuccessTokens = append(result.SuccessTokens, token)
+			result.MessageIDs = append(result.MessageIDs, "projects/emulator/messages/"+uuid.NewString())
+		}
+	}
+
+	call := dto.FCMEmulatorCall{
+		Kind:          kind,
+		Tokens:        slices.Clone(tokens),
+		Data:          message.Data,
+		MessageIDs:    result.MessageIDs,
+		FailureTokens: result.FailureTokens,
+	}
+	if message.Notification != nil {
+		call.Title = message.Notification.Title
+		call.Body = message.Notification.Body
+	}
+	if err := e.record(call); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkPayloadSize rejects a message whose data and notification text come
+// to more than fcmMaxPayloadSize bytes, with the reason FCM gives.
+func checkPayloadSize(message *messaging.MulticastMessage) error {
+	size := 0
+	for key, value := range message.Data {
+		size += len(key) + len(value)
+	}
+	if message.Notification != nil {
+		size += len(message.Notification.Title) + len(message.Notification.Body)
+	}
+	if size > fcmMaxPayloadSize {
+		return fmt.Errorf("message is too big: %d bytes of payload, at most %d", size, fcmMaxPayloadSize)
+	}
+	return nil
+}
+
+func (e *FCMEmulator) manageTopic(kind string, tokens []string, topic string) (*dto.TopicManagementResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result := &dto.TopicManagementResponse{
+		SuccessTokens: make([]string, 0),
+		FailureTokens: make([]dto.TopicManagementError, 0),
+	}
+	for _, token := range tokens {
+		if e.unregistered[token] {
+			result.FailureTokens = append(result.FailureTokens, dto.TopicManagementError{
+				Token:  token,
+				Reason: "registration-token-not-registered",
+				Code:   dto.TokenErrorUnregistered,
+			})
+			continue
+		}
+		result.SuccessTokens = append(result.SuccessTokens, token)
+	}
+
+	call := dto.FCMEmulatorCall{
+		Kind:          kind,
+		Tokens:        result.SuccessTokens,
+		Topic:         topic,
+		FailureTokens: result.FailureTokens,
+	}
+	if err := e.record(call); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// record keeps a call, appending it to the log first when there is one.
+// e.mu must be held.
+func (e *FCMEmulator) record(call dto.FCMEmulatorCall) error {
+	call.ID = uuid.NewString()
+	call.At = time.Now()
+	if e.log != nil {
+		line, err := json.Marshal(call)
+		if err != nil {
+			return fmt.Errorf("failed to encode FCM emulator call: %v", err)
+		}
+		if _, err := e.log.Write(append(line, '\n')); err != nil {
+			return fmt.Errorf("failed to write FCM emulator log: %v", err)
+		}
+	}
+	e.apply(call)
+	return nil
+}
+
+// apply adds a call to the state. e.mu must be held.
+func (e *FCMEmulator) apply(call dto.FCMEmulatorCall) {
+	e.calls = append(e.calls, call)
+	switch call.Kind {
+	case dto.FCMCallSubscribe:
+		if e.topics[call.Topic] == nil {
+			e.topics[call.Topic] = make(map[string]bool)
+		}
+		for _, token := range call.Tokens {
+			e.topics[call.Topic][token] = true
+		}
+	case dto.FCMCallUnsubscribe:
+		for _, token := range call.Tokens {
+			delete(e.topics[call.Topic], token)
+		}
+		if len(e.topics[call.Topic]) == 0 {
+			delete(e.topics, call.Topic)
+		}
+	}
+}
+
+// Calls returns the recorded calls matching query, oldest first.
+func (e *FCMEmulator) Calls(query dto.FCMEmulatorQuery) []dto.FCMEmulatorCall {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	calls := make([]dto.FCMEmulatorCall, 0, len(e.calls))
+	for _, call := range e.calls {
+		if query.Kind != "" && call.Kind != query.Kind {
+			continue
+		}
+		if query.Topic != "" && call.Topic != query.Topic {
+			continue
+		}
+		if query.Token != "" && !slices.Contains(call.Tokens, query.Token) {
+			continue
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// Topics returns the tokens subscribed to each topic, sorted.
+func (e *FCMEmulator) Topics() map[string][]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	topics := make(map[string][]string, len(e.topics))
+	for topic, tokens := range e.topics {
+		list := make([]string, 0, len(tokens))
+		for token := range tokens {
+			list = append(list, token)
+		}
+		slices.Sort(list)
+		topics[topic] = list
+	}
+	return topics
+}
+
+func (e *FCMEmulator) Unregister(tokens []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, token := range tokens {
+		e.unregistered[token] = true
+	}
+}
+
+// Reset forgets everything, emptying the log.
+func (e *FCMEmulator) Reset() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.log != nil {
+		if err := e.log.Truncate(0); err != nil {
+			return fmt.Errorf("failed to empty FCM emulator log: %v", err)
+		}
+	}
+	e.calls = nil
+	e.topics = make(map[string]map[string]bool)
+	e.unregistered = make(map[string]bool)
+	return nil
+}
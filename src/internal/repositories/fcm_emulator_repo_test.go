@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"pbmap_api/src/internal/dto"
+)
+
+func fcmTokens(n int) []string {
+	tokens := make([]string, n)
+	for i := range tokens {
+		tokens[i] = fmt.Sprintf("token-%d", i)
+	}
+	return tokens
+}
+
+func TestFCMEmulatorSplitsRequests(t *testing.T) {
+	emulator, err := NewFCMEmulator("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := fcmTokens(2*fcmMaxTokens + 1)
+
+	result, err := emulator.SendNotification(context.Background(), &dto.NotificationMessage{Title: "Title", Body: "Body", Locale: "en"}, tokens)
+	if err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	if result.SuccessCount != len(tokens) || result.FailureCount != 0 {
+		t.Fatalf("result: %d succeeded, %d failed; want all %d sent", result.SuccessCount, result.FailureCount, len(tokens))
+	}
+	calls := emulator.Calls(dto.FCMEmulatorQuery{})
+	sizes := make([]int, len(calls))
+	for i, call := range calls {
+		sizes[i] = len(call.Tokens)
+	}
+	if fmt.Sprint(sizes) != fmt.Sprint([]int{fcmMaxTokens, fcmMaxTokens, 1}) {
+		t.Fatalf("request sizes = %v, want [500 500 1]", sizes)
+	}
+
+	emulator.mu.Lock()
+	defer emulator.mu.Unlock()
+	if _, err := emulator.multicast(dto.FCMCallNotification, notificationMulticast(&dto.NotificationMessage{Title: "Title", Body: "Body"}), fcmTokens(fcmMaxTokens+1)); err == nil {
+		t.Fatal("a request over the token limit was accepted")
+	}
+}
+
+func TestFCMEmulatorRejectsLargePayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"fits", strings.Repeat("a", 3500), false},
+		{"too big", strings.Repeat("a", fcmMaxPayloadSize), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emulator, err := NewFCMEmulator("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tokens := fcmTokens(3)
+			result, err := emulator.SendAlarm(context.Background(), &dto.AlarmMessage{
+				AlarmID: "alarm-1",
+				Status:  "new",
+				Signal:  "Evacuate",
+				Content: tt.content,
+				Locale:  "en",
+			}, tokens)
+			if err != nil {
+				t.Fatalf("SendAlarm: %v", err)
+			}
+			if !tt.wantErr {
+				if result.SuccessCount != len(tokens) {
+					t.Fatalf("%d of %d tokens sent, want all", result.SuccessCount, len(tokens))
+				}
+				return
+			}
+			if result.SuccessCount != 0 || len(result.MessageIDs) != 0 || len(result.FailureTokens) != len(tokens) {
+				t.Fatalf("result: %d succeeded, %d failed; want every token failed", result.SuccessCount, len(result.FailureTokens))
+			}
+			for _, f := range result.FailureTokens {
+				if f.Code != dto.TokenErrorInvalidArgument {
+					t.Fatalf("failure = %+v, want invalid-argument", f)
+				}
+			}
+		})
+	}
+
+	emulator, err := NewFCMEmulator("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = emulator.SendNotificationToTopic(context.Background(), &dto.NotificationMessage{Title: "Title", Body: strings.Repeat("a", fcmMaxPayloadSize)}, "all")
+	if err == nil {
+		t.Fatal("an oversized topic message was accepted")
+	}
+}
@@ -3,13 +3,16 @@ package repositories
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"pbmap_api/src/internal/domain/repositories"
 	"pbmap_api/src/internal/dto"
 	"pbmap_api/src/pkg/config"
 
 	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/errorutils"
 	"firebase.google.com/go/v4/messaging"
 	"google.golang.org/api/option"
 )
@@ -17,14 +20,39 @@ import (
 // Ensure fcmRepo implements repositories.FCMRepository.
 var _ repositories.FCMRepository = (*fcmRepo)(nil)
 
+// ErrFCMTransient marks FCM failures that may succeed when retried: the
+// service was unavailable, failed internally, throttled us or timed out.
+var ErrFCMTransient = errors.New("transient FCM error")
+
+const (
+	// fcmMaxTokens is the most tokens FCM accepts in one multicast request.
+	fcmMaxTokens = 500
+	// fcmMaxPayloadSize is the most bytes FCM accepts in the payload of a
+	// message, data keys and values and notification text together.
+	fcmMaxPayloadSize = 4096
+)
+
 type fcmRepo struct {
-	client *messaging.Client
+	client  *messaging.Client
+	timeout time.Duration
 }
 
-// NewFCMRepo creates the FCM repository (implements repositories.FCMRepository).
+// NewFCMRepo creates the FCM repository (implements repositories.FCMRepository)
+// selected by FCM_PROVIDER: Firebase, or the emulator keeping calls in memory
+// or also in a JSON log.
 func NewFCMRepo(cfg *config.Config) (repositories.FCMRepository, error) {
+	switch cfg.FCMProvider {
+	case "", "firebase":
+	case "memory":
+		return NewFCMEmulator("")
+	case "jsonlog":
+		return NewFCMEmulator(cfg.FCMEmulatorLog)
+	default:
+		return nil, fmt.Errorf("unknown fcm provider %q", cfg.FCMProvider)
+	}
+
 	if cfg.FirebaseCredentialsPath == "" {
-		return &fcmRepo{}, nil
+		return &fcmRepo{timeout: cfg.FCMCallTimeout}, nil
 	}
 
 	opt := option.WithCredentialsFile(cfg.FirebaseCredentialsPath)
@@ -38,18 +66,64 @@ func NewFCMRepo(cfg *config.Config) (repositories.FCMRepository, error) {
 		return nil, fmt.Errorf("error getting messaging client: %v", err)
 	}
 
-	return &fcmRepo{client: client}, nil
+	return &fcmRepo{client: client, timeout: cfg.FCMCallTimeout}, nil
 }
 
-func (s *fcmRepo) BroadcastNotification(ctx context.Context, title, body string) error {
+func (s *fcmRepo) SendNotification(ctx context.Context, msg *dto.NotificationMessage, tokens []string) (*dto.MulticastResponse, error) {
 	if s.client == nil {
-		return fmt.Errorf("firebase client is not initialized")
+		return nil, fmt.Errorf("firebase client is not initialized")
 	}
 
-	topic := "all_devices"
-	message := &messaging.Message{
-		Data:         map[string]string{"type": "notification"},
-		Notification: &messaging.Notification{Title: title, Body: body},
+	result, err := s.sendMulticast(ctx, notificationMulticast(msg), tokens)
+	if err != nil {
+		return nil, fcmError("failed to send notification to devices", err)
+	}
+	fmt.Printf("Sent notification (%s): %d succeeded, %d failed\n", msg.Locale, result.SuccessCount, result.FailureCount)
+	return result, nil
+}
+
+func (s *fcmRepo) SendAlarm(ctx context.Context, msg *dto.AlarmMessage, tokens []string) (*dto.MulticastResponse, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("firebase client is not initialized")
+	}
+
+	result, err := s.sendMulticast(ctx, alarmMulticast(msg), tokens)
+	if err != nil {
+		return nil, fcmError("failed to send alarm to devices", err)
+	}
+	fmt.Printf("Sent alarm %s (%s, %s): %d succeeded, %d failed\n", msg.AlarmID, msg.Status, msg.Locale, result.SuccessCount, result.FailureCount)
+	return result, nil
+}
+
+func (s *fcmRepo) SendNotificationToTopic(ctx context.Context, msg *dto.NotificationMessage, topic string) (string, error) {
+	if s.client == nil {
+		return "", fmt.Errorf("firebase client is not initialized")
+	}
+
+	multicast := notificationMulticast(msg)
+	var id string
+	err := s.request(ctx, func(ctx context.Context) error {
+		var err error
+		id, err = s.client.Send(ctx, &messaging.Message{
+			Data:         multicast.Data,
+			Notification: multicast.Notification,
+			Android:      multicast.Android,
+			Topic:        topic,
+		})
+		return err
+	})
+	if err != nil {
+		return "", fcmError("failed to send notification to topic "+topic, err)
+	}
+	fmt.Printf("Sent notification (%s) to topic %s: %s\n", msg.Locale, topic, id)
+	return id, nil
+}
+
+// notificationMulticast is the FCM message of a notification.
+func notificationMulticast(msg *dto.NotificationMessage) *messaging.MulticastMessage {
+	return &messaging.MulticastMessage{
+		Data:         map[string]string{"type": "notification", "locale": msg.Locale},
+		Notification: &messaging.Notification{Title: msg.Title, Body: msg.Body},
 		Android: &messaging.AndroidConfig{
 			Priority: "high",
 			Notification: &messaging.AndroidNotification{
@@ -57,44 +131,100 @@ func (s *fcmRepo) BroadcastNotification(ctx context.Context, title, body string)
 				ChannelID: "high_importance_channel",
 			},
 		},
-		Topic: topic,
 	}
+}
 
-	response, err := s.client.Send(ctx, message)
-	if err != nil {
-		return fmt.Errorf("failed to send message to topic %s: %v", topic, err)
+// alarmMulticast is the data-only FCM message of an alarm, which the app
+// renders itself. Polygon areas would not fit in FCM's 4KB data limit, so the
+// app gets the covering circle and fetches the geometry by alarm_id.
+func alarmMulticast(msg *dto.AlarmMessage) *messaging.MulticastMessage {
+	centerJSON, _ := json.Marshal(msg.Center)
+	data := map[string]string{
+		"type":     "alarm",
+		"alarm_id": msg.AlarmID,
+		"status":   msg.Status,
+		"urgency":  msg.Urgency,
+		"mode":     msg.Mode,
+		"center":   string(centerJSON),
+		"signal":   msg.Signal,
+		"content":  msg.Content,
+		"locale":   msg.Locale,
+	}
+	if msg.HasAreas {
+		data["has_areas"] = "true"
+	}
+	if msg.ExpiresAt != nil {
+		data["expires_at"] = msg.ExpiresAt.UTC().Format(time.RFC3339)
 	}
-	fmt.Printf("Successfully sent message: %s\n", response)
-	return nil
-}
 
-func (s *fcmRepo) SendAlarm(ctx context.Context, req *dto.AlarmDispatchRequest) error {
-	if s.client == nil {
-		return fmt.Errorf("firebase client is not initialized")
-	}
-
-	centerJSON, _ := json.Marshal(req.Center)
-	message := &messaging.Message{
-		Data: map[string]string{
-			"type":     "alarm",
-			"alarm_id": req.AlarmID,
-			"urgency":  req.Urgency,
-			"center":   string(centerJSON),
-			"signal":   req.Signal,
-			"content":  req.Content,
-		},
+	return &messaging.MulticastMessage{
+		Data: data,
 		Android: &messaging.AndroidConfig{
 			Priority: "high",
 		},
-		Topic: "all_devices",
 	}
+}
 
-	response, err := s.client.Send(ctx, message)
-	if err != nil {
-		return fmt.Errorf("failed to send alarm to topic: %v", err)
+// sendMulticast sends message to tokens in batches and collects the per-token
+// results. When a batch fails after earlier ones were sent, the tokens not yet
+// sent to are reported as transient failures so that only they are retried.
+func (s *fcmRepo) sendMulticast(ctx context.Context, message *messaging.MulticastMessage, tokens []string) (*dto.MulticastResponse, error) {
+	result := &dto.MulticastResponse{
+		MessageIDs:    make([]string, 0),
+		SuccessTokens: make([]string, 0),
+		FailureTokens: make([]dto.TopicManagementError, 0),
+	}
+
+	batchSize := fcmMaxTokens
+	for i := 0; i < len(tokens); i += batchSize {
+		end := i + batchSize
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		batch := *message
+		batch.Tokens = tokens[i:end]
+
+		var response *messaging.BatchResponse
+		err := s.request(ctx, func(ctx context.Context) error {
+			var err error
+			response, err = s.client.SendEachForMulticast(ctx, &batch)
+			return err
+		})
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			for _, token := range tokens[i:] {
+				result.FailureCount++
+				result.FailureTokens = append(result.FailureTokens, dto.TopicManagementError{
+					Token:  token,
+					Reason: err.Error(),
+					Code:   dto.TokenErrorTransient,
+				})
+			}
+			return result, nil
+		}
+
+		result.SuccessCount += response.SuccessCount
+		result.FailureCount += response.FailureCount
+		for idx, resp := range response.Responses {
+			if resp.Success {
+				result.MessageIDs = append(result.MessageIDs, resp.MessageID)
+				result.SuccessTokens = append(result.SuccessTokens, batch.Tokens[idx])
+				continue
+			}
+			reason := "unknown error"
+			if resp.Error != nil {
+				reason = resp.Error.Error()
+			}
+			result.FailureTokens = append(result.FailureTokens, dto.TopicManagementError{
+				Token:  batch.Tokens[idx],
+				Reason: reason,
+				Code:   tokenErrorCode(resp.Error),
+			})
+		}
 	}
-	fmt.Printf("Successfully sent alarm %s: %s\n", req.AlarmID, response)
-	return nil
+	return result, nil
 }
 
 func (s *fcmRepo) SubscribeToTopic(ctx context.Context, tokens []string, topic string) (*dto.TopicManagementResponse, error) {
@@ -118,9 +248,14 @@ func (s *fcmRepo) SubscribeToTopic(ctx context.Context, tokens []string, topic s
 		}
 		batch := tokens[i:end]
 
-		response, err := s.client.SubscribeToTopic(ctx, batch, topic)
+		var response *messaging.TopicManagementResponse
+		err := s.request(ctx, func(ctx context.Context) error {
+			var err error
+			response, err = s.client.SubscribeToTopic(ctx, batch, topic)
+			return err
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to subscribe to topic: %v", err)
+			return nil, fcmError("failed to subscribe to topic", err)
 		}
 
 		failedIndices := make(map[int]string)
@@ -130,6 +265,7 @@ func (s *fcmRepo) SubscribeToTopic(ctx context.Context, tokens []string, topic s
 				result.FailureTokens = append(result.FailureTokens, dto.TopicManagementError{
 					Token:  batch[errWrap.Index],
 					Reason: errWrap.Reason,
+					Code:   topicErrorCode(errWrap.Reason),
 				})
 			}
 		}
@@ -163,9 +299,14 @@ func (s *fcmRepo) UnsubscribeFromTopic(ctx context.Context, tokens []string, top
 		}
 		batch := tokens[i:end]
 
-		response, err := s.client.UnsubscribeFromTopic(ctx, batch, topic)
+		var response *messaging.TopicManagementResponse
+		err := s.request(ctx, func(ctx context.Context) error {
+			var err error
+			response, err = s.client.UnsubscribeFromTopic(ctx, batch, topic)
+			return err
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to unsubscribe from topic: %v", err)
+			return nil, fcmError("failed to unsubscribe from topic", err)
 		}
 
 		failedIndices := make(map[int]string)
@@ -175,6 +316,7 @@ func (s *fcmRepo) UnsubscribeFromTopic(ctx context.Context, tokens []string, top
 				result.FailureTokens = append(result.FailureTokens, dto.TopicManagementError{
 					Token:  batch[errWrap.Index],
 					Reason: errWrap.Reason,
+					Code:   topicErrorCode(errWrap.Reason),
 				})
 			}
 		}
@@ -186,3 +328,67 @@ func (s *fcmRepo) UnsubscribeFromTopic(ctx context.Context, tokens []string, top
 	}
 	return result, nil
 }
+
+// request makes one request to FCM with its own timeout, so that a send in
+// many batches is not cut short by a deadline meant for one.
+func (s *fcmRepo) request(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.timeout <= 0 {
+		return fn(ctx)
+	}
+	requestCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	err := fn(requestCtx)
+	if err != nil && requestCtx.Err() != nil && ctx.Err() == nil {
+		return fmt.Errorf("%w: FCM request timed out after %s: %v", context.DeadlineExceeded, s.timeout, err)
+	}
+	return err
+}
+
+// tokenErrorCode classifies a per-token send error as one of the dead-token
+// codes, as transient, or "" when the token may still be valid.
+func tokenErrorCode(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case messaging.IsUnregistered(err):
+		return dto.TokenErrorUnregistered
+	case messaging.IsInvalidArgument(err):
+		return dto.TokenErrorInvalidArgument
+	case transientFCMError(err):
+		return dto.TokenErrorTransient
+	}
+	return ""
+}
+
+// fcmError describes a failed FCM request, marking it with ErrFCMTransient
+// when it is worth retrying. The firebase error checks do not unwrap, so this
+// must be decided on the error as the SDK returned it.
+func fcmError(msg string, err error) error {
+	if transientFCMError(err) {
+		return fmt.Errorf("%w: %s: %v", ErrFCMTransient, msg, err)
+	}
+	return fmt.Errorf("%s: %v", msg, err)
+}
+
+func transientFCMError(err error) bool {
+	return messaging.IsUnavailable(err) ||
+		messaging.IsInternal(err) ||
+		messaging.IsQuotaExceeded(err) ||
+		errorutils.IsUnavailable(err) ||
+		errorutils.IsInternal(err) ||
+		errorutils.IsResourceExhausted(err) ||
+		errorutils.IsDeadlineExceeded(err) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// topicErrorCode does the same for the reasons reported by topic management.
+func topicErrorCode(reason string) string {
+	switch reason {
+	case "NOT_FOUND", "registration-token-not-registered":
+		return dto.TokenErrorUnregistered
+	case "INVALID_ARGUMENT", "invalid-argument":
+		return dto.TokenErrorInvalidArgument
+	}
+	return ""
+}
@@ -0,0 +1,223 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/breaker"
+	"pbmap_api/src/pkg/config"
+)
+
+// fcmMaxRetryBackoff caps the wait between retries of one FCM call.
+const fcmMaxRetryBackoff = 10 * time.Second
+
+// Ensure ResilientFCM implements repositories.FCMRepository.
+var _ repositories.FCMRepository = (*ResilientFCM)(nil)
+
+// ResilientFCM guards an FCMRepository while FCM is degraded. Transient
+// failures, of the whole request or of single tokens, are retried with
+// backoff; and after repeated failures a circuit breaker fails calls fast
+// instead of letting them hang, so queued sends are retried later by the
+// outbox. Each request to FCM is timed out by the FCMRepository itself.
+type ResilientFCM struct {
+	next       repositories.FCMRepository
+	breaker    *breaker.Breaker
+	maxRetries int
+	backoff    time.Duration
+}
+
+// NewResilientFCM wraps next with the retry, timeout and breaker settings in cfg.
+func NewResilientFCM(next repositories.FCMRepository, cfg *config.Config) *ResilientFCM {
+	return &ResilientFCM{
+		next: next,
+		breaker: breaker.New(breaker.Config{
+			Threshold: cfg.FCMBreakerThreshold,
+			Cooldown:  cfg.FCMBreakerCooldown,
+		}),
+		maxRetries: cfg.FCMMaxRetries,
+		backoff:    cfg.FCMRetryBackoff,
+	}
+}
+
+// BreakerStatus reports the state of the FCM circuit breaker.
+func (r *ResilientFCM) BreakerStatus() dto.BreakerStatus {
+	status := r.breaker.Status()
+	return dto.BreakerStatus{
+		Name:                "fcm",
+		State:               status.State,
+		ConsecutiveFailures: status.ConsecutiveFailures,
+		OpenedAt:            status.OpenedAt,
+		RetryAt:             status.RetryAt,
+	}
+}
+
+func (r *ResilientFCM) SendNotification(ctx context.Context, msg *dto.NotificationMessage, tokens []string) (*dto.MulticastResponse, error) {
+	return r.sendWithRetry(ctx, tokens, func(ctx context.Context, tokens []string) (*dto.MulticastResponse, error) {
+		return r.next.SendNotification(ctx, msg, tokens)
+	})
+}
+
+func (r *ResilientFCM) SendAlarm(ctx context.Context, msg *dto.AlarmMessage, tokens []string) (*dto.MulticastResponse, error) {
+	return r.sendWithRetry(ctx, tokens, func(ctx context.Context, tokens []string) (*dto.MulticastResponse, error) {
+		return r.next.SendAlarm(ctx, msg, tokens)
+	})
+}
+
+func (r *ResilientFCM) SendNotificationToTopic(ctx context.Context, msg *dto.NotificationMessage, topic string) (string, error) {
+	var id string
+	err := r.retry(ctx, func(ctx context.Context) error {
+		var err error
+		id, err = r.next.SendNotificationToTopic(ctx, msg, topic)
+		return err
+	})
+	return id, err
+}
+
+func (r *ResilientFCM) SubscribeToTopic(ctx context.Context, tokens []string, topic string) (*dto.TopicManagementResponse, error) {
+	var result *dto.TopicManagementResponse
+	err := r.retry(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = r.next.SubscribeToTopic(ctx, tokens, topic)
+		return err
+	})
+	return result, err
+}
+
+func (r *ResilientFCM) UnsubscribeFromTopic(ctx context.Context, tokens []string, topic string) (*dto.TopicManagementResponse, error) {
+	var result *dto.TopicManagementResponse
+	err := r.retry(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = r.next.UnsubscribeFromTopic(ctx, tokens, topic)
+		return err
+	})
+	return result, err
+}
+
+// sendWithRetry sends to tokens, then resends to the tokens that failed
+// transiently until they succeed or the retries run out. Once some tokens
+// have been sent to, a later failed attempt is reported per token rather than
+// as an error, so that the message is not sent to them again.
+func (r *ResilientFCM) sendWithRetry(ctx context.Context, tokens []string, send func(ctx context.Context, tokens []string) (*dto.MulticastResponse, error)) (*dto.MulticastResponse, error) {
+	result := &dto.MulticastResponse{
+		MessageIDs:    make([]string, 0),
+		SuccessTokens: make([]string, 0),
+		FailureTokens: make([]dto.TopicManagementError, 0),
+	}
+
+	pending := tokens
+	for attempt := 0; ; attempt++ {
+		var sent *dto.MulticastResponse
+		err := r.call(ctx, func(ctx context.Context) error {
+			var err error
+			sent, err = send(ctx, pending)
+			if err == nil && sent.SuccessCount == 0 && len(pending) > 0 && allTransient(sent.FailureTokens) {
+				// Every token failing transiently is FCM failing, not the tokens.
+				return fmt.Errorf("%w: all %d tokens failed: %s", ErrFCMTransient, len(pending), sent.FailureTokens[0].Reason)
+			}
+			return err
+		})
+
+		var retry []dto.TopicManagementError
+		if err == nil {
+			result.SuccessCount += sent.SuccessCount
+			result.MessageIDs = append(result.MessageIDs, sent.MessageIDs...)
+			result.SuccessTokens = append(result.SuccessTokens, sent.SuccessTokens...)
+			for _, f := range sent.FailureTokens {
+				if f.Code == dto.TokenErrorTransient {
+					retry = append(retry, f)
+					continue
+				}
+				result.FailureCount++
+				result.FailureTokens = append(result.FailureTokens, f)
+			}
+			if len(retry) == 0 {
+				return result, nil
+			}
+		} else {
+			if result.SuccessCount == 0 && (!retryable(err) || attempt >= r.maxRetries) {
+				return nil, err
+			}
+			retry = make([]dto.TopicManagementError, 0, len(pending))
+			for _, token := range pending {
+				retry = append(retry, dto.TopicManagementError{Token: token, Reason: err.Error(), Code: dto.TokenErrorTransient})
+			}
+		}
+
+		if attempt >= r.maxRetries || (err != nil && !retryable(err)) || !r.wait(ctx, attempt) {
+			result.FailureCount += len(retry)
+			result.FailureTokens = append(result.FailureTokens, retry...)
+			return result, nil
+		}
+		pending = make([]string, 0, len(retry))
+		for _, f := range retry {
+			pending = append(pending, f.Token)
+		}
+	}
+}
+
+// retry makes a call, retrying it on transient failures.
+func (r *ResilientFCM) retry(ctx context.Context, fn func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		err := r.call(ctx, fn)
+		if err == nil || !retryable(err) || attempt >= r.maxRetries || !r.wait(ctx, attempt) {
+			return err
+		}
+	}
+}
+
+// call makes one call through the breaker. Only transient failures count
+// against FCM; a rejected request shows it is up.
+func (r *ResilientFCM) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	generation, err := r.breaker.Allow()
+	if err != nil {
+		return fmt.Errorf("FCM unavailable: %w", err)
+	}
+
+	err = fn(ctx)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// The caller gave up, which says nothing about FCM.
+		r.breaker.Abort(generation)
+	case errors.Is(err, ErrFCMTransient):
+		r.breaker.Failure(generation)
+	default:
+		r.breaker.Success(generation)
+	}
+	return err
+}
+
+// wait sleeps before the retry following attempt, doubling each time. It
+// reports false when ctx ends first.
+func (r *ResilientFCM) wait(ctx context.Context, attempt int) bool {
+	delay := r.backoff
+	for i := 0; i < attempt && delay < fcmMaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	timer := time.NewTimer(min(delay, fcmMaxRetryBackoff))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// retryable reports whether a failed call is worth making again. Calls
+// refused by the open breaker are not; the outbox retries them later.
+func retryable(err error) bool {
+	return errors.Is(err, ErrFCMTransient) && !errors.Is(err, breaker.ErrOpen)
+}
+
+func allTransient(failures []dto.TopicManagementError) bool {
+	for _, f := range failures {
+		if f.Code != dto.TokenErrorTransient {
+			return false
+		}
+	}
+	return len(failures) > 0
+}
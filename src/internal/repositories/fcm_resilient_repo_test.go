@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/config"
+)
+
+// stubFCM fails the tokens in transient until they are sent again, the way
+// fcmRepo reports the tokens of a batch it could not send.
+type stubFCM struct {
+	repositories.FCMRepository
+	transient map[string]bool
+	sends     [][]string
+}
+
+func (f *stubFCM) SendAlarm(ctx context.Context, msg *dto.AlarmMessage, tokens []string) (*dto.MulticastResponse, error) {
+	f.sends = append(f.sends, tokens)
+	result := &dto.MulticastResponse{}
+	for _, token := range tokens {
+		if f.transient[token] {
+			delete(f.transient, token)
+			result.FailureCount++
+			result.FailureTokens = append(result.FailureTokens, dto.TopicManagementError{Token: token, Reason: "batch not sent", Code: dto.TokenErrorTransient})
+			continue
+		}
+		result.SuccessCount++
+		result.SuccessTokens = append(result.SuccessTokens, token)
+		result.MessageIDs = append(result.MessageIDs, "id-"+token)
+	}
+	return result, nil
+}
+
+func TestResilientFCMResendsOnlyUnsentTokens(t *testing.T) {
+	next := &stubFCM{transient: map[string]bool{"c": true, "d": true}}
+	fcm := NewResilientFCM(next, &config.Config{FCMMaxRetries: 2, FCMRetryBackoff: time.Millisecond, FCMBreakerThreshold: 5, FCMBreakerCooldown: time.Second})
+
+	result, err := fcm.SendAlarm(context.Background(), &dto.AlarmMessage{}, []string{"a", "b", "c", "d"})
+	if err != nil {
+		t.Fatalf("SendAlarm: %v", err)
+	}
+	if len(next.sends) != 2 || !slices.Equal(next.sends[1], []string{"c", "d"}) {
+		t.Fatalf("sends = %v, want the unsent tokens resent alone", next.sends)
+	}
+	if result.SuccessCount != 4 || result.FailureCount != 0 || len(result.FailureTokens) != 0 {
+		t.Fatalf("result = %+v, want every token sent once", result)
+	}
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type idempotencyRepository struct {
+	client *redis.Client
+}
+
+func NewIdempotencyRepository(client *redis.Client) repositories.IdempotencyRepository {
+	return &idempotencyRepository{client: client}
+}
+
+func (r *idempotencyRepository) Reserve(ctx context.Context, key, payloadHash string, ttl time.Duration) (*entities.IdempotencyRecord, error) {
+	if r.client == nil {
+		return nil, fmt.Errorf("redis client is not initialized")
+	}
+
+	pending, err := json.Marshal(entities.IdempotencyRecord{PayloadHash: payloadHash})
+	if err != nil {
+		return nil, err
+	}
+
+	redisKey := fmt.Sprintf("idempotency:%s", key)
+	ok, err := r.client.SetNX(ctx, redisKey, pending, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return nil, nil
+	}
+
+	val, err := r.client.Get(ctx, redisKey).Bytes()
+	if err == redis.Nil {
+		// The key expired between SETNX and GET; try to claim it again.
+		return r.Reserve(ctx, key, payloadHash, ttl)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var record entities.IdempotencyRecord
+	if err := json.Unmarshal(val, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode idempotency record: %v", err)
+	}
+	return &record, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, key string, record *entities.IdempotencyRecord, ttl time.Duration) error {
+	if r.client == nil {
+		return fmt.Errorf("redis client is not initialized")
+	}
+
+	val, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, fmt.Sprintf("idempotency:%s", key), val, ttl).Err()
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, key string) error {
+	if r.client == nil {
+		return fmt.Errorf("redis client is not initialized")
+	}
+	return r.client.Del(ctx, fmt.Sprintf("idempotency:%s", key)).Err()
+}
@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/config"
+	"pbmap_api/src/pkg/line"
+)
+
+// Ensure lineRepo implements repositories.PushSender.
+var _ repositories.PushSender = (*lineRepo)(nil)
+
+// lineLabels are the captions of the alert card, by language.
+var lineLabels = map[string]map[string]string{
+	"th": {"update": "อัปเดต", "cancel": "ยกเลิกแล้ว", "expired": "สิ้นสุดแล้ว", "drill": "การซ้อม", "map": "ดูแผนที่"},
+	"en": {"update": "Update", "cancel": "Cancelled", "expired": "Ended", "drill": "Drill", "map": "View map"},
+}
+
+// alarmColors are the header colors of alarm cards, by urgency.
+var alarmColors = map[string]string{
+	"immediate": "#D32F2F",
+	"high":      "#F57C00",
+	"normal":    "#1976D2",
+	"low":       "#388E3C",
+}
+
+// lineRepo sends alarms and notifications as LINE messages. The push tokens
+// it is given are LINE user IDs.
+type lineRepo struct {
+	client *line.Client
+}
+
+// NewLineRepo creates the LINE sender from the Messaging API channel in cfg.
+func NewLineRepo(cfg *config.Config) (repositories.PushSender, error) {
+	if cfg.LineChannelAccessToken == "" {
+		return &lineRepo{}, nil
+	}
+
+	client, err := line.NewClient(line.Config{
+		Endpoint:           cfg.LineAPIEndpoint,
+		ChannelAccessToken: cfg.LineChannelAccessToken,
+		RequestsPerSecond:  cfg.LineRateLimit,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &lineRepo{client: client}, nil
+}
+
+// NewLineRepoWithClient creates the LINE sender around an existing client,
+// e.g. one pointed at linetest.
+func NewLineRepoWithClient(client *line.Client) repositories.PushSender {
+	return &lineRepo{client: client}
+}
+
+func (s *lineRepo) SendNotification(ctx context.Context, msg *dto.NotificationMessage, tokens []string) (*dto.MulticastResponse, error) {
+	message := line.AlertMessage(msg.Title+"\n"+msg.Body, line.Alert{Title: msg.Title, Body: msg.Body})
+
+	result, err := s.multicast(ctx, message, tokens)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Sent LINE notification (%s): %d succeeded, %d failed\n", msg.Locale, result.SuccessCount, result.FailureCount)
+	return result, nil
+}
+
+func (s *lineRepo) SendAlarm(ctx context.Context, msg *dto.AlarmMessage, tokens []string) (*dto.MulticastResponse, error) {
+	labels := lineLabelsFor(msg.Locale)
+
+	var caption []string
+	if msg.Mode == "drill" {
+		caption = append(caption, labels["drill"])
+	}
+	if msg.Status != "new" {
+		caption = append(caption, labels[msg.Status])
+	}
+	color := alarmColors[msg.Urgency]
+	if msg.Status == "cancel" || msg.Status == "expired" {
+		color = "#616161"
+	}
+
+	message := line.AlertMessage(msg.Signal+"\n"+msg.Content, line.Alert{
+		Title:    msg.Signal,
+		Body:     msg.Content,
+		Label:    strings.Join(caption, " · "),
+		Color:    color,
+		MapURL:   line.MapURL(msg.Center.Lat, msg.Center.Lng),
+		MapLabel: labels["map"],
+	})
+
+	result, err := s.multicast(ctx, message, tokens)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Sent LINE alarm %s (%s, %s): %d succeeded, %d failed\n", msg.AlarmID, msg.Status, msg.Locale, result.SuccessCount, result.FailureCount)
+	return result, nil
+}
+
+// multicast sends message to every user in chunks of line.MaxRecipients. LINE
+// reports the outcome per request, so every user in a chunk shares it,
+// including its request ID. Once LINE refuses a chunk for the rate limit or
+// the monthly quota, the remaining chunks are failed without being sent.
+func (s *lineRepo) multicast(ctx context.Context, message line.Message, userIDs []string) (*dto.MulticastResponse, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("line client is not initialized")
+	}
+
+	result := &dto.MulticastResponse{
+		MessageIDs:    make([]string, 0),
+		SuccessTokens: make([]string, 0),
+		FailureTokens: make([]dto.TopicManagementError, 0),
+	}
+	for start := 0; start < len(userIDs); start += line.MaxRecipients {
+		chunk := userIDs[start:min(start+line.MaxRecipients, len(userIDs))]
+
+		resp, err := s.client.Multicast(ctx, chunk, message)
+		reason := ""
+		switch {
+		case err != nil:
+			reason = err.Error()
+		case resp.RateLimited():
+			rest := userIDs[start:]
+			result.FailureCount += len(rest)
+			for _, id := range rest {
+				result.FailureTokens = append(result.FailureTokens, dto.TopicManagementError{Token: id, Reason: resp.Message})
+			}
+			return result, nil
+		case !resp.Sent():
+			reason = resp.Message
+		}
+		if reason == "" {
+			result.SuccessCount += len(chunk)
+			result.SuccessTokens = append(result.SuccessTokens, chunk...)
+			for range chunk {
+				result.MessageIDs = append(result.MessageIDs, resp.RequestID)
+			}
+			continue
+		}
+		result.FailureCount += len(chunk)
+		for _, id := range chunk {
+			result.FailureTokens = append(result.FailureTokens, dto.TopicManagementError{Token: id, Reason: reason})
+		}
+	}
+	return result, nil
+}
+
+// lineLabelsFor returns the card captions in the locale's language, falling
+// back to English.
+func lineLabelsFor(locale string) map[string]string {
+	language, _, _ := strings.Cut(locale, "-")
+	if labels, ok := lineLabels[language]; ok {
+		return labels
+	}
+	return lineLabels["en"]
+}
@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/line"
+	"pbmap_api/src/pkg/line/linetest"
+)
+
+const lineTestToken = "channel-token"
+
+// countingHandler counts the requests reaching the fake, including the ones
+// it rejects and does not record.
+type countingHandler struct {
+	http.Handler
+	calls atomic.Int32
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls.Add(1)
+	h.Handler.ServeHTTP(w, r)
+}
+
+func newTestLineRepo(t *testing.T, transport func(http.RoundTripper) http.RoundTripper) (repositories.PushSender, *linetest.Server, *countingHandler) {
+	t.Helper()
+	fake := linetest.NewServer(lineTestToken)
+	counter := &countingHandler{Handler: fake}
+	srv := httptest.NewServer(counter)
+	t.Cleanup(srv.Close)
+
+	httpClient := srv.Client()
+	if transport != nil {
+		httpClient.Transport = transport(httpClient.Transport)
+	}
+	client, err := line.NewClient(line.Config{
+		Endpoint:           srv.URL,
+		ChannelAccessToken: lineTestToken,
+		RequestsPerSecond:  1000,
+	}, httpClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewLineRepoWithClient(client), fake, counter
+}
+
+func lineUserIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("U%032d", i)
+	}
+	return ids
+}
+
+func TestLineMulticastChunks(t *testing.T) {
+	sender, fake, _ := newTestLineRepo(t, nil)
+	ids := lineUserIDs(2*line.MaxRecipients + 1)
+
+	result, err := sender.SendNotification(context.Background(), &dto.NotificationMessage{Title: "Title", Body: "Body", Locale: "en"}, ids)
+	if err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	if result.SuccessCount != len(ids) || result.FailureCount != 0 || len(result.MessageIDs) != len(ids) {
+		t.Fatalf("result: %d succeeded, %d failed, %d message IDs; want all %d sent",
+			result.SuccessCount, result.FailureCount, len(result.MessageIDs), len(ids))
+	}
+
+	requests := fake.Requests()
+	sizes := make([]int, len(requests))
+	for i, r := range requests {
+		sizes[i] = len(r.To)
+	}
+	if fmt.Sprint(sizes) != fmt.Sprint([]int{line.MaxRecipients, line.MaxRecipients, 1}) {
+		t.Fatalf("chunk sizes = %v, want [500 500 1]", sizes)
+	}
+	if requests[2].To[0] != ids[len(ids)-1] {
+		t.Fatalf("last chunk = %v, want the last user", requests[2].To)
+	}
+}
+
+func TestLineMulticastQuotaExhausted(t *testing.T) {
+	sender, fake, counter := newTestLineRepo(t, nil)
+	fake.SetQuotaExhausted(true)
+	ids := lineUserIDs(2*line.MaxRecipients + 1)
+
+	result, err := sender.SendAlarm(context.Background(), &dto.AlarmMessage{
+		AlarmID: "alarm-1",
+		Status:  "new",
+		Urgency: "immediate",
+		Signal:  "Evacuate",
+		Content: "Leave now",
+		Locale:  "en",
+	}, ids)
+	if err != nil {
+		t.Fatalf("SendAlarm: %v", err)
+	}
+	if calls := counter.calls.Load(); calls != 1 {
+		t.Fatalf("LINE was called %d times, want the remaining chunks skipped after the 429", calls)
+	}
+	if result.SuccessCount != 0 || result.FailureCount != len(ids) || len(result.FailureTokens) != len(ids) {
+		t.Fatalf("result: %d succeeded, %d failed; want all %d failed", result.SuccessCount, result.FailureCount, len(ids))
+	}
+	for _, f := range result.FailureTokens {
+		if f.Code != "" || f.Reason == "" {
+			t.Fatalf("failure = %+v, want a reason and no dead-token code", f)
+		}
+	}
+}
+
+// loseFirstResponse delivers the first request but reports it as failed, as
+// when the connection drops before the response arrives.
+type loseFirstResponse struct {
+	base http.RoundTripper
+	lost atomic.Bool
+}
+
+func (t *loseFirstResponse) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(r)
+	if err == nil && t.lost.CompareAndSwap(false, true) {
+		resp.Body.Close()
+		return nil, errors.New("connection reset by peer")
+	}
+	return resp, err
+}
+
+func TestLineMulticastRetryAfterLostResponse(t *testing.T) {
+	sender, fake, counter := newTestLineRepo(t, func(base http.RoundTripper) http.RoundTripper {
+		return &loseFirstResponse{base: base}
+	})
+	ids := lineUserIDs(3)
+
+	result, err := sender.SendNotification(context.Background(), &dto.NotificationMessage{Title: "Title", Body: "Body", Locale: "en"}, ids)
+	if err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	if calls := counter.calls.Load(); calls != 2 {
+		t.Fatalf("LINE was called %d times, want the request retried once", calls)
+	}
+	if n := len(fake.Requests()); n != 1 {
+		t.Fatalf("LINE accepted %d requests, want the retry deduplicated by its retry key", n)
+	}
+	if result.SuccessCount != len(ids) || result.FailureCount != 0 {
+		t.Fatalf("result: %d succeeded, %d failed; want the retry's 409 counted as sent", result.SuccessCount, result.FailureCount)
+	}
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// preferenceLookupBatchSize keeps lookups for large broadcasts under
+// Postgres' bind parameter limit.
+const preferenceLookupBatchSize = 1000
+
+type notificationPreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationPreferenceRepository(db *gorm.DB) repositories.NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{db: db}
+}
+
+func (r *notificationPreferenceRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*entities.NotificationPreference, error) {
+	var preference entities.NotificationPreference
+	err := GetDB(ctx, r.db).First(&preference, "user_id = ?", userID).Error
+	return &preference, err
+}
+
+func (r *notificationPreferenceRepository) FindByUserIDs(ctx context.Context, userIDs []uuid.UUID) ([]entities.NotificationPreference, error) {
+	var preferences []entities.NotificationPreference
+	for start := 0; start < len(userIDs); start += preferenceLookupBatchSize {
+		var batch []entities.NotificationPreference
+		chunk := userIDs[start:min(start+preferenceLookupBatchSize, len(userIDs))]
+		if err := GetDB(ctx, r.db).Where("user_id IN ?", chunk).Find(&batch).Error; err != nil {
+			return nil, err
+		}
+		preferences = append(preferences, batch...)
+	}
+	return preferences, nil
+}
+
+// Upsert stores every field, including false opt-ins that would otherwise be
+// replaced by their column defaults.
+func (r *notificationPreferenceRepository) Upsert(ctx context.Context, preference *entities.NotificationPreference) error {
+	return GetDB(ctx, r.db).Select("*").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"push_enabled", "line_enabled", "sms_enabled", "muted_topic_ids", "muted_hazards",
+			"quiet_start", "quiet_end", "time_zone", "updated_at",
+		}),
+	}).Create(preference).Error
+}
+
+func (r *notificationPreferenceRepository) UnmuteTopic(ctx context.Context, userID, topicID uuid.UUID) error {
+	return GetDB(ctx, r.db).Model(&entities.NotificationPreference{}).
+		Where("user_id = ?", userID).
+		Update("muted_topic_ids", gorm.Expr("muted_topic_ids - ?", topicID.String())).Error
+}
+
+func (r *notificationPreferenceRepository) FindLineRecipients(ctx context.Context, userIDs []uuid.UUID) ([]dto.LineRecipient, error) {
+	var recipients []dto.LineRecipient
+	if len(userIDs) == 0 {
+		return recipients, nil
+	}
+	err := r.lineRecipients(ctx).Where("users.id IN ?", userIDs).Scan(&recipients).Error
+	return recipients, err
+}
+
+func (r *notificationPreferenceRepository) lineRecipients(ctx context.Context) *gorm.DB {
+	return GetDB(ctx, r.db).Table("users").
+		Select("users.id AS user_id, user_social_accounts.provider_id AS line_user_id").
+		Joins("JOIN user_social_accounts ON user_social_accounts.user_id = users.id AND user_social_accounts.provider = ?", "line").
+		Joins("JOIN notification_preferences ON notification_preferences.user_id = users.id").
+		Where("notification_preferences.line_enabled AND users.deleted_at IS NULL")
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) repositories.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Create(ctx context.Context, message *entities.OutboxMessage) error {
+	return GetDB(ctx, r.db).Create(message).Error
+}
+
+func (r *outboxRepository) Update(ctx context.Context, message *entities.OutboxMessage) error {
+	return GetDB(ctx, r.db).Save(message).Error
+}
+
+func (r *outboxRepository) FindByID(ctx context.Context, id uuid.UUID) (*entities.OutboxMessage, error) {
+	var message entities.OutboxMessage
+	if err := GetDB(ctx, r.db).First(&message, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// Claim takes the due message that has waited longest, alarms before
+// anything else. SKIP LOCKED lets several API instances drain the outbox at
+// once without handing out a message twice.
+func (r *outboxRepository) Claim(ctx context.Context, lease time.Duration) (*entities.OutboxMessage, error) {
+	now := time.Now()
+	var messages []entities.OutboxMessage
+	err := GetDB(ctx, r.db).Raw(`
+		UPDATE outbox_messages SET locked_until = ?, attempts = attempts + 1, updated_at = ?
+		WHERE id = (
+			SELECT id FROM outbox_messages
+			WHERE status = 'pending' AND next_attempt_at <= ? AND (locked_until IS NULL OR locked_until < ?)
+			ORDER BY kind = 'alarm' DESC, next_attempt_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(lease), now, now, now,
+	).Scan(&messages).Error
+	if err != nil || len(messages) == 0 {
+		return nil, err
+	}
+	return &messages[0], nil
+}
+
+func (r *outboxRepository) ExtendLease(ctx context.Context, id uuid.UUID, lease time.Duration) error {
+	return GetDB(ctx, r.db).Model(&entities.OutboxMessage{}).
+		Where("id = ? AND status = ?", id, "pending").
+		Update("locked_until", time.Now().Add(lease)).Error
+}
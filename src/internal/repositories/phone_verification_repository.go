@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// incrementAttemptsScript counts an attempt on a pending verification without
+// creating one, which would outlive the verification's TTL.
+var incrementAttemptsScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+return redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+`)
+
+// countScript increments a counter that expires ARGV[1] ms after its first
+// increment.
+var countScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return n
+`)
+
+type phoneVerificationRepository struct {
+	client *redis.Client
+}
+
+func NewPhoneVerificationRepository(client *redis.Client) repositories.PhoneVerificationRepository {
+	return &phoneVerificationRepository{client: client}
+}
+
+func phoneVerificationKey(userID uuid.UUID) string {
+	return fmt.Sprintf("user:%s:phone_verification", userID)
+}
+
+func (r *phoneVerificationRepository) Save(ctx context.Context, userID uuid.UUID, verification *entities.PhoneVerification, ttl time.Duration) error {
+	if r.client == nil {
+		return fmt.Errorf("redis client is not initialized")
+	}
+
+	key := phoneVerificationKey(userID)
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key,
+			"phone_number", verification.PhoneNumber,
+			"code_hash", verification.CodeHash,
+			"attempts", verification.Attempts,
+			"sent_at", verification.SentAt.Unix(),
+		)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+func (r *phoneVerificationRepository) Find(ctx context.Context, userID uuid.UUID) (*entities.PhoneVerification, error) {
+	if r.client == nil {
+		return nil, fmt.Errorf("redis client is not initialized")
+	}
+
+	fields, err := r.client.HGetAll(ctx, phoneVerificationKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	attempts, _ := strconv.Atoi(fields["attempts"])
+	sentAt, _ := strconv.ParseInt(fields["sent_at"], 10, 64)
+	return &entities.PhoneVerification{
+		PhoneNumber: fields["phone_number"],
+		CodeHash:    fields["code_hash"],
+		Attempts:    attempts,
+		SentAt:      time.Unix(sentAt, 0),
+	}, nil
+}
+
+func (r *phoneVerificationRepository) IncrementAttempts(ctx context.Context, userID uuid.UUID) (int, error) {
+	if r.client == nil {
+		return 0, fmt.Errorf("redis client is not initialized")
+	}
+
+	return incrementAttemptsScript.Run(ctx, r.client, []string{phoneVerificationKey(userID)}).Int()
+}
+
+func (r *phoneVerificationRepository) CountSend(ctx context.Context, key string, window time.Duration) (int, error) {
+	if r.client == nil {
+		return 0, fmt.Errorf("redis client is not initialized")
+	}
+	return countScript.Run(ctx, r.client, []string{"phone_verification_sends:" + key}, window.Milliseconds()).Int()
+}
+
+func (r *phoneVerificationRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	if r.client == nil {
+		return fmt.Errorf("redis client is not initialized")
+	}
+	return r.client.Del(ctx, phoneVerificationKey(userID)).Err()
+}
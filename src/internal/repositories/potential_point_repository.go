@@ -4,6 +4,7 @@ import (
 	"context"
 	"pbmap_api/src/internal/domain/entities"
 	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/pkg/geo"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -44,3 +45,23 @@ func (r *potentialPointRepository) FindAll(ctx context.Context) ([]entities.Pote
 	}
 	return pps, nil
 }
+
+func (r *potentialPointRepository) FindWithinBounds(ctx context.Context, bounds geo.Bounds) ([]entities.PotentialPoint, error) {
+	var pps []entities.PotentialPoint
+	err := r.db.WithContext(ctx).
+		Where("deleted_at IS NULL").
+		Where("latitude BETWEEN ? AND ?", bounds.MinLat, bounds.MaxLat).
+		Where("longitude BETWEEN ? AND ?", bounds.MinLng, bounds.MaxLng).
+		Find(&pps).Error
+	return pps, err
+}
+
+func (r *potentialPointRepository) FindCreatorIDsByType(ctx context.Context, ppType string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&entities.PotentialPoint{}).
+		Where("deleted_at IS NULL").
+		Where("type = ?", ppType).
+		Distinct().
+		Pluck("created_by", &ids).Error
+	return ids, err
+}
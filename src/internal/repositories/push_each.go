@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"sync"
+
+	"pbmap_api/src/internal/dto"
+)
+
+// pushOutcome is the result of pushing to a single token.
+type pushOutcome struct {
+	Sent      bool
+	MessageID string
+	Reason    string
+	Code      string // dead-token code, if the provider reported one
+}
+
+// pushEach calls push for every token, at most concurrency at a time, and
+// collects the per-token results in the same shape as an FCM multicast. It is
+// used by providers that take one device per request.
+func pushEach(tokens []string, concurrency int, push func(token string) pushOutcome) *dto.MulticastResponse {
+	outcomes := make([]pushOutcome, len(tokens))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, token := range tokens {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, token string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			outcomes[i] = push(token)
+		}(i, token)
+	}
+	wg.Wait()
+
+	result := &dto.MulticastResponse{
+		MessageIDs:    make([]string, 0),
+		SuccessTokens: make([]string, 0),
+		FailureTokens: make([]dto.TopicManagementError, 0),
+	}
+	for i, o := range outcomes {
+		if o.Sent {
+			result.SuccessCount++
+			result.MessageIDs = append(result.MessageIDs, o.MessageID)
+			result.SuccessTokens = append(result.SuccessTokens, tokens[i])
+			continue
+		}
+		result.FailureCount++
+		result.FailureTokens = append(result.FailureTokens, dto.TopicManagementError{
+			Token:  tokens[i],
+			Reason: o.Reason,
+			Code:   o.Code,
+		})
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/pkg/config"
+)
+
+// Ensure smsGatewayRepo implements repositories.SMSSender.
+var _ repositories.SMSSender = (*smsGatewayRepo)(nil)
+
+// NewSMSSender creates the SMS sender selected by cfg.SMSProvider: "http" for
+// the gateway, "memory" for the in-memory fake, or none when it is empty.
+func NewSMSSender(cfg *config.Config) (repositories.SMSSender, error) {
+	switch cfg.SMSProvider {
+	case "":
+		return nil, nil
+	case "http":
+		return NewSMSGatewayRepo(cfg, nil)
+	case "memory":
+		return NewMemorySMSSender(), nil
+	}
+	return nil, fmt.Errorf("unknown sms provider %q", cfg.SMSProvider)
+}
+
+// smsGatewayRepo sends SMS through an HTTP gateway that accepts
+//
+//	POST {url} {"to": "+66...", "text": "...", "sender": "..."}
+//
+// with a bearer API key and answers 2xx with {"message_id": "..."}.
+type smsGatewayRepo struct {
+	url    string
+	apiKey string
+	sender string
+	http   *http.Client
+}
+
+// NewSMSGatewayRepo creates the SMS sender for the gateway in cfg. httpClient
+// may be nil, in which case a client with a 15 second timeout is used.
+func NewSMSGatewayRepo(cfg *config.Config, httpClient *http.Client) (repositories.SMSSender, error) {
+	if cfg.SMSGatewayURL == "" {
+		return nil, fmt.Errorf("sms gateway url is not configured")
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &smsGatewayRepo{
+		url:    cfg.SMSGatewayURL,
+		apiKey: cfg.SMSGatewayAPIKey,
+		sender: cfg.SMSSenderName,
+		http:   httpClient,
+	}, nil
+}
+
+func (s *smsGatewayRepo) Send(ctx context.Context, to, text string) (string, error) {
+	body, err := json.Marshal(map[string]string{"to": to, "text": text, "sender": s.sender})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode sms: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("content-type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("sms gateway request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("sms gateway rejected message: status=%d, body=%s", resp.StatusCode, string(data))
+	}
+
+	var result struct {
+		MessageID string `json:"message_id"`
+	}
+	_ = json.Unmarshal(data, &result)
+	return result.MessageID, nil
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"pbmap_api/src/internal/domain/repositories"
+
+	"github.com/google/uuid"
+)
+
+// Ensure MemorySMSSender implements repositories.SMSSender.
+var _ repositories.SMSSender = (*MemorySMSSender)(nil)
+
+// SentSMS is a message kept by MemorySMSSender.
+type SentSMS struct {
+	ID     string    `json:"id"`
+	To     string    `json:"to"`
+	Text   string    `json:"text"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+// MemorySMSSender keeps messages in memory instead of sending them, for tests
+// and local development.
+type MemorySMSSender struct {
+	mu       sync.Mutex
+	messages []SentSMS
+	failing  map[string]bool
+}
+
+func NewMemorySMSSender() *MemorySMSSender {
+	return &MemorySMSSender{failing: make(map[string]bool)}
+}
+
+func (s *MemorySMSSender) Send(ctx context.Context, to, text string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failing[to] {
+		return "", fmt.Errorf("sms to %s failed", to)
+	}
+	msg := SentSMS{ID: uuid.NewString(), To: to, Text: text, SentAt: time.Now()}
+	s.messages = append(s.messages, msg)
+	// The text is left out of the log since it may carry a verification code.
+	fmt.Printf("SMS to %s kept in memory (%d characters)\n", to, len([]rune(text)))
+	return msg.ID, nil
+}
+
+// Fail makes sends to the number return an error.
+func (s *MemorySMSSender) Fail(to string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing[to] = true
+}
+
+// Messages returns the messages sent so far.
+func (s *MemorySMSSender) Messages() []SentSMS {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SentSMS(nil), s.messages...)
+}
+
+// Reset forgets sent messages and failing numbers.
+func (s *MemorySMSSender) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = nil
+	s.failing = make(map[string]bool)
+}
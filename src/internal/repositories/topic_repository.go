@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type topicRepository struct {
+	db *gorm.DB
+}
+
+func NewTopicRepository(db *gorm.DB) repositories.TopicRepository {
+	return &topicRepository{db: db}
+}
+
+func (r *topicRepository) Create(ctx context.Context, topic *entities.Topic) error {
+	return GetDB(ctx, r.db).Create(topic).Error
+}
+
+func (r *topicRepository) FindByID(ctx context.Context, id uuid.UUID) (*entities.Topic, error) {
+	var topic entities.Topic
+	if err := GetDB(ctx, r.db).First(&topic, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &topic, nil
+}
+
+func (r *topicRepository) Update(ctx context.Context, topic *entities.Topic) error {
+	return GetDB(ctx, r.db).Save(topic).Error
+}
+
+func (r *topicRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := GetDB(ctx, r.db).Delete(&entities.Topic{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *topicRepository) FindAll(ctx context.Context, category string) ([]entities.Topic, error) {
+	var topics []entities.Topic
+	query := GetDB(ctx, r.db).Order("category, name")
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	err := query.Find(&topics).Error
+	return topics, err
+}
+
+type topicSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewTopicSubscriptionRepository(db *gorm.DB) repositories.TopicSubscriptionRepository {
+	return &topicSubscriptionRepository{db: db}
+}
+
+// Create records the subscriptions, ignoring devices already subscribed.
+func (r *topicSubscriptionRepository) Create(ctx context.Context, subscriptions []entities.TopicSubscription) error {
+	if len(subscriptions) == 0 {
+		return nil
+	}
+	return GetDB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&subscriptions).Error
+}
+
+func (r *topicSubscriptionRepository) Delete(ctx context.Context, topicID uuid.UUID, deviceIDs []uuid.UUID) error {
+	if len(deviceIDs) == 0 {
+		return nil
+	}
+	return GetDB(ctx, r.db).
+		Where("topic_id = ? AND device_id IN ?", topicID, deviceIDs).
+		Delete(&entities.TopicSubscription{}).Error
+}
+
+func (r *topicSubscriptionRepository) DeleteByDevices(ctx context.Context, deviceIDs []uuid.UUID) error {
+	if len(deviceIDs) == 0 {
+		return nil
+	}
+	return GetDB(ctx, r.db).Where("device_id IN ?", deviceIDs).Delete(&entities.TopicSubscription{}).Error
+}
+
+func (r *topicSubscriptionRepository) FindByTopic(ctx context.Context, topicID uuid.UUID) ([]entities.TopicSubscription, error) {
+	var subscriptions []entities.TopicSubscription
+	err := GetDB(ctx, r.db).Preload("Device").Where("topic_id = ?", topicID).Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *topicSubscriptionRepository) FindByDevice(ctx context.Context, deviceID uuid.UUID) ([]entities.TopicSubscription, error) {
+	var subscriptions []entities.TopicSubscription
+	err := GetDB(ctx, r.db).Preload("Topic").Where("device_id = ?", deviceID).Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *topicSubscriptionRepository) FindByUser(ctx context.Context, userID uuid.UUID) ([]entities.TopicSubscription, error) {
+	var subscriptions []entities.TopicSubscription
+	err := GetDB(ctx, r.db).Preload("Topic").Where("user_id = ?", userID).Order("created_at").Find(&subscriptions).Error
+	return subscriptions, err
+}
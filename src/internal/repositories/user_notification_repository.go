@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// userNotificationBatchSize keeps inserts for large broadcasts under
+// Postgres' bind parameter limit.
+const userNotificationBatchSize = 1000
+
+type userNotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewUserNotificationRepository(db *gorm.DB) repositories.UserNotificationRepository {
+	return &userNotificationRepository{db: db}
+}
+
+func (r *userNotificationRepository) CreateBatch(ctx context.Context, notifications []entities.UserNotification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return GetDB(ctx, r.db).CreateInBatches(notifications, userNotificationBatchSize).Error
+}
+
+func (r *userNotificationRepository) FindByUser(ctx context.Context, userID uuid.UUID, filter dto.InboxFilter) ([]entities.UserNotification, error) {
+	var notifications []entities.UserNotification
+	query := GetDB(ctx, r.db).Where("user_id = ?", userID)
+	if filter.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if filter.Before != nil {
+		query = query.Where("(created_at, id) < (?, ?)", *filter.Before, filter.BeforeID)
+	}
+	err := query.Order("created_at DESC, id DESC").Limit(filter.Limit).Find(&notifications).Error
+	return notifications, err
+}
+
+// MarkRead keeps the time a notification was first read.
+func (r *userNotificationRepository) MarkRead(ctx context.Context, userID, id uuid.UUID) error {
+	result := GetDB(ctx, r.db).Model(&entities.UserNotification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", time.Now()))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *userNotificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result := GetDB(ctx, r.db).Model(&entities.UserNotification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+func (r *userNotificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := GetDB(ctx, r.db).Model(&entities.UserNotification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"pbmap_api/src/internal/domain/entities"
 	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/pkg/geo"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -45,6 +47,15 @@ func (r *userRepository) FindAll(ctx context.Context) ([]entities.User, error) {
 	return users, err
 }
 
+func (r *userRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]entities.User, error) {
+	var users []entities.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := GetDB(ctx, r.db).Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
 func (r *userRepository) FindBySocialID(ctx context.Context, provider, providerID string) (*entities.User, error) {
 	var user entities.User
 	err := GetDB(ctx, r.db).
@@ -57,3 +68,61 @@ func (r *userRepository) FindBySocialID(ctx context.Context, provider, providerI
 		First(&user).Error
 	return &user, err
 }
+
+func (r *userRepository) FindIDsByRoles(ctx context.Context, roles []string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if len(roles) == 0 {
+		return ids, nil
+	}
+	err := GetDB(ctx, r.db).Model(&entities.User{}).Where("role IN ?", roles).Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *userRepository) FindIDsAfter(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := GetDB(ctx, r.db).Model(&entities.User{}).
+		Where("deleted_at IS NULL AND id > ?", after).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *userRepository) UpdatePhone(ctx context.Context, id uuid.UUID, phone *string, verifiedAt *time.Time) error {
+	return GetDB(ctx, r.db).Model(&entities.User{}).Where("id = ?", id).Updates(map[string]any{
+		"phone_number":      phone,
+		"phone_verified_at": verifiedAt,
+	}).Error
+}
+
+func (r *userRepository) UpdateHomeLocation(ctx context.Context, id uuid.UUID, lat, lng *float64) error {
+	return GetDB(ctx, r.db).Model(&entities.User{}).Where("id = ?", id).Updates(map[string]any{
+		"home_latitude":  lat,
+		"home_longitude": lng,
+	}).Error
+}
+
+func (r *userRepository) FindSMSRecipientsWithinBounds(ctx context.Context, bounds geo.Bounds) ([]entities.User, error) {
+	var users []entities.User
+	err := r.smsRecipients(ctx).
+		Where("home_latitude BETWEEN ? AND ?", bounds.MinLat, bounds.MaxLat).
+		Where("home_longitude BETWEEN ? AND ?", bounds.MinLng, bounds.MaxLng).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) FindSMSDrillRecipients(ctx context.Context) ([]entities.User, error) {
+	var users []entities.User
+	err := r.smsRecipients(ctx).
+		Where("id IN (?)", GetDB(ctx, r.db).Model(&entities.DrillEnrollment{}).Select("user_id").Where("user_id IS NOT NULL")).
+		Find(&users).Error
+	return users, err
+}
+
+// smsRecipients selects users with a verified phone and no device with a live push token.
+func (r *userRepository) smsRecipients(ctx context.Context) *gorm.DB {
+	return GetDB(ctx, r.db).
+		Where("phone_number IS NOT NULL AND phone_verified_at IS NOT NULL AND deleted_at IS NULL").
+		Where("NOT EXISTS (?)", GetDB(ctx, r.db).Model(&entities.UserDevice{}).Select("1").
+			Where("user_devices.user_id = users.id AND push_token <> '' AND invalidated_at IS NULL"))
+}
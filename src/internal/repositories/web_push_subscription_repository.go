@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type webPushSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewWebPushSubscriptionRepository(db *gorm.DB) repositories.WebPushSubscriptionRepository {
+	return &webPushSubscriptionRepository{db: db}
+}
+
+// Upsert stores the subscription, replacing the one already held for the device.
+func (r *webPushSubscriptionRepository) Upsert(ctx context.Context, subscription *entities.WebPushSubscription) error {
+	return GetDB(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "device_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"endpoint", "p256dh", "auth", "updated_at"}),
+	}).Create(subscription).Error
+}
+
+func (r *webPushSubscriptionRepository) DeleteByDevice(ctx context.Context, deviceID uuid.UUID) error {
+	return GetDB(ctx, r.db).Where("device_id = ?", deviceID).Delete(&entities.WebPushSubscription{}).Error
+}
+
+func (r *webPushSubscriptionRepository) FindByEndpoints(ctx context.Context, endpoints []string) ([]entities.WebPushSubscription, error) {
+	var subscriptions []entities.WebPushSubscription
+	if len(endpoints) == 0 {
+		return subscriptions, nil
+	}
+	err := GetDB(ctx, r.db).Where("endpoint IN ?", endpoints).Find(&subscriptions).Error
+	return subscriptions, err
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/config"
+	"pbmap_api/src/pkg/webpush"
+)
+
+// Ensure webPushRepo implements repositories.PushSender.
+var _ repositories.PushSender = (*webPushRepo)(nil)
+
+const (
+	webPushConcurrency = 16
+	// webPushDefaultTTL is how long push services keep a message for an
+	// offline browser when nothing shorter applies.
+	webPushDefaultTTL = 24 * time.Hour
+)
+
+// webPushRepo sends to browsers through their Web Push subscriptions. The
+// push tokens it is given are subscription endpoints.
+type webPushRepo struct {
+	client        *webpush.Client
+	subscriptions repositories.WebPushSubscriptionRepository
+}
+
+// NewWebPushRepo creates the Web Push sender signing with the VAPID key in cfg.
+func NewWebPushRepo(cfg *config.Config, subscriptions repositories.WebPushSubscriptionRepository) (repositories.PushSender, error) {
+	if cfg.VAPIDPrivateKey == "" {
+		return &webPushRepo{subscriptions: subscriptions}, nil
+	}
+
+	vapid, err := webpush.NewVAPID(cfg.VAPIDPrivateKey, cfg.VAPIDSubject)
+	if err != nil {
+		return nil, err
+	}
+	return &webPushRepo{
+		client:        webpush.NewClient(vapid, nil),
+		subscriptions: subscriptions,
+	}, nil
+}
+
+func (s *webPushRepo) SendNotification(ctx context.Context, msg *dto.NotificationMessage, tokens []string) (*dto.MulticastResponse, error) {
+	payload, err := json.Marshal(map[string]string{
+		"type":   "notification",
+		"title":  msg.Title,
+		"body":   msg.Body,
+		"locale": msg.Locale,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notification: %v", err)
+	}
+
+	result, err := s.sendAll(ctx, payload, webpush.Options{TTL: webPushDefaultTTL, Urgency: webpush.UrgencyNormal}, tokens)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Sent web push notification (%s): %d succeeded, %d failed\n", msg.Locale, result.SuccessCount, result.FailureCount)
+	return result, nil
+}
+
+func (s *webPushRepo) SendAlarm(ctx context.Context, msg *dto.AlarmMessage, tokens []string) (*dto.MulticastResponse, error) {
+	payload := map[string]any{
+		"type":     "alarm",
+		"title":    msg.Signal,
+		"body":     msg.Content,
+		"alarm_id": msg.AlarmID,
+		"status":   msg.Status,
+		"urgency":  msg.Urgency,
+		"mode":     msg.Mode,
+		"center":   msg.Center,
+		"locale":   msg.Locale,
+	}
+	if msg.HasAreas {
+		payload["has_areas"] = true
+	}
+	if msg.ExpiresAt != nil {
+		payload["expires_at"] = msg.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode alarm: %v", err)
+	}
+
+	opts := webpush.Options{TTL: webPushDefaultTTL, Urgency: webpush.UrgencyNormal}
+	if msg.Urgency == "immediate" || msg.Urgency == "high" {
+		opts.Urgency = webpush.UrgencyHigh
+	}
+	if msg.ExpiresAt != nil {
+		opts.TTL = max(time.Until(*msg.ExpiresAt), 0)
+	}
+
+	result, err := s.sendAll(ctx, body, opts, tokens)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Sent web push alarm %s (%s, %s): %d succeeded, %d failed\n", msg.AlarmID, msg.Status, msg.Locale, result.SuccessCount, result.FailureCount)
+	return result, nil
+}
+
+// sendAll encrypts payload for the subscription behind each endpoint and
+// sends it. A payload too large for Web Push fails for every endpoint, so
+// that the other providers still send the message.
+func (s *webPushRepo) sendAll(ctx context.Context, payload []byte, opts webpush.Options, endpoints []string) (*dto.MulticastResponse, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("web push client is not initialized")
+	}
+	if len(payload) > webpush.MaxPayloadSize {
+		return pushEach(endpoints, webPushConcurrency, func(endpoint string) pushOutcome {
+			return pushOutcome{Reason: webpush.ErrPayloadTooLarge.Error(), Code: dto.TokenErrorInvalidArgument}
+		}), nil
+	}
+
+	stored, err := s.subscriptions.FindByEndpoints(ctx, endpoints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load web push subscriptions: %v", err)
+	}
+	byEndpoint := make(map[string]*webpush.Subscription, len(stored))
+	for _, sub := range stored {
+		parsed, err := webpush.ParseSubscription(sub.Endpoint, sub.P256dh, sub.Auth)
+		if err != nil {
+			continue
+		}
+		byEndpoint[sub.Endpoint] = parsed
+	}
+
+	return pushEach(endpoints, webPushConcurrency, func(endpoint string) pushOutcome {
+		sub, ok := byEndpoint[endpoint]
+		if !ok {
+			return pushOutcome{Reason: "no valid web push subscription", Code: dto.TokenErrorInvalidArgument}
+		}
+		resp, err := s.client.Send(ctx, sub, payload, opts)
+		if err != nil {
+			return pushOutcome{Reason: err.Error()}
+		}
+		switch {
+		case resp.Sent():
+			return pushOutcome{Sent: true, MessageID: resp.Location}
+		case resp.Gone():
+			return pushOutcome{Reason: resp.Reason, Code: dto.TokenErrorUnregistered}
+		}
+		return pushOutcome{Reason: resp.Reason}
+	}), nil
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/config"
+	"pbmap_api/src/pkg/webpush"
+)
+
+func TestWebPushPayloadTooLarge(t *testing.T) {
+	privateKey, _, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, err := NewWebPushRepo(&config.Config{VAPIDPrivateKey: privateKey, VAPIDSubject: "mailto:test@example.com"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoints := []string{"https://fcm.googleapis.com/fcm/send/a", "https://fcm.googleapis.com/fcm/send/b"}
+	result, err := sender.SendNotification(context.Background(), &dto.NotificationMessage{
+		Title: "title",
+		Body:  strings.Repeat("x", webpush.MaxPayloadSize),
+	}, endpoints)
+	if err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	if result.SuccessCount != 0 || result.FailureCount != len(endpoints) {
+		t.Fatalf("result = %+v, want every endpoint failed", result)
+	}
+	for _, f := range result.FailureTokens {
+		if f.Code != dto.TokenErrorInvalidArgument {
+			t.Errorf("failure %+v, want code %s", f, dto.TokenErrorInvalidArgument)
+		}
+	}
+}
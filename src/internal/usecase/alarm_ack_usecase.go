@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrAlarmAckNotTracked = errors.New("acknowledgements are only tracked for immediate and high urgency alarms")
+
+// AlarmAckUsecase records citizen acknowledgements of alarms and summarises them for officers.
+type AlarmAckUsecase interface {
+	Acknowledge(ctx context.Context, alarmID string, userID uuid.UUID, req *dto.AlarmAckRequest) (*entities.AlarmAck, error)
+	Summary(ctx context.Context, alarmID string) (*dto.AlarmAckSummary, error)
+}
+
+type alarmAckUsecase struct {
+	alarmRepo  repositories.AlarmRepository
+	ackRepo    repositories.AlarmAckRepository
+	deviceRepo repositories.DeviceRepository
+	userRepo   repositories.UserRepository
+}
+
+// NewAlarmAckUsecase creates the alarm acknowledgement usecase.
+func NewAlarmAckUsecase(alarmRepo repositories.AlarmRepository, ackRepo repositories.AlarmAckRepository, deviceRepo repositories.DeviceRepository, userRepo repositories.UserRepository) AlarmAckUsecase {
+	return &alarmAckUsecase{alarmRepo: alarmRepo, ackRepo: ackRepo, deviceRepo: deviceRepo, userRepo: userRepo}
+}
+
+// Acknowledge records that the user received or acknowledged the alarm.
+// Repeated calls update the same record, so receipt and acknowledgement can
+// arrive separately.
+func (u *alarmAckUsecase) Acknowledge(ctx context.Context, alarmID string, userID uuid.UUID, req *dto.AlarmAckRequest) (*entities.AlarmAck, error) {
+	alarm, err := u.findTracked(ctx, alarmID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DeviceID != nil {
+		device, err := u.deviceRepo.FindByID(ctx, *req.DeviceID)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && device.UserID != userID) {
+			return nil, ErrDeviceNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ack, err := u.ackRepo.FindByAlarmAndUser(ctx, alarm.ID, userID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		ack = &entities.AlarmAck{AlarmID: alarm.ID, UserID: userID}
+	}
+
+	now := time.Now()
+	if req.DeviceID != nil {
+		ack.DeviceID = req.DeviceID
+	}
+	if ack.ReceivedAt == nil {
+		ack.ReceivedAt = &now
+	}
+	if req.Event == "acknowledged" && ack.AcknowledgedAt == nil {
+		ack.AcknowledgedAt = &now
+	}
+	if req.SafetyStatus != "" {
+		ack.SafetyStatus = req.SafetyStatus
+	}
+
+	if err := u.ackRepo.Save(ctx, ack); err != nil {
+		return nil, fmt.Errorf("failed to save acknowledgement: %v", err)
+	}
+	return ack, nil
+}
+
+// Summary aggregates acknowledgements and lists users inside the alarm
+// area who have not responded yet.
+func (u *alarmAckUsecase) Summary(ctx context.Context, alarmID string) (*dto.AlarmAckSummary, error) {
+	alarm, err := u.findTracked(ctx, alarmID)
+	if err != nil {
+		return nil, err
+	}
+
+	acks, err := u.ackRepo.FindByAlarm(ctx, alarm.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load acknowledgements: %v", err)
+	}
+
+	area, err := alarmAreaOf(alarm)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := alarmRecipients(ctx, u.deviceRepo, alarm, area)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &dto.AlarmAckSummary{
+		AlarmID:      alarm.AlarmID,
+		NeedHelpBy:   make([]dto.AlarmAckUser, 0),
+		NotResponded: make([]dto.AlarmAckUser, 0),
+	}
+
+	responded := make(map[uuid.UUID]bool, len(acks))
+	for _, ack := range acks {
+		responded[ack.UserID] = true
+		if ack.ReceivedAt != nil {
+			summary.Received++
+		}
+		if ack.AcknowledgedAt != nil {
+			summary.Acknowledged++
+		}
+		switch ack.SafetyStatus {
+		case "safe":
+			summary.Safe++
+		case "need_help":
+			summary.NeedHelp++
+			user := dto.AlarmAckUser{UserID: ack.UserID, SafetyStatus: ack.SafetyStatus}
+			if ack.User != nil {
+				user.DisplayName = ack.User.DisplayName
+			}
+			summary.NeedHelpBy = append(summary.NeedHelpBy, user)
+		}
+	}
+
+	recipients := make(map[uuid.UUID]bool)
+	var pending []uuid.UUID
+	for _, d := range devices {
+		if recipients[d.UserID] {
+			continue
+		}
+		recipients[d.UserID] = true
+		if !responded[d.UserID] {
+			pending = append(pending, d.UserID)
+		}
+	}
+	summary.Recipients = len(recipients)
+
+	users, err := u.userRepo.FindByIDs(ctx, pending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load users: %v", err)
+	}
+	for _, user := range users {
+		summary.NotResponded = append(summary.NotResponded, dto.AlarmAckUser{
+			UserID:      user.ID,
+			DisplayName: user.DisplayName,
+		})
+	}
+
+	return summary, nil
+}
+
+func (u *alarmAckUsecase) findTracked(ctx context.Context, alarmID string) (*entities.Alarm, error) {
+	alarm, err := u.alarmRepo.FindByAlarmID(ctx, alarmID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAlarmNotFound
+		}
+		return nil, err
+	}
+	if alarm.Urgency != "immediate" && alarm.Urgency != "high" {
+		return nil, ErrAlarmAckNotTracked
+	}
+	return alarm, nil
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/geo"
+)
+
+var ErrInvalidArea = errors.New("invalid alarm area")
+
+// alarmArea is where an alarm is delivered: an optional circle plus any number of polygons.
+type alarmArea struct {
+	circle   *dto.AlarmCenter
+	polygons geo.MultiPolygon
+}
+
+func newAlarmArea(center *dto.AlarmCenter, geometries []dto.GeoJSONGeometry) (*alarmArea, error) {
+	area := &alarmArea{circle: center}
+	for i, g := range geometries {
+		mp, err := geo.ParseGeoJSON(g.Type, g.Coordinates)
+		if err != nil {
+			return nil, fmt.Errorf("%w: areas[%d]: %v", ErrInvalidArea, i, err)
+		}
+		area.polygons = append(area.polygons, mp...)
+	}
+	if area.circle == nil && len(area.polygons) == 0 {
+		return nil, fmt.Errorf("%w: a center or at least one area is required", ErrInvalidArea)
+	}
+	return area, nil
+}
+
+func alarmAreaOf(alarm *entities.Alarm) (*alarmArea, error) {
+	var center *dto.AlarmCenter
+	if alarm.CircleTargeted {
+		center = &dto.AlarmCenter{Lat: alarm.Latitude, Lng: alarm.Longitude, Radius: alarm.Radius}
+	}
+
+	var geometries []dto.GeoJSONGeometry
+	if len(alarm.Areas) > 0 {
+		if err := json.Unmarshal(alarm.Areas, &geometries); err != nil {
+			return nil, fmt.Errorf("failed to decode areas of alarm %s: %v", alarm.AlarmID, err)
+		}
+	}
+	return newAlarmArea(center, geometries)
+}
+
+func (a *alarmArea) contains(p geo.Point) bool {
+	if a.circle != nil && geo.Distance(a.circle.Lat, a.circle.Lng, p.Lat, p.Lng) <= float64(a.circle.Radius) {
+		return true
+	}
+	return a.polygons.Contains(p)
+}
+
+func (a *alarmArea) bounds() geo.Bounds {
+	b := geo.Bounds{MinLat: 90, MaxLat: -90, MinLng: 180, MaxLng: -180}
+	if len(a.polygons) > 0 {
+		b = a.polygons.Bounds()
+	}
+	if a.circle != nil {
+		c := geo.CircleBounds(a.circle.Lat, a.circle.Lng, float64(a.circle.Radius))
+		b.MinLat = math.Min(b.MinLat, c.MinLat)
+		b.MaxLat = math.Max(b.MaxLat, c.MaxLat)
+		b.MinLng = math.Min(b.MinLng, c.MinLng)
+		b.MaxLng = math.Max(b.MaxLng, c.MaxLng)
+	}
+	return b
+}
+
+// coveringCircle is the circle stored on the alarm record. It equals the
+// target circle when there are no polygons and otherwise encloses the whole area.
+func (a *alarmArea) coveringCircle() dto.AlarmCenter {
+	if len(a.polygons) == 0 {
+		return *a.circle
+	}
+
+	points := a.polygons.Points()
+	if a.circle != nil {
+		points = append(points, geo.CirclePolygon(geo.Point{Lat: a.circle.Lat, Lng: a.circle.Lng}, float64(a.circle.Radius), 16)[0]...)
+	}
+	center, radius := geo.EnclosingCircle(points)
+	return dto.AlarmCenter{Lat: center.Lat, Lng: center.Lng, Radius: int(math.Ceil(radius))}
+}
+
+// selectDevices returns the devices whose last known location is inside the area.
+func selectDevices(ctx context.Context, deviceRepo repositories.DeviceRepository, area *alarmArea) ([]entities.UserDevice, error) {
+	candidates, err := deviceRepo.FindWithinBounds(ctx, area.bounds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to select target devices: %v", err)
+	}
+
+	devices := make([]entities.UserDevice, 0, len(candidates))
+	for _, d := range candidates {
+		if d.Latitude != nil && d.Longitude != nil && area.contains(geo.Point{Lat: *d.Latitude, Lng: *d.Longitude}) {
+			devices = append(devices, d)
+		}
+	}
+	return devices, nil
+}
+
+// alarmRecipients returns the devices an alarm is pushed to: the drill group
+// for drills, wherever its members are, and the devices inside the area otherwise.
+func alarmRecipients(ctx context.Context, deviceRepo repositories.DeviceRepository, alarm *entities.Alarm, area *alarmArea) ([]entities.UserDevice, error) {
+	if alarm.Mode != AlarmModeDrill {
+		return selectDevices(ctx, deviceRepo, area)
+	}
+
+	devices, err := deviceRepo.FindDrillEnrolled(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select drill group devices: %v", err)
+	}
+	return devices, nil
+}
+
+// affectedPotentialPoints returns the potential points inside the area.
+func affectedPotentialPoints(ctx context.Context, ppRepo repositories.PotentialPointRepository, area *alarmArea) ([]dto.AffectedPotentialPoint, error) {
+	candidates, err := ppRepo.FindWithinBounds(ctx, area.bounds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to select affected potential points: %v", err)
+	}
+
+	points := make([]dto.AffectedPotentialPoint, 0)
+	for _, pp := range candidates {
+		if area.contains(geo.Point{Lat: pp.Latitude, Lng: pp.Longitude}) {
+			points = append(points, dto.AffectedPotentialPoint{ID: pp.ID, Name: pp.Name, Type: pp.Type})
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/config"
+	"pbmap_api/src/pkg/placeholder"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAlarmTemplateNotFound = errors.New("alarm template not found")
+	ErrInvalidAlarmTemplate  = errors.New("invalid alarm template")
+	ErrMissingVariables      = errors.New("missing template variables")
+)
+
+// AlarmTemplateUsecase manages reusable alarm templates.
+type AlarmTemplateUsecase interface {
+	Create(ctx context.Context, input dto.CreateAlarmTemplateInput, creatorID *uuid.UUID) (*entities.AlarmTemplate, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*entities.AlarmTemplate, error)
+	Update(ctx context.Context, id uuid.UUID, input dto.UpdateAlarmTemplateInput) (*entities.AlarmTemplate, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindAll(ctx context.Context) ([]entities.AlarmTemplate, error)
+}
+
+type alarmTemplateUsecase struct {
+	repo          repositories.AlarmTemplateRepository
+	defaultLocale string
+}
+
+func NewAlarmTemplateUsecase(repo repositories.AlarmTemplateRepository, cfg *config.Config) AlarmTemplateUsecase {
+	return &alarmTemplateUsecase{repo: repo, defaultLocale: cfg.DefaultLocale}
+}
+
+func (u *alarmTemplateUsecase) Create(ctx context.Context, input dto.CreateAlarmTemplateInput, creatorID *uuid.UUID) (*entities.AlarmTemplate, error) {
+	content := dto.LocalizedText(input.Content).Resolve(u.defaultLocale)
+	if _, ok := content[u.defaultLocale]; !ok {
+		return nil, fmt.Errorf("%w: content must include the default locale %q", ErrInvalidAlarmTemplate, u.defaultLocale)
+	}
+
+	template := &entities.AlarmTemplate{
+		Name:          input.Name,
+		Urgency:       input.Urgency,
+		HazardType:    input.HazardType,
+		Signal:        input.Signal,
+		Content:       datatypes.NewJSONType(content),
+		DefaultRadius: input.DefaultRadius,
+		CreatedBy:     creatorID,
+	}
+	if err := u.repo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (u *alarmTemplateUsecase) FindByID(ctx context.Context, id uuid.UUID) (*entities.AlarmTemplate, error) {
+	template, err := u.repo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAlarmTemplateNotFound
+	}
+	return template, err
+}
+
+func (u *alarmTemplateUsecase) Update(ctx context.Context, id uuid.UUID, input dto.UpdateAlarmTemplateInput) (*entities.AlarmTemplate, error) {
+	template, err := u.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		template.Name = *input.Name
+	}
+	if input.Urgency != nil {
+		template.Urgency = *input.Urgency
+	}
+	if input.HazardType != nil {
+		template.HazardType = *input.HazardType
+	}
+	if input.Signal != nil {
+		template.Signal = *input.Signal
+	}
+	if input.Content != nil {
+		content := dto.LocalizedText(input.Content).Resolve(u.defaultLocale)
+		if _, ok := content[u.defaultLocale]; !ok {
+			return nil, fmt.Errorf("%w: content must include the default locale %q", ErrInvalidAlarmTemplate, u.defaultLocale)
+		}
+		template.Content = datatypes.NewJSONType(content)
+	}
+	if input.DefaultRadius != nil {
+		template.DefaultRadius = *input.DefaultRadius
+	}
+
+	if err := u.repo.Update(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (u *alarmTemplateUsecase) Delete(ctx context.Context, id uuid.UUID) error {
+	err := u.repo.Delete(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAlarmTemplateNotFound
+	}
+	return err
+}
+
+func (u *alarmTemplateUsecase) FindAll(ctx context.Context) ([]entities.AlarmTemplate, error) {
+	return u.repo.FindAll(ctx)
+}
+
+// renderTemplate fills in a dispatch request from its template. Fields set on
+// the request take precedence over the template defaults; every placeholder
+// left in the template text must have a value in req.Variables.
+func renderTemplate(template *entities.AlarmTemplate, req *dto.AlarmDispatchRequest) (*dto.AlarmDispatchRequest, error) {
+	rendered := *req
+	content := template.Content.Data()
+
+	var texts []string
+	if rendered.Signal == "" {
+		texts = append(texts, template.Signal)
+	}
+	if len(rendered.Content) == 0 {
+		for _, text := range content {
+			texts = append(texts, text)
+		}
+	}
+	if missing := placeholder.Missing(req.Variables, texts...); len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingVariables, strings.Join(missing, ", "))
+	}
+
+	if rendered.Urgency == "" {
+		rendered.Urgency = template.Urgency
+	}
+	if rendered.HazardType == "" {
+		rendered.HazardType = template.HazardType
+	}
+	if rendered.Signal == "" {
+		rendered.Signal = placeholder.Render(template.Signal, req.Variables)
+	}
+	if len(rendered.Content) == 0 {
+		rendered.Content = make(dto.LocalizedText, len(content))
+		for locale, text := range content {
+			rendered.Content[locale] = placeholder.Render(text, req.Variables)
+		}
+	}
+	if rendered.Center != nil && rendered.Center.Radius == 0 {
+		center := *rendered.Center
+		center.Radius = template.DefaultRadius
+		rendered.Center = &center
+	}
+	return &rendered, nil
+}
@@ -2,25 +2,853 @@ package usecase
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
 
+	"pbmap_api/src/internal/domain/entities"
 	"pbmap_api/src/internal/domain/repositories"
 	"pbmap_api/src/internal/dto"
+	implRepositories "pbmap_api/src/internal/repositories"
+	"pbmap_api/src/pkg/config"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	AlarmDispatchQueued  = "queued"
+	AlarmDispatchSent    = "sent"
+	AlarmDispatchPartial = "partial"
+	AlarmDispatchFailed  = "failed"
+
+	// Drill alarms reach only the drill group and are listed apart from live ones.
+	AlarmModeLive  = "live"
+	AlarmModeDrill = "drill"
+
+	AlarmStatusPendingApproval = "pending_approval"
+	AlarmStatusActive          = "active"
+	AlarmStatusCancelled       = "cancelled"
+	AlarmStatusExpired         = "expired"
+	AlarmStatusRejected        = "rejected"
+	AlarmStatusApprovalExpired = "approval_expired"
+
+	// Values of the "status" field in the alarm push payload.
+	AlarmMessageNew     = "new"
+	AlarmMessageUpdate  = "update"
+	AlarmMessageCancel  = "cancel"
+	AlarmMessageExpired = "expired"
+)
+
+// alarmIdempotencyTTL bounds how long a retried dispatch is answered from Redis.
+const alarmIdempotencyTTL = 24 * time.Hour
+
+// alarmIdempotencyPendingTTL bounds how long a dispatch in progress holds its
+// alarm_id, so that a process dying mid-dispatch does not block retries for
+// the whole alarmIdempotencyTTL.
+const alarmIdempotencyPendingTTL = time.Minute
+
+// alarmMaxSignalLength and alarmMaxContentLength, in characters, match the
+// limits of dto.AlarmDispatchRequest. They are checked again in the usecase
+// for rendered templates, whose variables may make the text longer, and for
+// alarms from CAP feeds.
+const (
+	alarmMaxSignalLength  = 255
+	alarmMaxContentLength = 600
 )
 
-// AlarmUsecase orchestrates alarm dispatch.
+var (
+	ErrAlarmNotFound   = errors.New("alarm not found")
+	ErrAlarmNotActive  = errors.New("alarm is no longer active")
+	ErrAlarmConflict   = errors.New("alarm_id was already dispatched with a different payload")
+	ErrAlarmInProgress = errors.New("alarm with this alarm_id is already being dispatched")
+
+	ErrAlarmNotPending     = errors.New("alarm is not awaiting approval")
+	ErrAlarmSelfApproval   = errors.New("an alarm must be approved by a different officer than the one who drafted it")
+	ErrAlarmApprovalExpiry = errors.New("approval window for this alarm has passed")
+	ErrAlarmEscalation     = errors.New("urgency cannot be raised to immediate or high on a dispatched alarm; draft a new alarm instead")
+)
+
+// AlarmApproval is a second officer's approval of an alarm.
+type AlarmApproval struct {
+	ApprovedBy uuid.UUID
+	ApprovedAt time.Time
+}
+
+// AlarmUsecase orchestrates alarm dispatch and the lifecycle of dispatched alarms.
 type AlarmUsecase interface {
-	DispatchAlarm(ctx context.Context, req *dto.AlarmDispatchRequest) error
+	DispatchAlarm(ctx context.Context, req *dto.AlarmDispatchRequest, dispatchedBy *uuid.UUID) (*dto.AlarmDispatchResponse, error)
+	DispatchApprovedAlarm(ctx context.Context, req *dto.AlarmDispatchRequest, dispatchedBy *uuid.UUID, approval AlarmApproval) (*dto.AlarmDispatchResponse, error)
+	ValidateAlarm(ctx context.Context, req *dto.AlarmDispatchRequest) error
+	ApproveAlarm(ctx context.Context, alarmID string, approverID uuid.UUID) (*dto.AlarmDispatchResponse, error)
+	RejectAlarm(ctx context.Context, alarmID string, reviewerID uuid.UUID) (*dto.AlarmDispatchResponse, error)
+	UpdateAlarm(ctx context.Context, alarmID string, req *dto.AlarmUpdateRequest) (*dto.AlarmDispatchResponse, error)
+	CancelAlarm(ctx context.Context, alarmID string) (*dto.AlarmDispatchResponse, error)
+	ExpireDueAlarms(ctx context.Context) error
+	DeliverQueued(ctx context.Context, message *entities.OutboxMessage) (*dto.AlarmDispatchResponse, error)
+	FindByAlarmID(ctx context.Context, alarmID string) (*entities.Alarm, error)
+	// FindPublished finds an alarm that has gone out, failing with
+	// ErrAlarmNotFound for drafts and alarms that were never approved.
+	FindPublished(ctx context.Context, alarmID string) (*entities.Alarm, error)
+	FindAll(ctx context.Context, filter dto.AlarmFilter) ([]entities.Alarm, error)
 }
 
 type alarmUsecase struct {
-	fcm repositories.FCMRepository
+	push        *PushRouter
+	deviceRepo  repositories.DeviceRepository
+	alarmRepo   repositories.AlarmRepository
+	idempotency repositories.IdempotencyRepository
+	ppRepo      repositories.PotentialPointRepository
+	templates   repositories.AlarmTemplateRepository
+	pruner      TokenPruner
+	line        *LineChannel
+	sms         *SMSChannel
+	inbox       repositories.UserNotificationRepository
+	preferences *DeliveryPreferences
+	outbox      *Outbox
+	deliveries  *DeliveryLog
+	tm          implRepositories.TransactionManager
+
+	approvalWindow time.Duration
+	defaultLocale  string
 }
 
 // NewAlarmUsecase creates the alarm usecase.
-func NewAlarmUsecase(fcm repositories.FCMRepository) AlarmUsecase {
-	return &alarmUsecase{fcm: fcm}
+func NewAlarmUsecase(push *PushRouter, deviceRepo repositories.DeviceRepository, alarmRepo repositories.AlarmRepository, idempotency repositories.IdempotencyRepository, ppRepo repositories.PotentialPointRepository, templates repositories.AlarmTemplateRepository, pruner TokenPruner, line *LineChannel, sms *SMSChannel, inbox repositories.UserNotificationRepository, preferences *DeliveryPreferences, outbox *Outbox, deliveries *DeliveryLog, tm implRepositories.TransactionManager, cfg *config.Config) AlarmUsecase {
+	return &alarmUsecase{
+		push:           push,
+		deviceRepo:     deviceRepo,
+		alarmRepo:      alarmRepo,
+		idempotency:    idempotency,
+		ppRepo:         ppRepo,
+		templates:      templates,
+		pruner:         pruner,
+		line:           line,
+		sms:            sms,
+		inbox:          inbox,
+		preferences:    preferences,
+		outbox:         outbox,
+		deliveries:     deliveries,
+		tm:             tm,
+		approvalWindow: cfg.AlarmApprovalWindow,
+		defaultLocale:  cfg.DefaultLocale,
+	}
+}
+
+// DispatchAlarm records the alarm and queues it in the outbox, from which the
+// worker sends it to devices whose last known location is inside the alarm's
+// circle or areas. Immediate and high urgency alarms are stored as drafts
+// instead and only go out once ApproveAlarm is called by a second officer.
+// Drills skip approval and go to the drill group instead of the area.
+//
+// Dispatch is idempotent on AlarmID: a repeated request with the same payload
+// returns the original result without sending again, while a repeated request
+// with a different payload fails with ErrAlarmConflict. A request repeating an
+// alarm whose send the outbox gave up on sends it again, unless the alarm has
+// since been cancelled or has expired; then the recorded result is returned
+// with ErrAlarmNotActive. Redis holds the fast path; the alarms table is the
+// fallback when Redis is unavailable or the key has expired.
+func (u *alarmUsecase) DispatchAlarm(ctx context.Context, req *dto.AlarmDispatchRequest, dispatchedBy *uuid.UUID) (*dto.AlarmDispatchResponse, error) {
+	return u.dispatchAlarm(ctx, req, dispatchedBy, nil)
+}
+
+// DispatchApprovedAlarm dispatches an alarm whose second-officer approval was
+// given ahead of time, such as a scheduled alarm approved before it runs.
+func (u *alarmUsecase) DispatchApprovedAlarm(ctx context.Context, req *dto.AlarmDispatchRequest, dispatchedBy *uuid.UUID, approval AlarmApproval) (*dto.AlarmDispatchResponse, error) {
+	if dispatchedBy != nil && *dispatchedBy == approval.ApprovedBy {
+		return nil, ErrAlarmSelfApproval
+	}
+	return u.dispatchAlarm(ctx, req, dispatchedBy, &approval)
+}
+
+// ValidateAlarm checks an alarm the way dispatching it would, without
+// dispatching it, so that a scheduled alarm is rejected up front.
+func (u *alarmUsecase) ValidateAlarm(ctx context.Context, req *dto.AlarmDispatchRequest) error {
+	_, _, err := u.prepare(ctx, req)
+	return err
+}
+
+// prepare renders the alarm's template, if any, and checks its area and
+// content, returning the rendered request and its area.
+func (u *alarmUsecase) prepare(ctx context.Context, req *dto.AlarmDispatchRequest) (*dto.AlarmDispatchRequest, *alarmArea, error) {
+	if req.TemplateID != nil {
+		template, err := u.templates.FindByID(ctx, *req.TemplateID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, ErrAlarmTemplateNotFound
+			}
+			return nil, nil, err
+		}
+		if req, err = renderTemplate(template, req); err != nil {
+			return nil, nil, err
+		}
+	}
+	if req.Center != nil && req.Center.Radius <= 0 {
+		return nil, nil, fmt.Errorf("%w: center radius must be greater than zero", ErrInvalidArea)
+	}
+	area, err := newAlarmArea(req.Center, req.Areas)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := req.Content.Resolve(u.defaultLocale)[u.defaultLocale]; !ok {
+		return nil, nil, fmt.Errorf("%w: content must include the default locale %q", ErrInvalidContent, u.defaultLocale)
+	}
+	if err := checkAlarmLength(req.Signal, req.Content); err != nil {
+		return nil, nil, err
+	}
+	return req, area, nil
+}
+
+// checkAlarmLength fails with ErrInvalidContent when signal or content is
+// too long to be pushed.
+func checkAlarmLength(signal string, content dto.LocalizedText) error {
+	if utf8.RuneCountInString(signal) > alarmMaxSignalLength {
+		return fmt.Errorf("%w: signal is longer than %d characters", ErrInvalidContent, alarmMaxSignalLength)
+	}
+	for locale, text := range content {
+		if utf8.RuneCountInString(text) > alarmMaxContentLength {
+			return fmt.Errorf("%w: %s content is longer than %d characters", ErrInvalidContent, locale, alarmMaxContentLength)
+		}
+	}
+	return nil
+}
+
+func (u *alarmUsecase) dispatchAlarm(ctx context.Context, req *dto.AlarmDispatchRequest, dispatchedBy *uuid.UUID, approval *AlarmApproval) (*dto.AlarmDispatchResponse, error) {
+	// The hash covers the request as sent, so retries of a templated alarm
+	// match even if the template is edited in between.
+	hash, err := payloadHash(req)
+	if err != nil {
+		return nil, err
+	}
+	req, area, err := u.prepare(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	key := "alarm:" + req.AlarmID
+	reserved := false
+	record, err := u.idempotency.Reserve(ctx, key, hash, alarmIdempotencyPendingTTL)
+	switch {
+	case err != nil:
+		fmt.Printf("Warning: alarm idempotency check fell back to database: %v\n", err)
+	case record == nil:
+		reserved = true
+	case record.PayloadHash != hash:
+		return nil, ErrAlarmConflict
+	case record.Response == nil:
+		return nil, ErrAlarmInProgress
+	default:
+		var resp dto.AlarmDispatchResponse
+		if err := json.Unmarshal(record.Response, &resp); err == nil {
+			return &resp, nil
+		}
+	}
+
+	resp, err := u.dispatch(ctx, req, area, hash, dispatchedBy, approval)
+	if !reserved {
+		return resp, err
+	}
+
+	if err != nil {
+		_ = u.idempotency.Release(ctx, key)
+		return resp, err
+	}
+
+	if body, err := json.Marshal(resp); err == nil {
+		_ = u.idempotency.Complete(ctx, key, &entities.IdempotencyRecord{PayloadHash: hash, Response: body}, alarmIdempotencyTTL)
+	}
+	return resp, nil
+}
+
+func (u *alarmUsecase) dispatch(ctx context.Context, req *dto.AlarmDispatchRequest, area *alarmArea, hash string, dispatchedBy *uuid.UUID, approval *AlarmApproval) (*dto.AlarmDispatchResponse, error) {
+	alarm, err := u.alarmRepo.FindByAlarmID(ctx, req.AlarmID)
+	switch {
+	case err == nil:
+		if alarm.PayloadHash != hash {
+			return nil, ErrAlarmConflict
+		}
+		if alarm.DispatchError == "" {
+			return u.recordedResponse(ctx, alarm)
+		}
+		// The outbox gave up on the previous send; retry it on the same
+		// record, unless the alarm is no longer in force.
+		if alarm.Status != AlarmStatusActive || (alarm.ExpiresAt != nil && !alarm.ExpiresAt.After(time.Now())) {
+			resp, err := u.recordedResponse(ctx, alarm)
+			if err != nil {
+				return nil, err
+			}
+			return resp, ErrAlarmNotActive
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		mode := req.Mode
+		if mode == "" {
+			mode = AlarmModeLive
+		}
+
+		content := req.Content.Resolve(u.defaultLocale)
+		circle := area.coveringCircle()
+		alarm = &entities.Alarm{
+			AlarmID:          req.AlarmID,
+			Urgency:          req.Urgency,
+			Mode:             mode,
+			TemplateID:       req.TemplateID,
+			HazardType:       req.HazardType,
+			Latitude:         circle.Lat,
+			Longitude:        circle.Lng,
+			Radius:           circle.Radius,
+			CircleTargeted:   req.Center != nil,
+			Signal:           req.Signal,
+			Content:          content[u.defaultLocale],
+			LocalizedContent: datatypes.NewJSONType(content),
+			DispatchedBy:     dispatchedBy,
+			PayloadHash:      hash,
+			Status:           AlarmStatusActive,
+			ExpiresAt:        req.ExpiresAt,
+		}
+		if len(req.Areas) > 0 {
+			areas, err := json.Marshal(req.Areas)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode alarm areas: %v", err)
+			}
+			alarm.Areas = datatypes.JSON(areas)
+		}
+		if approval != nil {
+			alarm.ApprovedBy = &approval.ApprovedBy
+			alarm.ApprovedAt = &approval.ApprovedAt
+		}
+	default:
+		return nil, fmt.Errorf("failed to look up alarm: %v", err)
+	}
+
+	if alarm.ID == uuid.Nil && alarm.ApprovedBy == nil && alarm.Mode == AlarmModeLive && requiresApproval(alarm.Urgency) {
+		deadline := time.Now().Add(u.approvalWindow)
+		alarm.Status = AlarmStatusPendingApproval
+		alarm.ApprovalExpiresAt = &deadline
+		if err := u.alarmRepo.Create(ctx, alarm); err != nil {
+			return nil, fmt.Errorf("failed to record alarm draft: %v", err)
+		}
+		resp := dto.ToAlarmDispatchResponse(alarm)
+		return &resp, nil
+	}
+
+	return u.deliver(ctx, alarm, "")
+}
+
+// alarmSend is the outbox payload of an alarm message.
+type alarmSend struct {
+	AlarmID uuid.UUID `json:"alarm_id"` // ID of the alarm record
+	Status  string    `json:"status"`   // new, update, cancel, expired
+}
+
+// deliver saves a new alarm and queues its first send in one transaction, so
+// an alarm is never recorded without being sent or sent without a record.
+// With from set, an existing alarm is only saved and sent while its stored
+// status is still from, so that concurrent approvals send it once.
+func (u *alarmUsecase) deliver(ctx context.Context, alarm *entities.Alarm, from string) (*dto.AlarmDispatchResponse, error) {
+	isNew := alarm.ID == uuid.Nil
+	if isNew {
+		alarm.ID = uuid.New()
+	}
+	trackingID := uuid.New()
+	now := time.Now()
+	alarm.DispatchedAt = &now
+	alarm.DispatchStatus = AlarmDispatchQueued
+	alarm.DispatchError = ""
+	alarm.TrackingID = &trackingID
+
+	err := u.tm.Do(ctx, func(ctx context.Context) error {
+		var err error
+		switch {
+		case isNew:
+			err = u.alarmRepo.Create(ctx, alarm)
+		case from != "":
+			var saved bool
+			saved, err = u.alarmRepo.UpdateIfStatus(ctx, alarm, from)
+			if err == nil && !saved {
+				return ErrAlarmNotPending
+			}
+		default:
+			err = u.alarmRepo.Update(ctx, alarm)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to record alarm: %v", err)
+		}
+		_, err = u.outbox.enqueue(ctx, trackingID, OutboxKindAlarm, alarmSend{AlarmID: alarm.ID, Status: AlarmMessageNew}, alarm.DispatchedBy)
+		return err
+	})
+	if err != nil {
+		if isNew {
+			alarm.ID = uuid.Nil
+		}
+		return nil, err
+	}
+	return u.recordedResponse(ctx, alarm)
+}
+
+// queueFollowUp saves changes to a dispatched alarm and queues the follow-up
+// message announcing them in one transaction.
+func (u *alarmUsecase) queueFollowUp(ctx context.Context, alarm *entities.Alarm, status string) (*dto.AlarmDispatchResponse, error) {
+	var message *entities.OutboxMessage
+	err := u.tm.Do(ctx, func(ctx context.Context) error {
+		if err := u.alarmRepo.Update(ctx, alarm); err != nil {
+			return fmt.Errorf("failed to save alarm: %v", err)
+		}
+		var err error
+		message, err = u.outbox.enqueue(ctx, uuid.Nil, OutboxKindAlarm, alarmSend{AlarmID: alarm.ID, Status: status}, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AlarmDispatchResponse{
+		AlarmID:        alarm.AlarmID,
+		Status:         alarm.Status,
+		DispatchStatus: AlarmDispatchQueued,
+		TrackingID:     &message.ID,
+	}, nil
+}
+
+// DeliverQueued sends an alarm message taken from the outbox. The outcome of
+// a new alarm is saved on its record, which is marked failed once the outbox
+// gives up on it.
+func (u *alarmUsecase) DeliverQueued(ctx context.Context, message *entities.OutboxMessage) (*dto.AlarmDispatchResponse, error) {
+	var payload alarmSend
+	if err := json.Unmarshal(message.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJobRejected, err)
+	}
+	alarm, err := u.alarmRepo.FindByID(ctx, payload.AlarmID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %v", ErrJobRejected, ErrAlarmNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alarm: %v", err)
+	}
+
+	// Only the first attempt writes the inbox, so retries do not repeat it.
+	trail := u.deliveries.trail(ctx, message).forAlarm(alarm, payload.Status)
+	result, sendErr := u.send(ctx, alarm, payload.Status, message.Attempts <= 1, trail)
+	trail.flush(ctx)
+	if payload.Status != AlarmMessageNew {
+		if sendErr != nil {
+			return nil, sendErr
+		}
+		return &result.AlarmDispatchResponse, nil
+	}
+
+	if sendErr != nil {
+		if message.Attempts < message.MaxAttempts && !errors.Is(sendErr, ErrJobRejected) {
+			return nil, sendErr
+		}
+		alarm.DispatchStatus = AlarmDispatchFailed
+		alarm.DispatchError = sendErr.Error()
+	} else {
+		alarm.TargetedDevices = result.TargetedDevices
+		alarm.SuccessCount = result.SuccessCount
+		alarm.FailureCount = result.FailureCount
+		alarm.MessageIDs = datatypes.NewJSONSlice(result.MessageIDs)
+		alarm.DispatchStatus = dispatchStatus(result.SuccessCount, result.FailureCount)
+		alarm.DispatchError = ""
+	}
+	if err := u.alarmRepo.Update(ctx, alarm); err != nil {
+		return nil, fmt.Errorf("failed to record alarm: %v", err)
+	}
+
+	if sendErr != nil {
+		return nil, sendErr
+	}
+	return &result.AlarmDispatchResponse, nil
+}
+
+// ApproveAlarm releases a draft alarm. The approver must differ from the
+// officer who drafted it and the approval window must still be open.
+func (u *alarmUsecase) ApproveAlarm(ctx context.Context, alarmID string, approverID uuid.UUID) (*dto.AlarmDispatchResponse, error) {
+	alarm, err := u.findPending(ctx, alarmID, approverID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	alarm.Status = AlarmStatusActive
+	alarm.ApprovedBy = &approverID
+	alarm.ApprovedAt = &now
+	return u.deliver(ctx, alarm, AlarmStatusPendingApproval)
+}
+
+// RejectAlarm discards a draft alarm without sending it.
+func (u *alarmUsecase) RejectAlarm(ctx context.Context, alarmID string, reviewerID uuid.UUID) (*dto.AlarmDispatchResponse, error) {
+	alarm, err := u.findPending(ctx, alarmID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	alarm.Status = AlarmStatusRejected
+	alarm.RejectedBy = &reviewerID
+	alarm.RejectedAt = &now
+	rejected, err := u.alarmRepo.UpdateIfStatus(ctx, alarm, AlarmStatusPendingApproval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject alarm: %v", err)
+	}
+	if !rejected {
+		return nil, ErrAlarmNotPending
+	}
+
+	resp := dto.ToAlarmDispatchResponse(alarm)
+	return &resp, nil
+}
+
+// UpdateAlarm applies corrections to an active alarm and queues them for the
+// devices in its area as an "update" follow-up.
+func (u *alarmUsecase) UpdateAlarm(ctx context.Context, alarmID string, req *dto.AlarmUpdateRequest) (*dto.AlarmDispatchResponse, error) {
+	alarm, err := u.findActive(ctx, alarmID)
+	if err != nil {
+		return nil, err
+	}
+	signal := ""
+	if req.Signal != nil {
+		signal = *req.Signal
+	}
+	if err := checkAlarmLength(signal, req.Content); err != nil {
+		return nil, err
+	}
+
+	if req.Urgency != nil {
+		if alarm.Mode == AlarmModeLive && requiresApproval(*req.Urgency) && !requiresApproval(alarm.Urgency) {
+			return nil, ErrAlarmEscalation
+		}
+		alarm.Urgency = *req.Urgency
+	}
+	if req.Signal != nil {
+		alarm.Signal = *req.Signal
+	}
+	if len(req.Content) > 0 {
+		// Only the locales in the request are replaced; the rest are kept.
+		content := alarmContent(alarm, u.defaultLocale)
+		for locale, text := range req.Content.Resolve(u.defaultLocale) {
+			content[locale] = text
+		}
+		alarm.Content = content[u.defaultLocale]
+		alarm.LocalizedContent = datatypes.NewJSONType(content)
+	}
+	if req.ExpiresAt != nil {
+		alarm.ExpiresAt = req.ExpiresAt
+	}
+	return u.queueFollowUp(ctx, alarm, AlarmMessageUpdate)
+}
+
+// CancelAlarm marks an active alarm as cancelled and queues an "all clear".
+func (u *alarmUsecase) CancelAlarm(ctx context.Context, alarmID string) (*dto.AlarmDispatchResponse, error) {
+	alarm, err := u.findActive(ctx, alarmID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	alarm.Status = AlarmStatusCancelled
+	alarm.CancelledAt = &now
+	return u.queueFollowUp(ctx, alarm, AlarmMessageCancel)
+}
+
+// ExpireDueAlarms expires every active alarm whose expires_at has passed and
+// every draft whose approval window has closed.
+func (u *alarmUsecase) ExpireDueAlarms(ctx context.Context) error {
+	drafts, err := u.alarmRepo.FindExpiredApprovals(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to find expired drafts: %v", err)
+	}
+
+	for i := range drafts {
+		drafts[i].Status = AlarmStatusApprovalExpired
+		if err := u.alarmRepo.Update(ctx, &drafts[i]); err != nil {
+			return fmt.Errorf("failed to expire draft %s: %v", drafts[i].AlarmID, err)
+		}
+	}
+
+	alarms, err := u.alarmRepo.FindExpired(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to find expired alarms: %v", err)
+	}
+
+	for i := range alarms {
+		alarm := &alarms[i]
+		alarm.Status = AlarmStatusExpired
+		if _, err := u.queueFollowUp(ctx, alarm, AlarmMessageExpired); err != nil {
+			return fmt.Errorf("failed to expire alarm %s: %v", alarm.AlarmID, err)
+		}
+	}
+	return nil
+}
+
+func (u *alarmUsecase) FindByAlarmID(ctx context.Context, alarmID string) (*entities.Alarm, error) {
+	return u.alarmRepo.FindByAlarmID(ctx, alarmID)
+}
+
+func (u *alarmUsecase) FindPublished(ctx context.Context, alarmID string) (*entities.Alarm, error) {
+	alarm, err := u.alarmRepo.FindByAlarmID(ctx, alarmID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAlarmNotFound
+		}
+		return nil, err
+	}
+	switch alarm.Status {
+	case AlarmStatusPendingApproval, AlarmStatusRejected, AlarmStatusApprovalExpired:
+		return nil, ErrAlarmNotFound
+	}
+	return alarm, nil
+}
+
+func (u *alarmUsecase) FindAll(ctx context.Context, filter dto.AlarmFilter) ([]entities.Alarm, error) {
+	return u.alarmRepo.FindAll(ctx, filter)
+}
+
+func (u *alarmUsecase) findActive(ctx context.Context, alarmID string) (*entities.Alarm, error) {
+	alarm, err := u.alarmRepo.FindByAlarmID(ctx, alarmID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAlarmNotFound
+		}
+		return nil, err
+	}
+	if alarm.Status != AlarmStatusActive {
+		return nil, ErrAlarmNotActive
+	}
+	return alarm, nil
+}
+
+func (u *alarmUsecase) findPending(ctx context.Context, alarmID string, reviewerID uuid.UUID) (*entities.Alarm, error) {
+	alarm, err := u.alarmRepo.FindByAlarmID(ctx, alarmID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAlarmNotFound
+		}
+		return nil, err
+	}
+	if alarm.Status != AlarmStatusPendingApproval {
+		return nil, ErrAlarmNotPending
+	}
+	if alarm.DispatchedBy != nil && *alarm.DispatchedBy == reviewerID {
+		return nil, ErrAlarmSelfApproval
+	}
+	if alarm.ApprovalExpiresAt != nil && alarm.ApprovalExpiresAt.Before(time.Now()) {
+		alarm.Status = AlarmStatusApprovalExpired
+		if err := u.alarmRepo.Update(ctx, alarm); err != nil {
+			return nil, fmt.Errorf("failed to expire draft: %v", err)
+		}
+		return nil, ErrAlarmApprovalExpiry
+	}
+	return alarm, nil
+}
+
+// recordedResponse rebuilds the dispatch response of an alarm that was already sent.
+func (u *alarmUsecase) recordedResponse(ctx context.Context, alarm *entities.Alarm) (*dto.AlarmDispatchResponse, error) {
+	area, err := alarmAreaOf(alarm)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := dto.ToAlarmDispatchResponse(alarm)
+	resp.AffectedPotentialPoints, err = affectedPotentialPoints(ctx, u.ppRepo, area)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+type sendResult struct {
+	dto.AlarmDispatchResponse
+	MessageIDs []string
+}
+
+// send pushes the alarm with the given message status to all of its
+// recipients, keeping it in their inboxes when withInbox is set. The outcome
+// per recipient is collected in trail.
+func (u *alarmUsecase) send(ctx context.Context, alarm *entities.Alarm, status string, withInbox bool, trail *deliveryTrail) (*sendResult, error) {
+	area, err := alarmAreaOf(alarm)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := alarmRecipients(ctx, u.deviceRepo, alarm, area)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := affectedPotentialPoints(ctx, u.ppRepo, area)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each device gets the content in its own locale, falling back to the default locale.
+	content := alarmContent(alarm, u.defaultLocale)
+
+	var smsUsers []entities.User
+	if u.sms.enabled() {
+		if smsUsers, err = u.sms.recipients(ctx, alarm, area); err != nil {
+			fmt.Printf("Warning: failed to send alarm %s by SMS: %v\n", alarm.AlarmID, err)
+		}
+	}
+	// The inbox is written first so that opening the push finds the message
+	// there. It is kept for everyone in the area, whatever their preferences.
+	if withInbox {
+		u.recordInbox(ctx, alarm, status, devices, smsUsers, content)
+	}
+
+	// An alarm is better sent to someone who opted out than to no one, so
+	// recipients are kept when their preferences cannot be loaded.
+	filter := u.preferences.forAlarm(alarm)
+	pushDevices, err := filter.devices(ctx, devices)
+	if err != nil {
+		fmt.Printf("Warning: sending alarm %s without preferences: %v\n", alarm.AlarmID, err)
+		pushDevices = devices
+	}
+	if filtered, err := filter.smsUsers(ctx, smsUsers); err == nil {
+		smsUsers = filtered
+	}
+
+	sent := &sendResult{
+		AlarmDispatchResponse: dto.AlarmDispatchResponse{
+			AlarmID:                 alarm.AlarmID,
+			Status:                  alarm.Status,
+			TargetedDevices:         len(pushDevices),
+			AffectedPotentialPoints: points,
+		},
+		MessageIDs: make([]string, 0),
+	}
+
+	byToken := indexByToken(pushDevices)
+	for _, batch := range u.push.batches(pushDevices, content, u.defaultLocale) {
+		tokens, reached := trail.unsent(batch.Provider, batch.Tokens)
+		sent.SuccessCount += len(reached)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		msg := dto.ToAlarmMessage(alarm, status)
+		msg.Content = content[batch.Locale]
+		msg.Locale = batch.Locale
+
+		result, err := u.push.sender(batch.Provider).SendAlarm(ctx, msg, tokens)
+		trail.push(batch.Provider, byToken, tokens, result, err)
+		if err != nil {
+			return nil, err
+		}
+		u.pruner.Prune(ctx, len(tokens), result.FailureTokens)
+		sent.SuccessCount += result.SuccessCount
+		sent.FailureCount += result.FailureCount
+		sent.MessageIDs = append(sent.MessageIDs, result.MessageIDs...)
+	}
+	if u.line.enabled() {
+		sent.LineRecipients = u.sendLine(ctx, alarm, status, devices, content, filter, trail)
+	}
+	if len(smsUsers) > 0 {
+		// SMS goes out in the default locale to users who cannot be reached by
+		// push. Like LINE, SMS failures are logged rather than failing the dispatch.
+		sent.SMSRecipients = u.sms.sendAll(ctx, smsUsers, alarmSMSText(alarm.Signal, content[u.defaultLocale], status, u.defaultLocale), trail)
+	}
+	return sent, nil
+}
+
+// recordInbox keeps the alarm message in the inbox of every user it is sent
+// to. Failures are logged so they never hold back the alarm itself.
+func (u *alarmUsecase) recordInbox(ctx context.Context, alarm *entities.Alarm, status string, devices []entities.UserDevice, smsUsers []entities.User, content map[string]string) {
+	userIDs := deviceOwners(devices)
+	for _, user := range smsUsers {
+		userIDs = append(userIDs, user.ID)
+	}
+
+	titles := make(map[string]string, len(content))
+	for locale := range content {
+		titles[locale] = alarm.Signal
+	}
+	notifications := inboxNotifications(dto.InboxKindAlarm, userIDs, devices, titles, content, u.defaultLocale)
+	for i := range notifications {
+		notifications[i].AlarmID = &alarm.AlarmID
+		notifications[i].AlarmStatus = status
+	}
+	if err := u.inbox.CreateBatch(ctx, notifications); err != nil {
+		fmt.Printf("Warning: failed to record alarm %s in inboxes: %v\n", alarm.AlarmID, err)
+	}
+}
+
+// sendLine sends the alarm over LINE to the opted-in owners of devices and
+// returns how many received it. Push remains the primary channel, so LINE
+// failures are logged rather than failing the dispatch.
+func (u *alarmUsecase) sendLine(ctx context.Context, alarm *entities.Alarm, status string, devices []entities.UserDevice, content map[string]string, filter *deliveryFilter, trail *deliveryTrail) int {
+	recipients, err := u.line.recipientsOf(ctx, devices)
+	if err != nil {
+		fmt.Printf("Warning: failed to send alarm %s over LINE: %v\n", alarm.AlarmID, err)
+		return 0
+	}
+	if filtered, err := filter.lineRecipients(ctx, recipients); err == nil {
+		recipients = filtered
+	}
+
+	delivered := 0
+	owners := lineOwners(recipients)
+	batches := lineBatches(recipients, devices, content, u.defaultLocale)
+	for _, locale := range sortedLocales(batches) {
+		lineUserIDs, reached := trail.unsent(channelLine, batches[locale])
+		delivered += len(reached)
+		if len(lineUserIDs) == 0 {
+			continue
+		}
+
+		msg := dto.ToAlarmMessage(alarm, status)
+		msg.Content = content[locale]
+		msg.Locale = locale
+
+		result, err := u.line.sender.SendAlarm(ctx, msg, lineUserIDs)
+		trail.line(owners, lineUserIDs, result, err)
+		if err != nil {
+			fmt.Printf("Warning: failed to send alarm %s over LINE: %v\n", alarm.AlarmID, err)
+			continue
+		}
+		delivered += result.SuccessCount
+	}
+	return delivered
+}
+
+// payloadHash fingerprints a dispatch request so retries can be told apart from conflicting reuse of an alarm_id.
+func payloadHash(req *dto.AlarmDispatchRequest) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode alarm payload: %v", err)
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// alarmContent returns the alarm content per locale. Alarms stored before
+// content was localized only have the default-locale text.
+func alarmContent(alarm *entities.Alarm, defaultLocale string) map[string]string {
+	content := make(map[string]string)
+	for locale, text := range alarm.LocalizedContent.Data() {
+		content[locale] = text
+	}
+	if _, ok := content[defaultLocale]; !ok {
+		content[defaultLocale] = alarm.Content
+	}
+	return content
+}
+
+// requiresApproval reports whether alarms of this urgency need a second officer's approval.
+func requiresApproval(urgency string) bool {
+	return urgency == "immediate" || urgency == "high"
 }
 
-func (u *alarmUsecase) DispatchAlarm(ctx context.Context, req *dto.AlarmDispatchRequest) error {
-	return u.fcm.SendAlarm(ctx, req)
+func dispatchStatus(successCount, failureCount int) string {
+	switch {
+	case failureCount == 0:
+		return AlarmDispatchSent
+	case successCount == 0:
+		return AlarmDispatchFailed
+	default:
+		return AlarmDispatchPartial
+	}
 }
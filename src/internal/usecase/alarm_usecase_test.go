@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/config"
+	"pbmap_api/src/pkg/geo"
+
+	"gorm.io/gorm"
+)
+
+// stubAlarmRepo holds a single recorded alarm.
+type stubAlarmRepo struct {
+	repositories.AlarmRepository
+	alarm *entities.Alarm
+}
+
+func (r *stubAlarmRepo) FindByAlarmID(ctx context.Context, alarmID string) (*entities.Alarm, error) {
+	if r.alarm == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.alarm, nil
+}
+
+// stubPotentialPointRepo finds no potential points.
+type stubPotentialPointRepo struct {
+	repositories.PotentialPointRepository
+}
+
+func (r *stubPotentialPointRepo) FindWithinBounds(ctx context.Context, bounds geo.Bounds) ([]entities.PotentialPoint, error) {
+	return nil, nil
+}
+
+func TestDispatchAlarmDoesNotResendInactiveAlarm(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name      string
+		status    string
+		expiresAt *time.Time
+	}{
+		{name: "cancelled", status: AlarmStatusCancelled},
+		{name: "expired", status: AlarmStatusExpired, expiresAt: &past},
+		{name: "past expiry", status: AlarmStatusActive, expiresAt: &past},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &dto.AlarmDispatchRequest{
+				AlarmID: "alarm-1",
+				Urgency: "normal",
+				Center:  &dto.AlarmCenter{Lat: 13.75, Lng: 100.5, Radius: 1000},
+				Signal:  "flood",
+				Content: dto.LocalizedText{"th": "น้ำท่วม"},
+			}
+			hash, err := payloadHash(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			alarms := &stubAlarmRepo{alarm: &entities.Alarm{
+				AlarmID:        req.AlarmID,
+				Latitude:       req.Center.Lat,
+				Longitude:      req.Center.Lng,
+				Radius:         req.Center.Radius,
+				CircleTargeted: true,
+				PayloadHash:    hash,
+				Status:         tt.status,
+				ExpiresAt:      tt.expiresAt,
+				DispatchError:  "fcm: retries exhausted",
+			}}
+			idempotency := &stubIdempotencyRepo{records: make(map[string]*entities.IdempotencyRecord)}
+			alarm := NewAlarmUsecase(nil, nil, alarms, idempotency, &stubPotentialPointRepo{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{DefaultLocale: "th"})
+
+			resp, err := alarm.DispatchAlarm(context.Background(), req, nil)
+			if !errors.Is(err, ErrAlarmNotActive) {
+				t.Fatalf("err = %v, want ErrAlarmNotActive", err)
+			}
+			if resp == nil || resp.AlarmID != req.AlarmID {
+				t.Fatalf("resp = %+v, want the recorded alarm", resp)
+			}
+			if len(idempotency.records) != 0 {
+				t.Fatal("reservation kept after the alarm was not sent")
+			}
+		})
+	}
+}
+
+func TestDispatchAlarmRejectsContentTooLongToPush(t *testing.T) {
+	req := &dto.AlarmDispatchRequest{
+		AlarmID: "alarm-1",
+		Urgency: "normal",
+		Center:  &dto.AlarmCenter{Lat: 13.75, Lng: 100.5, Radius: 1000},
+		Signal:  "flood",
+		Content: dto.LocalizedText{"th": strings.Repeat("น้ำท่วม", 100)},
+	}
+	alarm := NewAlarmUsecase(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{DefaultLocale: "th"})
+
+	if _, err := alarm.DispatchAlarm(context.Background(), req, nil); !errors.Is(err, ErrInvalidContent) {
+		t.Fatalf("err = %v, want ErrInvalidContent", err)
+	}
+}
+
+func TestFindPublished(t *testing.T) {
+	tests := []struct {
+		name    string
+		alarm   *entities.Alarm
+		wantErr error
+	}{
+		{name: "missing", wantErr: ErrAlarmNotFound},
+		{name: "draft", alarm: &entities.Alarm{Status: AlarmStatusPendingApproval}, wantErr: ErrAlarmNotFound},
+		{name: "rejected", alarm: &entities.Alarm{Status: AlarmStatusRejected}, wantErr: ErrAlarmNotFound},
+		{name: "approval expired", alarm: &entities.Alarm{Status: AlarmStatusApprovalExpired}, wantErr: ErrAlarmNotFound},
+		{name: "active", alarm: &entities.Alarm{Status: AlarmStatusActive}},
+		{name: "cancelled", alarm: &entities.Alarm{Status: AlarmStatusCancelled}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alarm := NewAlarmUsecase(nil, nil, &stubAlarmRepo{alarm: tt.alarm}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+			if _, err := alarm.FindPublished(context.Background(), "alarm-1"); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
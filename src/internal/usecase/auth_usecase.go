@@ -91,12 +91,14 @@ func (s *authService) LoginWithSocial(ctx context.Context, req *dto.SocialLoginR
 		return nil, fmt.Errorf("failed to generate refresh token: %v", err)
 	}
 
+	// Topic changes call FCM, so they wait until the device is committed.
+	var syncTopics func(ctx context.Context) error
 	err = s.tm.Do(ctx, func(ctx context.Context) error {
 		deviceID := uuid.Nil
 		if req.DeviceID != "" || req.DeviceType != "" || req.PushToken != "" {
-			provider := "apns"
-			if req.DeviceType == "android" {
-				provider = "fcm"
+			provider := req.PushProvider
+			if provider == "" {
+				provider = ProviderFCM
 			}
 
 			device := &entities.UserDevice{
@@ -104,12 +106,18 @@ func (s *authService) LoginWithSocial(ctx context.Context, req *dto.SocialLoginR
 				Provider:   provider,
 				DeviceType: req.DeviceType,
 				PushToken:  req.PushToken,
+				Locale:     normalizeLocale(req.Locale),
 				LastSeen:   time.Now(),
 			}
+			if id, err := uuid.Parse(req.DeviceID); err == nil {
+				device.ID = id
+			}
 
-			if err := s.userUsecase.UpsertDevice(ctx, device); err != nil {
+			sync, err := s.userUsecase.UpsertDevice(ctx, device)
+			if err != nil {
 				return fmt.Errorf("failed to register device: %v", err)
 			}
+			syncTopics = sync
 
 			if device.ID != uuid.Nil {
 				deviceID = device.ID
@@ -147,6 +155,11 @@ func (s *authService) LoginWithSocial(ctx context.Context, req *dto.SocialLoginR
 	if err != nil {
 		return nil, err
 	}
+	if syncTopics != nil {
+		if err := syncTopics(ctx); err != nil {
+			fmt.Printf("Warning: failed to update topics of a device of user %s: %v\n", user.ID, err)
+		}
+	}
 
 	return &dto.LoginResponse{
 		AccessToken:  token,
@@ -0,0 +1,418 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/config"
+	"pbmap_api/src/pkg/geo"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCAP = errors.New("invalid CAP alert")
+
+// CAPUsecase maps Common Alerting Protocol 1.2 documents onto the alarm flow.
+type CAPUsecase interface {
+	Ingest(ctx context.Context, alert *dto.CAPAlert, dispatchedBy *uuid.UUID) (*dto.AlarmDispatchResponse, error)
+	Export(ctx context.Context, alarmID string) (*dto.CAPAlert, error)
+	ActiveFeed(ctx context.Context, baseURL string) (*dto.AtomFeed, error)
+}
+
+type capUsecase struct {
+	alarmUsecase  AlarmUsecase
+	sender        string
+	defaultLocale string
+}
+
+// NewCAPUsecase creates the CAP usecase.
+func NewCAPUsecase(alarmUsecase AlarmUsecase, cfg *config.Config) CAPUsecase {
+	return &capUsecase{alarmUsecase: alarmUsecase, sender: cfg.CAPSender, defaultLocale: cfg.DefaultLocale}
+}
+
+// Ingest dispatches, updates or cancels an alarm from a CAP alert depending on its msgType.
+func (u *capUsecase) Ingest(ctx context.Context, alert *dto.CAPAlert, dispatchedBy *uuid.UUID) (*dto.AlarmDispatchResponse, error) {
+	if alert.Status != "Actual" && alert.Status != "Exercise" {
+		return nil, fmt.Errorf("%w: status %q is not supported", ErrInvalidCAP, alert.Status)
+	}
+
+	switch alert.MsgType {
+	case "Alert":
+		req, err := capToDispatchRequest(alert, u.defaultLocale)
+		if err != nil {
+			return nil, err
+		}
+		if alert.Status == "Exercise" {
+			req.Mode = AlarmModeDrill
+		}
+		return u.alarmUsecase.DispatchAlarm(ctx, req, dispatchedBy)
+	case "Update":
+		alarmID, err := capReferencedID(alert)
+		if err != nil {
+			return nil, err
+		}
+		req, err := capToDispatchRequest(alert, u.defaultLocale)
+		if err != nil {
+			return nil, err
+		}
+		return u.alarmUsecase.UpdateAlarm(ctx, alarmID, &dto.AlarmUpdateRequest{
+			Urgency:   &req.Urgency,
+			Signal:    &req.Signal,
+			Content:   req.Content,
+			ExpiresAt: req.ExpiresAt,
+		})
+	case "Cancel":
+		alarmID, err := capReferencedID(alert)
+		if err != nil {
+			return nil, err
+		}
+		return u.alarmUsecase.CancelAlarm(ctx, alarmID)
+	default:
+		return nil, fmt.Errorf("%w: msgType %q is not supported", ErrInvalidCAP, alert.MsgType)
+	}
+}
+
+// Export renders a stored alarm as a CAP alert.
+func (u *capUsecase) Export(ctx context.Context, alarmID string) (*dto.CAPAlert, error) {
+	alarm, err := u.alarmUsecase.FindByAlarmID(ctx, alarmID)
+	if err != nil {
+		return nil, ErrAlarmNotFound
+	}
+	return u.alarmToCAP(alarm), nil
+}
+
+// ActiveFeed lists active alarms as an Atom feed linking to their CAP documents.
+func (u *capUsecase) ActiveFeed(ctx context.Context, baseURL string) (*dto.AtomFeed, error) {
+	alarms, err := u.alarmUsecase.FindAll(ctx, dto.AlarmFilter{Mode: AlarmModeLive, Status: AlarmStatusActive, Limit: 100})
+	if err != nil {
+		return nil, err
+	}
+
+	feedURL := baseURL + "/api/v1/dispatch/cap/feed"
+	feed := &dto.AtomFeed{
+		ID:      feedURL,
+		Title:   "Active alerts from " + u.sender,
+		Updated: time.Now().UTC().Format(time.RFC3339),
+		Link:    []dto.AtomLink{{Rel: "self", Href: feedURL}},
+		Entries: make([]dto.AtomEntry, 0, len(alarms)),
+	}
+
+	for _, alarm := range alarms {
+		capURL := fmt.Sprintf("%s/api/v1/dispatch/alarms/%s/cap", baseURL, alarm.AlarmID)
+		feed.Entries = append(feed.Entries, dto.AtomEntry{
+			ID:      capURL,
+			Title:   alarm.Signal,
+			Updated: alarm.UpdatedAt.UTC().Format(time.RFC3339),
+			Summary: alarm.Content,
+			Link:    []dto.AtomLink{{Rel: "alternate", Type: "application/cap+xml", Href: capURL}},
+		})
+	}
+	return feed, nil
+}
+
+func (u *capUsecase) alarmToCAP(alarm *entities.Alarm) *dto.CAPAlert {
+	msgType := "Alert"
+	if alarm.Status == AlarmStatusCancelled {
+		msgType = "Cancel"
+	}
+
+	capUrgency, severity := "Future", "Minor"
+	switch alarm.Urgency {
+	case "immediate":
+		capUrgency, severity = "Immediate", "Extreme"
+	case "high":
+		capUrgency, severity = "Immediate", "Severe"
+	case "normal":
+		capUrgency, severity = "Expected", "Moderate"
+	}
+
+	area := dto.CAPArea{AreaDesc: alarm.Signal}
+	if alarm.CircleTargeted {
+		area.Circle = []string{fmt.Sprintf("%g,%g %g", alarm.Latitude, alarm.Longitude, float64(alarm.Radius)/1000)}
+	}
+	if len(alarm.Areas) > 0 {
+		var geometries []dto.GeoJSONGeometry
+		if err := json.Unmarshal(alarm.Areas, &geometries); err == nil {
+			area.Polygon = capPolygons(geometries)
+		}
+	}
+	var expires string
+	if alarm.ExpiresAt != nil {
+		expires = alarm.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+
+	// One info block per locale, default locale first.
+	content := alarmContent(alarm, u.defaultLocale)
+	locales := make([]string, 0, len(content))
+	for locale := range content {
+		if locale != u.defaultLocale {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	locales = append([]string{u.defaultLocale}, locales...)
+
+	infos := make([]dto.CAPInfo, 0, len(locales))
+	for _, locale := range locales {
+		infos = append(infos, dto.CAPInfo{
+			Language:    locale,
+			Category:    []string{"Safety"},
+			Event:       alarm.Signal,
+			Urgency:     capUrgency,
+			Severity:    severity,
+			Certainty:   "Likely",
+			Expires:     expires,
+			SenderName:  u.sender,
+			Headline:    alarm.Signal,
+			Description: content[locale],
+			Area:        []dto.CAPArea{area},
+		})
+	}
+
+	status := "Actual"
+	if alarm.Mode == AlarmModeDrill {
+		status = "Exercise"
+	}
+
+	sent := alarm.CreatedAt
+	if alarm.DispatchedAt != nil {
+		sent = *alarm.DispatchedAt
+	}
+
+	return &dto.CAPAlert{
+		Xmlns:      dto.CAPNamespace,
+		Identifier: alarm.AlarmID,
+		Sender:     u.sender,
+		Sent:       sent.UTC().Format(time.RFC3339),
+		Status:     status,
+		MsgType:    msgType,
+		Scope:      "Public",
+		Info:       infos,
+	}
+}
+
+func capToDispatchRequest(alert *dto.CAPAlert, defaultLocale string) (*dto.AlarmDispatchRequest, error) {
+	if alert.Identifier == "" {
+		return nil, fmt.Errorf("%w: identifier is required", ErrInvalidCAP)
+	}
+	if len(alert.Info) == 0 {
+		return nil, fmt.Errorf("%w: at least one info block is required", ErrInvalidCAP)
+	}
+	info := alert.Info[0]
+
+	center, areas, err := capAreas(info.Area)
+	if err != nil {
+		return nil, err
+	}
+
+	signal := info.Headline
+	if signal == "" {
+		signal = info.Event
+	}
+	if signal == "" {
+		return nil, fmt.Errorf("%w: headline or event is required", ErrInvalidCAP)
+	}
+
+	// Every info block carries the same alert in another language. Blocks
+	// without a language are taken to be in the default locale, and with none
+	// in it the first block stands in for it, so feeds in a single other
+	// language are still accepted.
+	content := make(dto.LocalizedText, len(alert.Info)+1)
+	first := ""
+	for _, in := range alert.Info {
+		locale := strings.ToLower(in.Language)
+		if i := strings.Index(locale, "-"); i > 0 {
+			locale = locale[:i]
+		}
+		if _, ok := content[locale]; ok {
+			continue
+		}
+
+		text := in.Description
+		if in.Instruction != "" {
+			text = strings.TrimSpace(text + "\n" + in.Instruction)
+		}
+		if text == "" {
+			text = signal
+		}
+		content[locale] = text
+		if first == "" {
+			first = text
+		}
+	}
+	if _, ok := content.Resolve(defaultLocale)[defaultLocale]; !ok {
+		content[defaultLocale] = first
+	}
+
+	req := &dto.AlarmDispatchRequest{
+		AlarmID: alert.Identifier,
+		Urgency: capUrgency(info.Urgency, info.Severity),
+		Center:  center,
+		Areas:   areas,
+		Signal:  signal,
+		Content: content,
+	}
+	if info.Expires != "" {
+		expires, err := time.Parse(time.RFC3339, info.Expires)
+		if err != nil {
+			return nil, fmt.Errorf("%w: expires must be RFC 3339", ErrInvalidCAP)
+		}
+		if expires.After(time.Now()) {
+			req.ExpiresAt = &expires
+		}
+	}
+	return req, nil
+}
+
+// capUrgency folds CAP urgency and severity into the four urgency levels of the dispatch API.
+func capUrgency(urgency, severity string) string {
+	severe := severity == "Extreme" || severity == "Severe"
+	switch {
+	case urgency == "Immediate" && severe:
+		return "immediate"
+	case urgency == "Immediate", urgency == "Expected" && severe:
+		return "high"
+	case urgency == "Expected", severe:
+		return "normal"
+	default:
+		return "low"
+	}
+}
+
+// capAreas maps CAP areas onto the dispatch target: the first circle becomes
+// the center and every polygon, as well as any further circle, becomes a
+// GeoJSON Polygon.
+func capAreas(areas []dto.CAPArea) (*dto.AlarmCenter, []dto.GeoJSONGeometry, error) {
+	var center *dto.AlarmCenter
+	var polygons []geo.Polygon
+
+	for _, area := range areas {
+		for _, raw := range area.Circle {
+			c, radius, err := parseCAPCircle(raw)
+			if err != nil {
+				return nil, nil, err
+			}
+			if center == nil {
+				center = &dto.AlarmCenter{Lat: c.Lat, Lng: c.Lng, Radius: int(radius)}
+				continue
+			}
+			polygons = append(polygons, geo.CirclePolygon(c, radius, 32))
+		}
+		for _, raw := range area.Polygon {
+			ring, err := parseCAPPolygon(raw)
+			if err != nil {
+				return nil, nil, err
+			}
+			polygons = append(polygons, geo.Polygon{ring})
+		}
+	}
+
+	if center == nil && len(polygons) == 0 {
+		return nil, nil, fmt.Errorf("%w: an area with a circle or polygon is required", ErrInvalidCAP)
+	}
+
+	geometries := make([]dto.GeoJSONGeometry, 0, len(polygons))
+	for _, polygon := range polygons {
+		coordinates := make([][][]float64, 0, len(polygon))
+		for _, ring := range polygon {
+			positions := make([][]float64, 0, len(ring))
+			for _, p := range ring {
+				positions = append(positions, []float64{p.Lng, p.Lat})
+			}
+			coordinates = append(coordinates, positions)
+		}
+		raw, err := json.Marshal(coordinates)
+		if err != nil {
+			return nil, nil, err
+		}
+		geometries = append(geometries, dto.GeoJSONGeometry{Type: "Polygon", Coordinates: raw})
+	}
+	return center, geometries, nil
+}
+
+// capPolygons renders the outer rings of GeoJSON geometries as CAP polygons.
+// CAP has no notion of holes, so they are dropped.
+func capPolygons(geometries []dto.GeoJSONGeometry) []string {
+	var polygons []string
+	for _, g := range geometries {
+		mp, err := geo.ParseGeoJSON(g.Type, g.Coordinates)
+		if err != nil {
+			continue
+		}
+		for _, polygon := range mp {
+			pairs := make([]string, 0, len(polygon[0]))
+			for _, p := range polygon[0] {
+				pairs = append(pairs, fmt.Sprintf("%g,%g", p.Lat, p.Lng))
+			}
+			polygons = append(polygons, strings.Join(pairs, " "))
+		}
+	}
+	return polygons
+}
+
+func parseCAPCircle(raw string) (geo.Point, float64, error) {
+	fields := strings.Fields(raw)
+	if len(fields) != 2 {
+		return geo.Point{}, 0, fmt.Errorf("%w: malformed circle %q", ErrInvalidCAP, raw)
+	}
+	center, err := parseCAPPoint(fields[0])
+	if err != nil {
+		return geo.Point{}, 0, err
+	}
+	km, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || km < 0 {
+		return geo.Point{}, 0, fmt.Errorf("%w: malformed circle radius %q", ErrInvalidCAP, fields[1])
+	}
+	return center, km * 1000, nil
+}
+
+func parseCAPPolygon(raw string) (geo.Ring, error) {
+	fields := strings.Fields(raw)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("%w: polygon needs at least four points", ErrInvalidCAP)
+	}
+	points := make(geo.Ring, 0, len(fields))
+	for _, field := range fields {
+		p, err := parseCAPPoint(field)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+func parseCAPPoint(raw string) (geo.Point, error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != 2 {
+		return geo.Point{}, fmt.Errorf("%w: malformed coordinate %q", ErrInvalidCAP, raw)
+	}
+	lat, errLat := strconv.ParseFloat(parts[0], 64)
+	lng, errLng := strconv.ParseFloat(parts[1], 64)
+	if errLat != nil || errLng != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return geo.Point{}, fmt.Errorf("%w: malformed coordinate %q", ErrInvalidCAP, raw)
+	}
+	return geo.Point{Lat: lat, Lng: lng}, nil
+}
+
+// capReferencedID returns the identifier of the last alert listed in references.
+// Each reference has the form "sender,identifier,sent".
+func capReferencedID(alert *dto.CAPAlert) (string, error) {
+	refs := strings.Fields(alert.References)
+	if len(refs) == 0 {
+		return "", fmt.Errorf("%w: references are required for %s messages", ErrInvalidCAP, alert.MsgType)
+	}
+	parts := strings.Split(refs[len(refs)-1], ",")
+	if len(parts) != 3 || parts[1] == "" {
+		return "", fmt.Errorf("%w: malformed reference %q", ErrInvalidCAP, refs[len(refs)-1])
+	}
+	return parts[1], nil
+}
@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+// Outcomes of a DeliveryRecord.
+const (
+	DeliverySent   = "sent"
+	DeliveryFailed = "failed"
+)
+
+// What a DeliveryRecord was sent to: a push token, an FCM topic, or a user
+// reached over LINE or SMS.
+const (
+	deliveryTargetToken = "token"
+	deliveryTargetTopic = "topic"
+	deliveryTargetUser  = "user"
+)
+
+// DeliveryLog keeps the outcome of every outbound message per target, with
+// the provider's message ID or error, for delivery reports.
+type DeliveryLog struct {
+	repo repositories.DeliveryRecordRepository
+}
+
+// NewDeliveryLog creates the delivery log. With a nil repository nothing is kept.
+func NewDeliveryLog(repo repositories.DeliveryRecordRepository) *DeliveryLog {
+	return &DeliveryLog{repo: repo}
+}
+
+// deliveryTrail collects the records of one attempt at an outbox message and
+// saves them together. Its methods may be called concurrently.
+type deliveryTrail struct {
+	log     *DeliveryLog
+	base    entities.DeliveryRecord
+	sent    map[string]map[string]bool
+	mu      sync.Mutex
+	records []entities.DeliveryRecord
+}
+
+// trail starts the records of the current attempt at message. On a retry it
+// loads the targets earlier attempts reached, so that they are not sent to
+// twice. If they cannot be loaded, everyone is sent to again rather than
+// risk missing someone.
+func (l *DeliveryLog) trail(ctx context.Context, message *entities.OutboxMessage) *deliveryTrail {
+	t := &deliveryTrail{
+		log: l,
+		base: entities.DeliveryRecord{
+			OutboxMessageID: message.ID,
+			Kind:            message.Kind,
+			Attempt:         message.Attempts,
+		},
+		sent: make(map[string]map[string]bool),
+	}
+	if message.Attempts <= 1 || l == nil || l.repo == nil {
+		return t
+	}
+
+	records, err := l.repo.FindSent(ctx, message.ID)
+	if err != nil {
+		fmt.Printf("Warning: failed to load earlier deliveries of %s: %v\n", message.ID, err)
+		return t
+	}
+	for _, r := range records {
+		if t.sent[r.Provider] == nil {
+			t.sent[r.Provider] = make(map[string]bool)
+		}
+		t.sent[r.Provider][r.Target] = true
+	}
+	return t
+}
+
+// reached tells whether an earlier attempt already sent to target.
+func (t *deliveryTrail) reached(provider, target string) bool {
+	return t.sent[provider][target]
+}
+
+// unsent splits targets into the ones still to send to and the ones an
+// earlier attempt already reached.
+func (t *deliveryTrail) unsent(provider string, targets []string) (pending, reached []string) {
+	if len(t.sent[provider]) == 0 {
+		return targets, nil
+	}
+	pending = make([]string, 0, len(targets))
+	for _, target := range targets {
+		if t.sent[provider][target] {
+			reached = append(reached, target)
+		} else {
+			pending = append(pending, target)
+		}
+	}
+	return pending, reached
+}
+
+// forAlarm marks the records as sends of the alarm with the given message status.
+func (t *deliveryTrail) forAlarm(alarm *entities.Alarm, status string) *deliveryTrail {
+	t.base.AlarmID = &alarm.AlarmID
+	t.base.AlarmStatus = status
+	return t
+}
+
+// push records the outcome of sending to the tokens of one provider. With
+// sendErr set, the whole request failed and every token is recorded failed.
+func (t *deliveryTrail) push(provider string, devices map[string]entities.UserDevice, tokens []string, result *dto.MulticastResponse, sendErr error) {
+	t.add(provider, deliveryTargetToken, tokens, result, sendErr, func(r *entities.DeliveryRecord) {
+		if device, ok := devices[r.Target]; ok {
+			r.UserID = &device.UserID
+			r.DeviceID = &device.ID
+			r.DeviceType = device.DeviceType
+		}
+	})
+}
+
+// line records the outcome of sending to LINE users, owners mapping each LINE
+// user ID to its user.
+func (t *deliveryTrail) line(owners map[string]uuid.UUID, lineUserIDs []string, result *dto.MulticastResponse, sendErr error) {
+	t.add(channelLine, deliveryTargetUser, lineUserIDs, result, sendErr, func(r *entities.DeliveryRecord) {
+		if userID, ok := owners[r.Target]; ok {
+			r.UserID = &userID
+		}
+	})
+}
+
+// topic records the outcome of sending to an FCM topic.
+func (t *deliveryTrail) topic(topic, messageID string, sendErr error) {
+	t.append(t.outcome(t.record(ProviderFCM, deliveryTargetTopic, topic), messageID, sendErr))
+}
+
+// sms records the outcome of texting a user's phone.
+func (t *deliveryTrail) sms(user entities.User, phoneNumber, messageID string, sendErr error) {
+	record := t.record(channelSMS, deliveryTargetUser, phoneNumber)
+	record.UserID = &user.ID
+	t.append(t.outcome(record, messageID, sendErr))
+}
+
+// outcome completes a record of a single send.
+func (t *deliveryTrail) outcome(record entities.DeliveryRecord, messageID string, sendErr error) entities.DeliveryRecord {
+	now := time.Now()
+	if sendErr != nil {
+		record.Status = DeliveryFailed
+		record.ErrorReason = sendErr.Error()
+		record.FailedAt = &now
+	} else {
+		record.Status = DeliverySent
+		record.ProviderMessageID = messageID
+		record.SentAt = &now
+	}
+	return record
+}
+
+func (t *deliveryTrail) add(provider, targetType string, targets []string, result *dto.MulticastResponse, sendErr error, describe func(r *entities.DeliveryRecord)) {
+	now := time.Now()
+	records := make([]entities.DeliveryRecord, 0, len(targets))
+	if sendErr != nil || result == nil {
+		reason := "no result"
+		if sendErr != nil {
+			reason = sendErr.Error()
+		}
+		for _, target := range targets {
+			record := t.record(provider, targetType, target)
+			record.Status = DeliveryFailed
+			record.ErrorReason = reason
+			record.FailedAt = &now
+			describe(&record)
+			records = append(records, record)
+		}
+		t.append(records...)
+		return
+	}
+
+	for i, target := range result.SuccessTokens {
+		record := t.record(provider, targetType, target)
+		record.Status = DeliverySent
+		record.SentAt = &now
+		if i < len(result.MessageIDs) {
+			record.ProviderMessageID = result.MessageIDs[i]
+		}
+		describe(&record)
+		records = append(records, record)
+	}
+	for _, failure := range result.FailureTokens {
+		record := t.record(provider, targetType, failure.Token)
+		record.Status = DeliveryFailed
+		record.ErrorCode = failure.Code
+		record.ErrorReason = failure.Reason
+		record.FailedAt = &now
+		describe(&record)
+		records = append(records, record)
+	}
+	t.append(records...)
+}
+
+func (t *deliveryTrail) record(provider, targetType, target string) entities.DeliveryRecord {
+	record := t.base
+	record.Provider = provider
+	record.TargetType = targetType
+	record.Target = target
+	return record
+}
+
+func (t *deliveryTrail) append(records ...entities.DeliveryRecord) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.records = append(t.records, records...)
+}
+
+// flush saves the collected records. Failures are logged so they never hold
+// back the send itself.
+func (t *deliveryTrail) flush(ctx context.Context) {
+	t.mu.Lock()
+	records := t.records
+	t.records = nil
+	t.mu.Unlock()
+
+	if t.log == nil || t.log.repo == nil || len(records) == 0 {
+		return
+	}
+	if err := t.log.repo.CreateBatch(ctx, records); err != nil {
+		fmt.Printf("Warning: failed to record %d deliveries of %s: %v\n", len(records), t.base.OutboxMessageID, err)
+	}
+}
+
+// indexByToken indexes devices by their push token.
+func indexByToken(devices []entities.UserDevice) map[string]entities.UserDevice {
+	byToken := make(map[string]entities.UserDevice, len(devices))
+	for _, d := range devices {
+		byToken[d.PushToken] = d
+	}
+	return byToken
+}
+
+// lineOwners maps each LINE user ID to the user it belongs to.
+func lineOwners(recipients []dto.LineRecipient) map[string]uuid.UUID {
+	owners := make(map[string]uuid.UUID, len(recipients))
+	for _, r := range recipients {
+		owners[r.LineUserID] = r.UserID
+	}
+	return owners
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+
+	"github.com/google/uuid"
+)
+
+// stubDeliveryRecordRepo serves the targets earlier attempts reached.
+type stubDeliveryRecordRepo struct {
+	repositories.DeliveryRecordRepository
+	sent  []entities.DeliveryRecord
+	err   error
+	calls int
+}
+
+func (r *stubDeliveryRecordRepo) FindSent(ctx context.Context, outboxMessageID uuid.UUID) ([]entities.DeliveryRecord, error) {
+	r.calls++
+	return r.sent, r.err
+}
+
+func TestDeliveryTrailSkipsReachedTargets(t *testing.T) {
+	repo := &stubDeliveryRecordRepo{sent: []entities.DeliveryRecord{
+		{Provider: ProviderFCM, Target: "token-1"},
+		{Provider: ProviderFCM, Target: BroadcastTopic},
+		{Provider: channelSMS, Target: "+66800000001"},
+	}}
+	log := NewDeliveryLog(repo)
+
+	first := log.trail(context.Background(), &entities.OutboxMessage{ID: uuid.New(), Attempts: 1})
+	if repo.calls != 0 {
+		t.Fatalf("first attempt loaded earlier deliveries %d times, want none", repo.calls)
+	}
+	if pending, reached := first.unsent(ProviderFCM, []string{"token-1", "token-2"}); len(pending) != 2 || len(reached) != 0 {
+		t.Fatalf("first attempt: pending %v, reached %v; want everyone pending", pending, reached)
+	}
+
+	retry := log.trail(context.Background(), &entities.OutboxMessage{ID: uuid.New(), Attempts: 2})
+	pending, reached := retry.unsent(ProviderFCM, []string{"token-1", "token-2", "token-3"})
+	if fmt.Sprint(pending) != "[token-2 token-3]" || fmt.Sprint(reached) != "[token-1]" {
+		t.Fatalf("retry: pending %v, reached %v; want token-1 skipped", pending, reached)
+	}
+	if pending, _ := retry.unsent(ProviderAPNs, []string{"token-1"}); len(pending) != 1 {
+		t.Fatal("a token reached on one provider is skipped on another")
+	}
+	if !retry.reached(ProviderFCM, BroadcastTopic) || !retry.reached(channelSMS, "+66800000001") {
+		t.Fatal("retry sends to the topic or phone again")
+	}
+}
+
+func TestDeliveryTrailSendsToEveryoneWithoutRecords(t *testing.T) {
+	repo := &stubDeliveryRecordRepo{err: errors.New("connection refused")}
+	trail := NewDeliveryLog(repo).trail(context.Background(), &entities.OutboxMessage{ID: uuid.New(), Attempts: 3})
+	if pending, _ := trail.unsent(ProviderFCM, []string{"token-1"}); len(pending) != 1 {
+		t.Fatalf("pending %v, want everyone sent to again when records cannot be loaded", pending)
+	}
+}
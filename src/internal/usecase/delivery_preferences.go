@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/config"
+
+	"github.com/google/uuid"
+)
+
+// Delivery channels a user can opt out of.
+const (
+	channelPush = "push"
+	channelLine = "line"
+	channelSMS  = "sms"
+)
+
+const quietHoursLayout = "15:04"
+
+// DeliveryPreferences applies users' notification preferences to the
+// recipients of a message. Preferences only decide what interrupts a user;
+// the message is still kept in their inbox.
+type DeliveryPreferences struct {
+	repo            repositories.NotificationPreferenceRepository
+	defaultTimeZone *time.Location
+}
+
+// NewDeliveryPreferences creates the preference filter. Quiet hours without a
+// time zone are read in DEFAULT_TIMEZONE.
+func NewDeliveryPreferences(repo repositories.NotificationPreferenceRepository, cfg *config.Config) *DeliveryPreferences {
+	location, err := time.LoadLocation(cfg.DefaultTimeZone)
+	if err != nil {
+		fmt.Printf("Warning: unknown DEFAULT_TIMEZONE %q, using UTC: %v\n", cfg.DefaultTimeZone, err)
+		location = time.UTC
+	}
+	return &DeliveryPreferences{repo: repo, defaultTimeZone: location}
+}
+
+// deliveryFilter decides, for one message, which recipients receive it.
+// Preferences are loaded as users are first seen and kept for the message,
+// as are the time zones of their quiet hours.
+type deliveryFilter struct {
+	preferences *DeliveryPreferences
+	hazardType  string
+	bypassQuiet bool
+	now         time.Time
+	loaded      map[uuid.UUID]*entities.NotificationPreference
+	locations   map[string]*time.Location
+}
+
+// forBroadcast returns the filter for a broadcast or targeted notification.
+func (p *DeliveryPreferences) forBroadcast() *deliveryFilter {
+	return p.filter("", false)
+}
+
+// forAlarm returns the filter for an alarm. Immediate alarms are delivered
+// during quiet hours; channel and hazard opt-outs still apply to them.
+func (p *DeliveryPreferences) forAlarm(alarm *entities.Alarm) *deliveryFilter {
+	return p.filter(alarm.HazardType, alarm.Urgency == "immediate")
+}
+
+func (p *DeliveryPreferences) filter(hazardType string, bypassQuiet bool) *deliveryFilter {
+	return &deliveryFilter{
+		preferences: p,
+		hazardType:  hazardType,
+		bypassQuiet: bypassQuiet,
+		now:         time.Now(),
+		loaded:      make(map[uuid.UUID]*entities.NotificationPreference),
+		locations:   make(map[string]*time.Location),
+	}
+}
+
+// devices keeps the devices whose owners take push for this message.
+func (f *deliveryFilter) devices(ctx context.Context, devices []entities.UserDevice) ([]entities.UserDevice, error) {
+	if err := f.load(ctx, deviceOwners(devices)); err != nil {
+		return nil, err
+	}
+	kept := make([]entities.UserDevice, 0, len(devices))
+	for _, d := range devices {
+		if f.allows(d.UserID, channelPush) {
+			kept = append(kept, d)
+		}
+	}
+	return kept, nil
+}
+
+// lineRecipients keeps the LINE recipients who take this message over LINE.
+func (f *deliveryFilter) lineRecipients(ctx context.Context, recipients []dto.LineRecipient) ([]dto.LineRecipient, error) {
+	userIDs := make([]uuid.UUID, 0, len(recipients))
+	for _, r := range recipients {
+		userIDs = append(userIDs, r.UserID)
+	}
+	if err := f.load(ctx, userIDs); err != nil {
+		return nil, err
+	}
+	kept := make([]dto.LineRecipient, 0, len(recipients))
+	for _, r := range recipients {
+		if f.allows(r.UserID, channelLine) {
+			kept = append(kept, r)
+		}
+	}
+	return kept, nil
+}
+
+// smsUsers keeps the users who take this message by SMS.
+func (f *deliveryFilter) smsUsers(ctx context.Context, users []entities.User) ([]entities.User, error) {
+	userIDs := make([]uuid.UUID, 0, len(users))
+	for _, u := range users {
+		userIDs = append(userIDs, u.ID)
+	}
+	if err := f.load(ctx, userIDs); err != nil {
+		return nil, err
+	}
+	kept := make([]entities.User, 0, len(users))
+	for _, u := range users {
+		if f.allows(u.ID, channelSMS) {
+			kept = append(kept, u)
+		}
+	}
+	return kept, nil
+}
+
+func (f *deliveryFilter) load(ctx context.Context, userIDs []uuid.UUID) error {
+	missing := make([]uuid.UUID, 0, len(userIDs))
+	for _, id := range userIDs {
+		if _, ok := f.loaded[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	stored, err := f.preferences.repo.FindByUserIDs(ctx, missing)
+	if err != nil {
+		return fmt.Errorf("failed to load notification preferences: %v", err)
+	}
+	for _, id := range missing {
+		f.loaded[id] = nil
+	}
+	for i := range stored {
+		f.loaded[stored[i].UserID] = &stored[i]
+	}
+	return nil
+}
+
+// allows reports whether the user takes this message on channel. Users
+// without stored preferences get the defaults.
+func (f *deliveryFilter) allows(userID uuid.UUID, channel string) bool {
+	preference := f.loaded[userID]
+	if preference == nil {
+		preference = defaultPreference(userID)
+	}
+
+	switch channel {
+	case channelPush:
+		if !preference.PushEnabled {
+			return false
+		}
+	case channelLine:
+		if !preference.LineEnabled {
+			return false
+		}
+	case channelSMS:
+		if !preference.SMSEnabled {
+			return false
+		}
+	}
+	if f.hazardType != "" && slices.Contains(preference.MutedHazards, f.hazardType) {
+		return false
+	}
+	return f.bypassQuiet || !inQuietHours(preference, f.now.In(f.location(preference.TimeZone)))
+}
+
+// location returns the named time zone, or the default one when name is
+// empty or unknown.
+func (f *deliveryFilter) location(name string) *time.Location {
+	if name == "" {
+		return f.preferences.defaultTimeZone
+	}
+	if location, ok := f.locations[name]; ok {
+		return location
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		location = f.preferences.defaultTimeZone
+	}
+	f.locations[name] = location
+	return location
+}
+
+// defaultPreference is the preference of a user who never changed theirs.
+func defaultPreference(userID uuid.UUID) *entities.NotificationPreference {
+	return &entities.NotificationPreference{UserID: userID, PushEnabled: true, SMSEnabled: true}
+}
+
+// inQuietHours reports whether local, the time in the user's time zone, falls
+// in their quiet hours. The window includes its start and excludes its end,
+// and wraps past midnight when it starts later than it ends.
+func inQuietHours(preference *entities.NotificationPreference, local time.Time) bool {
+	if preference.QuietStart == "" || preference.QuietEnd == "" {
+		return false
+	}
+	start, err := time.Parse(quietHoursLayout, preference.QuietStart)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse(quietHoursLayout, preference.QuietEnd)
+	if err != nil {
+		return false
+	}
+
+	minute := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+
+	if from <= to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/pkg/config"
+
+	"github.com/google/uuid"
+)
+
+// stubPreferenceRepo serves stored preferences from memory.
+type stubPreferenceRepo struct {
+	repositories.NotificationPreferenceRepository
+	stored map[uuid.UUID]entities.NotificationPreference
+	calls  int
+}
+
+func (r *stubPreferenceRepo) FindByUserIDs(ctx context.Context, userIDs []uuid.UUID) ([]entities.NotificationPreference, error) {
+	r.calls++
+	found := make([]entities.NotificationPreference, 0, len(userIDs))
+	for _, id := range userIDs {
+		if p, ok := r.stored[id]; ok {
+			found = append(found, p)
+		}
+	}
+	return found, nil
+}
+
+func TestInQuietHours(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 18, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name       string
+		start, end string
+		local      time.Time
+		want       bool
+	}{
+		{"off", "", "", at(23, 0), false},
+		{"malformed", "25:00", "07:00", at(23, 0), false},
+		{"inside same-day window", "13:00", "15:00", at(14, 0), true},
+		{"before same-day window", "13:00", "15:00", at(12, 59), false},
+		{"start is inside", "13:00", "15:00", at(13, 0), true},
+		{"end is outside", "13:00", "15:00", at(15, 0), false},
+		{"wrapping window before midnight", "22:00", "07:00", at(23, 30), true},
+		{"wrapping window after midnight", "22:00", "07:00", at(6, 59), true},
+		{"outside wrapping window", "22:00", "07:00", at(12, 0), false},
+		{"wrapping window end is outside", "22:00", "07:00", at(7, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preference := &entities.NotificationPreference{QuietStart: tt.start, QuietEnd: tt.end}
+			if got := inQuietHours(preference, tt.local); got != tt.want {
+				t.Fatalf("inQuietHours(%s-%s, %s) = %v, want %v", tt.start, tt.end, tt.local.Format("15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliveryFilter(t *testing.T) {
+	var (
+		defaults     = uuid.New()
+		pushOff      = uuid.New()
+		floodMuted   = uuid.New()
+		quietBangkok = uuid.New()
+		quietDefault = uuid.New()
+		quietUnknown = uuid.New()
+		lineOn       = uuid.New()
+	)
+	repo := &stubPreferenceRepo{stored: map[uuid.UUID]entities.NotificationPreference{
+		pushOff:      {UserID: pushOff, PushEnabled: false, SMSEnabled: true},
+		floodMuted:   {UserID: floodMuted, PushEnabled: true, SMSEnabled: true, MutedHazards: []string{"flood"}},
+		quietBangkok: {UserID: quietBangkok, PushEnabled: true, SMSEnabled: true, QuietStart: "22:00", QuietEnd: "07:00", TimeZone: "Asia/Bangkok"},
+		quietDefault: {UserID: quietDefault, PushEnabled: true, SMSEnabled: true, QuietStart: "22:00", QuietEnd: "07:00"},
+		quietUnknown: {UserID: quietUnknown, PushEnabled: true, SMSEnabled: true, QuietStart: "22:00", QuietEnd: "07:00", TimeZone: "Mars/Olympus"},
+		lineOn:       {UserID: lineOn, PushEnabled: true, LineEnabled: true, SMSEnabled: true},
+	}}
+	// 16:30 UTC is 23:30 in Bangkok and 16:30 in the UTC default time zone.
+	preferences := NewDeliveryPreferences(repo, &config.Config{DefaultTimeZone: "UTC"})
+	now := time.Date(2026, 10, 18, 16, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		filter  *deliveryFilter
+		userID  uuid.UUID
+		channel string
+		want    bool
+	}{
+		{"defaults take push", preferences.forBroadcast(), defaults, channelPush, true},
+		{"defaults take SMS", preferences.forBroadcast(), defaults, channelSMS, true},
+		{"defaults do not take LINE", preferences.forBroadcast(), defaults, channelLine, false},
+		{"LINE when enabled", preferences.forBroadcast(), lineOn, channelLine, true},
+		{"push turned off", preferences.forBroadcast(), pushOff, channelPush, false},
+		{"push turned off still takes SMS", preferences.forBroadcast(), pushOff, channelSMS, true},
+		{"muted hazard", preferences.forAlarm(&entities.Alarm{HazardType: "flood", Urgency: "high"}), floodMuted, channelPush, false},
+		{"other hazard", preferences.forAlarm(&entities.Alarm{HazardType: "fire", Urgency: "high"}), floodMuted, channelPush, true},
+		{"broadcast has no hazard", preferences.forBroadcast(), floodMuted, channelPush, true},
+		{"quiet hours in own time zone", preferences.forBroadcast(), quietBangkok, channelPush, false},
+		{"quiet hours in default time zone", preferences.forBroadcast(), quietDefault, channelPush, true},
+		{"unknown time zone uses default", preferences.forBroadcast(), quietUnknown, channelPush, true},
+		{"high alarm respects quiet hours", preferences.forAlarm(&entities.Alarm{Urgency: "high"}), quietBangkok, channelPush, false},
+		{"immediate alarm bypasses quiet hours", preferences.forAlarm(&entities.Alarm{Urgency: "immediate"}), quietBangkok, channelPush, true},
+		{"immediate alarm respects hazard mute", preferences.forAlarm(&entities.Alarm{HazardType: "flood", Urgency: "immediate"}), floodMuted, channelPush, false},
+		{"immediate alarm respects channel opt-out", preferences.forAlarm(&entities.Alarm{Urgency: "immediate"}), pushOff, channelPush, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.now = now
+			if err := tt.filter.load(context.Background(), []uuid.UUID{tt.userID}); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.filter.allows(tt.userID, tt.channel); got != tt.want {
+				t.Fatalf("allows(%s) = %v, want %v", tt.channel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliveryFilterCaches(t *testing.T) {
+	quiet := uuid.New()
+	repo := &stubPreferenceRepo{stored: map[uuid.UUID]entities.NotificationPreference{
+		quiet: {UserID: quiet, PushEnabled: true, QuietStart: "22:00", QuietEnd: "07:00", TimeZone: "Asia/Bangkok"},
+	}}
+	filter := NewDeliveryPreferences(repo, &config.Config{DefaultTimeZone: "Asia/Bangkok"}).forBroadcast()
+	filter.now = time.Date(2026, 10, 18, 4, 0, 0, 0, time.UTC) // 11:00 in Bangkok
+
+	devices := []entities.UserDevice{{UserID: quiet}, {UserID: uuid.New()}}
+	for range 3 {
+		kept, err := filter.devices(context.Background(), devices)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(kept) != 2 {
+			t.Fatalf("kept %d devices, want 2 outside quiet hours", len(kept))
+		}
+	}
+	if repo.calls != 1 {
+		t.Fatalf("preferences loaded %d times, want once per user", repo.calls)
+	}
+	if len(filter.locations) != 1 || filter.locations["Asia/Bangkok"] == nil {
+		t.Fatalf("cached locations = %v, want Asia/Bangkok only", filter.locations)
+	}
+	if filter.location("") != filter.preferences.defaultTimeZone {
+		t.Fatal("empty time zone does not resolve to the default")
+	}
+}
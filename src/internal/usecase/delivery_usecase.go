@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+)
+
+// DeliveryUsecase reports how well alarms and notifications were delivered.
+type DeliveryUsecase interface {
+	Summary(ctx context.Context, filter dto.DeliveryFilter) (*dto.DeliverySummaryResponse, error)
+}
+
+type deliveryUsecase struct {
+	repo repositories.DeliveryRecordRepository
+}
+
+func NewDeliveryUsecase(repo repositories.DeliveryRecordRepository) DeliveryUsecase {
+	return &deliveryUsecase{repo: repo}
+}
+
+// Summary gives the delivery success rates of the records matching filter per
+// alarm, per broadcast or targeted notification, and per platform. Retries
+// count as attempts; a target is delivered once any attempt reached it.
+func (u *deliveryUsecase) Summary(ctx context.Context, filter dto.DeliveryFilter) (*dto.DeliverySummaryResponse, error) {
+	resp := &dto.DeliverySummaryResponse{
+		Alarms:     make([]dto.AlarmDeliveryStats, 0),
+		Broadcasts: make([]dto.BroadcastDeliveryStats, 0),
+		Platforms:  make([]dto.PlatformDeliveryStats, 0),
+	}
+
+	platforms, err := u.repo.Summarize(ctx, filter, dto.DeliveryGroupPlatform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize deliveries: %v", err)
+	}
+	var overall dto.DeliveryStatsRow
+	for _, row := range platforms {
+		resp.Platforms = append(resp.Platforms, dto.PlatformDeliveryStats{
+			Provider:      row.Provider,
+			DeviceType:    row.DeviceType,
+			DeliveryStats: deliveryStats(row),
+		})
+		overall.Attempts += row.Attempts
+		overall.Targets += row.Targets
+		overall.Delivered += row.Delivered
+	}
+	resp.Overall = deliveryStats(overall)
+
+	alarms, err := u.repo.Summarize(ctx, filter, dto.DeliveryGroupAlarm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize alarm deliveries: %v", err)
+	}
+	for _, row := range alarms {
+		if row.AlarmID == nil {
+			continue
+		}
+		resp.Alarms = append(resp.Alarms, dto.AlarmDeliveryStats{
+			AlarmID:       *row.AlarmID,
+			DeliveryStats: deliveryStats(row),
+		})
+	}
+
+	if filter.AlarmID == "" {
+		broadcastFilter := filter
+		broadcastFilter.Kinds = []string{OutboxKindBroadcast, OutboxKindTargeted}
+		broadcasts, err := u.repo.Summarize(ctx, broadcastFilter, dto.DeliveryGroupMessage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize broadcast deliveries: %v", err)
+		}
+		for _, row := range broadcasts {
+			if row.OutboxMessageID == nil {
+				continue
+			}
+			resp.Broadcasts = append(resp.Broadcasts, dto.BroadcastDeliveryStats{
+				TrackingID:    *row.OutboxMessageID,
+				Kind:          row.Kind,
+				DeliveryStats: deliveryStats(row),
+			})
+		}
+	}
+	return resp, nil
+}
+
+func deliveryStats(row dto.DeliveryStatsRow) dto.DeliveryStats {
+	stats := dto.DeliveryStats{
+		Targets:   row.Targets,
+		Delivered: row.Delivered,
+		Failed:    row.Targets - row.Delivered,
+		Attempts:  row.Attempts,
+	}
+	if row.Targets > 0 {
+		stats.SuccessRate = float64(row.Delivered) / float64(row.Targets)
+	}
+	return stats
+}
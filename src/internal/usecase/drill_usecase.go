@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"context"
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+// DrillUsecase manages the drill group that receives drill alarms.
+type DrillUsecase interface {
+	Enroll(ctx context.Context, req dto.DrillEnrollmentRequest, enrolledBy *uuid.UUID) (*entities.DrillEnrollment, error)
+	Unenroll(ctx context.Context, id uuid.UUID) error
+	FindAll(ctx context.Context) ([]entities.DrillEnrollment, error)
+}
+
+type drillUsecase struct {
+	repo repositories.DrillEnrollmentRepository
+}
+
+func NewDrillUsecase(repo repositories.DrillEnrollmentRepository) DrillUsecase {
+	return &drillUsecase{repo: repo}
+}
+
+func (u *drillUsecase) Enroll(ctx context.Context, req dto.DrillEnrollmentRequest, enrolledBy *uuid.UUID) (*entities.DrillEnrollment, error) {
+	enrollment := &entities.DrillEnrollment{
+		UserID:     req.UserID,
+		DeviceID:   req.DeviceID,
+		EnrolledBy: enrolledBy,
+	}
+	if err := u.repo.Create(ctx, enrollment); err != nil {
+		return nil, err
+	}
+	return enrollment, nil
+}
+
+func (u *drillUsecase) Unenroll(ctx context.Context, id uuid.UUID) error {
+	return u.repo.Delete(ctx, id)
+}
+
+func (u *drillUsecase) FindAll(ctx context.Context) ([]entities.DrillEnrollment, error) {
+	return u.repo.FindAll(ctx)
+}
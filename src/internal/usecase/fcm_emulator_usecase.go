@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+)
+
+// FCMEmulatorUsecase inspects and controls the FCM emulator used in place of
+// FCM in development and integration tests.
+type FCMEmulatorUsecase interface {
+	Inspect(query dto.FCMEmulatorQuery) *dto.FCMEmulatorState
+	Unregister(tokens []string)
+	Reset() error
+}
+
+type fcmEmulatorUsecase struct {
+	emulator repositories.FCMInspector
+}
+
+func NewFCMEmulatorUsecase(emulator repositories.FCMInspector) FCMEmulatorUsecase {
+	return &fcmEmulatorUsecase{emulator: emulator}
+}
+
+// Inspect returns the calls matching query and the current topic subscriptions.
+func (u *fcmEmulatorUsecase) Inspect(query dto.FCMEmulatorQuery) *dto.FCMEmulatorState {
+	return &dto.FCMEmulatorState{
+		Calls:  u.emulator.Calls(query),
+		Topics: u.emulator.Topics(),
+	}
+}
+
+func (u *fcmEmulatorUsecase) Unregister(tokens []string) {
+	u.emulator.Unregister(tokens)
+}
+
+func (u *fcmEmulatorUsecase) Reset() error {
+	return u.emulator.Reset()
+}
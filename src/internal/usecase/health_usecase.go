@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/breaker"
+)
+
+// HealthUsecase reports the health of the API and its guarded dependencies.
+type HealthUsecase interface {
+	Status() *dto.HealthResponse
+}
+
+type healthUsecase struct {
+	breakers []repositories.CircuitBreakerReporter
+}
+
+func NewHealthUsecase(breakers ...repositories.CircuitBreakerReporter) HealthUsecase {
+	return &healthUsecase{breakers: breakers}
+}
+
+// Status is degraded while any breaker is not closed: the API still serves
+// requests, but sends through that dependency are held back.
+func (u *healthUsecase) Status() *dto.HealthResponse {
+	resp := &dto.HealthResponse{
+		Status:   dto.HealthOK,
+		Breakers: make([]dto.BreakerStatus, 0, len(u.breakers)),
+	}
+	for _, b := range u.breakers {
+		status := b.BreakerStatus()
+		if status.State != breaker.StateClosed {
+			resp.Status = dto.HealthDegraded
+		}
+		resp.Breakers = append(resp.Breakers, status)
+	}
+	return resp
+}
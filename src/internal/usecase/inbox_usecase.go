@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const defaultInboxLimit = 20
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrInvalidCursor        = errors.New("invalid cursor")
+)
+
+// InboxUsecase reads and updates the signed-in user's notification inbox.
+type InboxUsecase interface {
+	List(ctx context.Context, userID uuid.UUID, query dto.InboxQuery) (*dto.InboxResponse, error)
+	MarkRead(ctx context.Context, userID, id uuid.UUID) error
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	UnreadCount(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+type inboxUsecase struct {
+	repo repositories.UserNotificationRepository
+}
+
+func NewInboxUsecase(repo repositories.UserNotificationRepository) InboxUsecase {
+	return &inboxUsecase{repo: repo}
+}
+
+// List returns a page of the inbox, newest first. NextCursor is set when
+// there may be older notifications.
+func (u *inboxUsecase) List(ctx context.Context, userID uuid.UUID, query dto.InboxQuery) (*dto.InboxResponse, error) {
+	filter := dto.InboxFilter{UnreadOnly: query.Unread, Limit: query.Limit}
+	if filter.Limit == 0 {
+		filter.Limit = defaultInboxLimit
+	}
+	if query.Cursor != "" {
+		before, id, err := decodeInboxCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.Before = &before
+		filter.BeforeID = id
+	}
+
+	notifications, err := u.repo.FindByUser(ctx, userID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notifications: %v", err)
+	}
+	unread, err := u.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread notifications: %v", err)
+	}
+
+	resp := &dto.InboxResponse{Notifications: notifications, UnreadCount: unread}
+	if len(notifications) == filter.Limit {
+		last := notifications[len(notifications)-1]
+		resp.NextCursor = encodeInboxCursor(last.CreatedAt, last.ID)
+	}
+	return resp, nil
+}
+
+func (u *inboxUsecase) MarkRead(ctx context.Context, userID, id uuid.UUID) error {
+	err := u.repo.MarkRead(ctx, userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotificationNotFound
+	}
+	return err
+}
+
+func (u *inboxUsecase) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return u.repo.MarkAllRead(ctx, userID)
+}
+
+func (u *inboxUsecase) UnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return u.repo.CountUnread(ctx, userID)
+}
+
+// encodeInboxCursor makes an opaque cursor pointing just past a notification.
+func encodeInboxCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := strconv.FormatInt(createdAt.UnixMicro(), 10) + "_" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeInboxCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	micros, idPart, ok := strings.Cut(string(raw), "_")
+	if !ok {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	us, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	id, err := uuid.Parse(idPart)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	return time.UnixMicro(us), id, nil
+}
+
+// inboxNotifications builds one inbox entry per user, each in the locale of
+// one of their devices when there is text for it.
+func inboxNotifications(kind string, userIDs []uuid.UUID, devices []entities.UserDevice, titles, bodies map[string]string, defaultLocale string) []entities.UserNotification {
+	locales := userLocales(devices)
+	notifications := make([]entities.UserNotification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		locale := localeFor(locales[userID], titles, defaultLocale)
+		body, ok := bodies[locale]
+		if !ok {
+			body = bodies[defaultLocale]
+		}
+		notifications = append(notifications, entities.UserNotification{
+			UserID: userID,
+			Kind:   kind,
+			Title:  titles[locale],
+			Body:   body,
+			Locale: locale,
+		})
+	}
+	return notifications
+}
+
+// deviceOwners returns the distinct users owning devices.
+func deviceOwners(devices []entities.UserDevice) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool)
+	userIDs := make([]uuid.UUID, 0)
+	for _, d := range devices {
+		if !seen[d.UserID] {
+			seen[d.UserID] = true
+			userIDs = append(userIDs, d.UserID)
+		}
+	}
+	return userIDs
+}
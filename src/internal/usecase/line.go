@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+// LineChannel sends alarms and broadcasts as LINE messages to users who signed
+// in with LINE and enabled LINE delivery. The LINE Login channel and the
+// Messaging API channel must belong to the same LINE provider for the stored
+// user IDs to be valid recipients.
+type LineChannel struct {
+	sender      repositories.PushSender
+	preferences repositories.NotificationPreferenceRepository
+}
+
+// NewLineChannel creates the LINE channel. With a nil sender nothing is sent.
+func NewLineChannel(sender repositories.PushSender, preferences repositories.NotificationPreferenceRepository) *LineChannel {
+	return &LineChannel{sender: sender, preferences: preferences}
+}
+
+func (l *LineChannel) enabled() bool {
+	return l != nil && l.sender != nil
+}
+
+// recipientsOf returns the opted-in LINE recipients among the owners of devices.
+func (l *LineChannel) recipientsOf(ctx context.Context, devices []entities.UserDevice) ([]dto.LineRecipient, error) {
+	recipients, err := l.preferences.FindLineRecipients(ctx, deviceOwners(devices))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load LINE recipients: %v", err)
+	}
+	return recipients, nil
+}
+
+// recipientsAmong returns the opted-in LINE recipients among users.
+func (l *LineChannel) recipientsAmong(ctx context.Context, userIDs []uuid.UUID) ([]dto.LineRecipient, error) {
+	recipients, err := l.preferences.FindLineRecipients(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load LINE recipients: %v", err)
+	}
+	return recipients, nil
+}
+
+// lineBatches groups LINE user IDs by the locale each user is sent: that of
+// one of their devices, or the default locale for users without one.
+func lineBatches(recipients []dto.LineRecipient, devices []entities.UserDevice, texts map[string]string, defaultLocale string) map[string][]string {
+	locales := userLocales(devices)
+	batches := make(map[string][]string)
+	for _, r := range recipients {
+		locale := localeFor(locales[r.UserID], texts, defaultLocale)
+		batches[locale] = append(batches[locale], r.LineUserID)
+	}
+	return batches
+}
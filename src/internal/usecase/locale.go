@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"pbmap_api/src/internal/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidContent = errors.New("invalid localized content")
+
+// normalizeLocale lower-cases a locale tag and uses "-" between its parts.
+func normalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+}
+
+// localeFor picks the locale a device is sent: its own locale, then its
+// language without the region, then the default locale.
+func localeFor(deviceLocale string, texts map[string]string, defaultLocale string) string {
+	locale := normalizeLocale(deviceLocale)
+	if locale == "" {
+		return defaultLocale
+	}
+	if _, ok := texts[locale]; ok {
+		return locale
+	}
+	if i := strings.Index(locale, "-"); i > 0 {
+		if _, ok := texts[locale[:i]]; ok {
+			return locale[:i]
+		}
+	}
+	return defaultLocale
+}
+
+// tokensByLocale groups push tokens by the locale each device is sent.
+func tokensByLocale(devices []entities.UserDevice, texts map[string]string, defaultLocale string) map[string][]string {
+	batches := make(map[string][]string)
+	for _, d := range devices {
+		locale := localeFor(d.Locale, texts, defaultLocale)
+		batches[locale] = append(batches[locale], d.PushToken)
+	}
+	return batches
+}
+
+// sortedLocales returns the locales of a batch map in a stable order.
+func sortedLocales(batches map[string][]string) []string {
+	locales := make([]string, 0, len(batches))
+	for locale := range batches {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// userLocales returns the locale of one device of each user who set one.
+func userLocales(devices []entities.UserDevice) map[uuid.UUID]string {
+	locales := make(map[uuid.UUID]string)
+	for _, d := range devices {
+		if _, ok := locales[d.UserID]; !ok && d.Locale != "" {
+			locales[d.UserID] = d.Locale
+		}
+	}
+	return locales
+}
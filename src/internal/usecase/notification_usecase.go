@@ -2,35 +2,382 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
+	"pbmap_api/src/internal/domain/entities"
 	"pbmap_api/src/internal/domain/repositories"
 	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/config"
+
+	"github.com/google/uuid"
 )
 
+// BroadcastTopic is the FCM topic of devices that are not signed in, which
+// broadcasts reach without a device record. Signed-in devices leave it and are
+// sent to one by one, in their locale and under their owner's preferences.
+const BroadcastTopic = "all_devices"
+
+// broadcastPageSize is how many users a broadcast loads at a time.
+const broadcastPageSize = 1000
+
 // NotificationUsecase orchestrates notification (broadcast, subscribe, unsubscribe).
 type NotificationUsecase interface {
-	Broadcast(ctx context.Context, title, body string) error
+	Broadcast(ctx context.Context, req *dto.BroadcastRequest) (*dto.DispatchReceipt, error)
+	SendToUsers(ctx context.Context, req *dto.TargetedNotificationRequest) (*dto.DispatchReceipt, error)
+	DeliverQueued(ctx context.Context, message *entities.OutboxMessage) (interface{}, error)
 	SubscribeToTopic(ctx context.Context, tokens []string, topic string) (*dto.TopicManagementResponse, error)
 	UnsubscribeFromTopic(ctx context.Context, tokens []string, topic string) (*dto.TopicManagementResponse, error)
 }
 
 type notificationUsecase struct {
-	fcm repositories.FCMRepository
+	push          *PushRouter
+	deviceRepo    repositories.DeviceRepository
+	userRepo      repositories.UserRepository
+	ppRepo        repositories.PotentialPointRepository
+	pruner        TokenPruner
+	line          *LineChannel
+	inbox         repositories.UserNotificationRepository
+	preferences   *DeliveryPreferences
+	outbox        *Outbox
+	deliveries    *DeliveryLog
+	defaultLocale string
 }
 
 // NewNotificationUsecase creates the notification usecase.
-func NewNotificationUsecase(fcm repositories.FCMRepository) NotificationUsecase {
-	return &notificationUsecase{fcm: fcm}
+func NewNotificationUsecase(push *PushRouter, deviceRepo repositories.DeviceRepository, userRepo repositories.UserRepository, ppRepo repositories.PotentialPointRepository, pruner TokenPruner, line *LineChannel, inbox repositories.UserNotificationRepository, preferences *DeliveryPreferences, outbox *Outbox, deliveries *DeliveryLog, cfg *config.Config) NotificationUsecase {
+	return &notificationUsecase{
+		push:          push,
+		deviceRepo:    deviceRepo,
+		userRepo:      userRepo,
+		ppRepo:        ppRepo,
+		pruner:        pruner,
+		line:          line,
+		inbox:         inbox,
+		preferences:   preferences,
+		outbox:        outbox,
+		deliveries:    deliveries,
+		defaultLocale: cfg.DefaultLocale,
+	}
+}
+
+// Broadcast queues a notification for every device and, over LINE, every
+// user who enabled it.
+func (u *notificationUsecase) Broadcast(ctx context.Context, req *dto.BroadcastRequest) (*dto.DispatchReceipt, error) {
+	if _, _, err := u.resolveText(req.Title, req.Body); err != nil {
+		return nil, err
+	}
+	message, err := u.outbox.enqueue(ctx, uuid.Nil, OutboxKindBroadcast, req, nil)
+	if err != nil {
+		return nil, err
+	}
+	return receipt(message), nil
 }
 
-func (u *notificationUsecase) Broadcast(ctx context.Context, title, body string) error {
-	return u.fcm.BroadcastNotification(ctx, title, body)
+// SendToUsers queues a notification for every device of the targeted users.
+// The users are resolved when it is sent.
+func (u *notificationUsecase) SendToUsers(ctx context.Context, req *dto.TargetedNotificationRequest) (*dto.DispatchReceipt, error) {
+	if _, _, err := u.resolveText(req.Title, req.Body); err != nil {
+		return nil, err
+	}
+	message, err := u.outbox.enqueue(ctx, uuid.Nil, OutboxKindTargeted, req, nil)
+	if err != nil {
+		return nil, err
+	}
+	return receipt(message), nil
 }
 
+// DeliverQueued sends a broadcast or targeted notification taken from the
+// outbox. Only the first attempt writes the inbox, so retries do not repeat it.
+func (u *notificationUsecase) DeliverQueued(ctx context.Context, message *entities.OutboxMessage) (interface{}, error) {
+	withInbox := message.Attempts <= 1
+	trail := u.deliveries.trail(ctx, message)
+	defer trail.flush(ctx)
+	switch message.Kind {
+	case OutboxKindBroadcast:
+		var req dto.BroadcastRequest
+		if err := json.Unmarshal(message.Payload, &req); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrJobRejected, err)
+		}
+		return u.broadcast(ctx, &req, withInbox, trail)
+	case OutboxKindTargeted:
+		var req dto.TargetedNotificationRequest
+		if err := json.Unmarshal(message.Payload, &req); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrJobRejected, err)
+		}
+		return u.sendToUsers(ctx, &req, withInbox, trail)
+	default:
+		return nil, fmt.Errorf("%w: %q is not a notification", ErrJobRejected, message.Kind)
+	}
+}
+
+// broadcast sends a notification to every signed-in device and, over LINE,
+// to every user who enabled it, skipping users whose preferences rule it out.
+// It lands in every user's inbox. Users are walked a page at a time so that a
+// broadcast never holds every device in memory. Devices that are not signed
+// in are reached through BroadcastTopic, in the default locale.
+func (u *notificationUsecase) broadcast(ctx context.Context, req *dto.BroadcastRequest, withInbox bool, trail *deliveryTrail) (*dto.BroadcastResponse, error) {
+	titles, bodies, err := u.resolveText(req.Title, req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if !trail.reached(ProviderFCM, BroadcastTopic) {
+		messageID, err := u.push.fcm.SendNotificationToTopic(ctx, &dto.NotificationMessage{
+			Title:  titles[u.defaultLocale],
+			Body:   bodies[u.defaultLocale],
+			Locale: u.defaultLocale,
+		}, BroadcastTopic)
+		trail.topic(BroadcastTopic, messageID, err)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &dto.BroadcastResponse{}
+	after := uuid.Nil
+	for {
+		userIDs, err := u.userRepo.FindIDsAfter(ctx, after, broadcastPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load users: %v", err)
+		}
+		if len(userIDs) == 0 {
+			return resp, nil
+		}
+		after = userIDs[len(userIDs)-1]
+
+		devices, err := u.deviceRepo.FindByUserIDs(ctx, userIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load devices: %v", err)
+		}
+		if withInbox {
+			u.recordInbox(ctx, dto.InboxKindBroadcast, userIDs, devices, titles, bodies)
+		}
+
+		filter := u.preferences.forBroadcast()
+		pushDevices, err := filter.devices(ctx, devices)
+		if err != nil {
+			return nil, err
+		}
+		sent, err := u.send(ctx, pushDevices, titles, bodies, trail)
+		if err != nil {
+			return nil, err
+		}
+		resp.TargetedDevices += len(pushDevices)
+		resp.SuccessCount += sent.SuccessCount
+		resp.FailureCount += sent.FailureCount
+		if u.line.enabled() {
+			resp.LineRecipients += u.broadcastLine(ctx, userIDs, devices, titles, bodies, filter, trail)
+		}
+	}
+}
+
+// sendToUsers sends a notification to every device of the targeted users that
+// takes it under their preferences, and reports the outcome per push token.
+func (u *notificationUsecase) sendToUsers(ctx context.Context, req *dto.TargetedNotificationRequest, withInbox bool, trail *deliveryTrail) (*dto.TargetedNotificationResponse, error) {
+	titles, bodies, err := u.resolveText(req.Title, req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs, err := u.resolveUsers(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := u.deviceRepo.FindByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load devices: %v", err)
+	}
+	if withInbox {
+		u.recordInbox(ctx, dto.InboxKindTargeted, userIDs, devices, titles, bodies)
+	}
+
+	pushDevices, err := u.preferences.forBroadcast().devices(ctx, devices)
+	if err != nil {
+		return nil, err
+	}
+	sent, err := u.send(ctx, pushDevices, titles, bodies, trail)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.TargetedNotificationResponse{
+		TargetedUsers:   len(userIDs),
+		TargetedDevices: len(pushDevices),
+		SuccessTokens:   sent.SuccessTokens,
+		FailureTokens:   sent.FailureTokens,
+	}, nil
+}
+
+// resolveUsers returns the distinct IDs of the users a targeted request selects.
+func (u *notificationUsecase) resolveUsers(ctx context.Context, req *dto.TargetedNotificationRequest) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool)
+	userIDs := make([]uuid.UUID, 0, len(req.UserIDs))
+	add := func(ids []uuid.UUID) {
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				userIDs = append(userIDs, id)
+			}
+		}
+	}
+
+	add(req.UserIDs)
+	if len(req.Roles) > 0 {
+		ids, err := u.userRepo.FindIDsByRoles(ctx, req.Roles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve roles: %v", err)
+		}
+		add(ids)
+	}
+	if req.Segment != nil {
+		ids, err := u.ppRepo.FindCreatorIDsByType(ctx, req.Segment.PotentialPointType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve segment: %v", err)
+		}
+		add(ids)
+	}
+	return userIDs, nil
+}
+
+func (u *notificationUsecase) resolveText(title, body dto.LocalizedText) (map[string]string, map[string]string, error) {
+	titles := title.Resolve(u.defaultLocale)
+	bodies := body.Resolve(u.defaultLocale)
+	if titles[u.defaultLocale] == "" || bodies[u.defaultLocale] == "" {
+		return nil, nil, fmt.Errorf("%w: title and body must include the default locale %q", ErrInvalidContent, u.defaultLocale)
+	}
+	return titles, bodies, nil
+}
+
+// send pushes the notification to devices, each in its own locale when there
+// is a title for it and in the default locale otherwise. The outcome per
+// token is collected in trail.
+func (u *notificationUsecase) send(ctx context.Context, devices []entities.UserDevice, titles, bodies map[string]string, trail *deliveryTrail) (*dto.MulticastResponse, error) {
+	result := &dto.MulticastResponse{
+		MessageIDs:    make([]string, 0),
+		SuccessTokens: make([]string, 0),
+		FailureTokens: make([]dto.TopicManagementError, 0),
+	}
+
+	byToken := indexByToken(devices)
+	for _, batch := range u.push.batches(devices, titles, u.defaultLocale) {
+		tokens, reached := trail.unsent(batch.Provider, batch.Tokens)
+		result.SuccessCount += len(reached)
+		result.SuccessTokens = append(result.SuccessTokens, reached...)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		body, ok := bodies[batch.Locale]
+		if !ok {
+			body = bodies[u.defaultLocale]
+		}
+
+		sent, err := u.push.sender(batch.Provider).SendNotification(ctx, &dto.NotificationMessage{
+			Title:  titles[batch.Locale],
+			Body:   body,
+			Locale: batch.Locale,
+		}, tokens)
+		trail.push(batch.Provider, byToken, tokens, sent, err)
+		if err != nil {
+			return nil, err
+		}
+		u.pruner.Prune(ctx, len(tokens), sent.FailureTokens)
+		result.SuccessCount += sent.SuccessCount
+		result.FailureCount += sent.FailureCount
+		result.MessageIDs = append(result.MessageIDs, sent.MessageIDs...)
+		result.SuccessTokens = append(result.SuccessTokens, sent.SuccessTokens...)
+		result.FailureTokens = append(result.FailureTokens, sent.FailureTokens...)
+	}
+	return result, nil
+}
+
+// recordInbox keeps the notification in the inbox of every user it is sent
+// to. Failures are logged so they never hold back the push.
+func (u *notificationUsecase) recordInbox(ctx context.Context, kind string, userIDs []uuid.UUID, devices []entities.UserDevice, titles, bodies map[string]string) {
+	notifications := inboxNotifications(kind, userIDs, devices, titles, bodies, u.defaultLocale)
+	if err := u.inbox.CreateBatch(ctx, notifications); err != nil {
+		fmt.Printf("Warning: failed to record %s notification in inboxes: %v\n", kind, err)
+	}
+}
+
+// broadcastLine sends the notification over LINE to the opted-in users among
+// userIDs and returns how many received it. Failures are logged, as with alarms.
+func (u *notificationUsecase) broadcastLine(ctx context.Context, userIDs []uuid.UUID, devices []entities.UserDevice, titles, bodies map[string]string, filter *deliveryFilter, trail *deliveryTrail) int {
+	recipients, err := u.line.recipientsAmong(ctx, userIDs)
+	if err == nil {
+		recipients, err = filter.lineRecipients(ctx, recipients)
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to broadcast over LINE: %v\n", err)
+		return 0
+	}
+
+	delivered := 0
+	owners := lineOwners(recipients)
+	batches := lineBatches(recipients, devices, titles, u.defaultLocale)
+	for _, locale := range sortedLocales(batches) {
+		lineUserIDs, reached := trail.unsent(channelLine, batches[locale])
+		delivered += len(reached)
+		if len(lineUserIDs) == 0 {
+			continue
+		}
+
+		body, ok := bodies[locale]
+		if !ok {
+			body = bodies[u.defaultLocale]
+		}
+
+		result, err := u.line.sender.SendNotification(ctx, &dto.NotificationMessage{
+			Title:  titles[locale],
+			Body:   body,
+			Locale: locale,
+		}, lineUserIDs)
+		trail.line(owners, lineUserIDs, result, err)
+		if err != nil {
+			fmt.Printf("Warning: failed to broadcast over LINE: %v\n", err)
+			continue
+		}
+		delivered += result.SuccessCount
+	}
+	return delivered
+}
+
+// SubscribeToTopic subscribes raw push tokens to topic. Tokens of signed-in
+// devices are kept out of BroadcastTopic, since broadcasts reach them
+// directly, and are reported as subscribed.
 func (u *notificationUsecase) SubscribeToTopic(ctx context.Context, tokens []string, topic string) (*dto.TopicManagementResponse, error) {
-	return u.fcm.SubscribeToTopic(ctx, tokens, topic)
+	if topic != BroadcastTopic {
+		return u.push.fcm.SubscribeToTopic(ctx, tokens, topic)
+	}
+
+	devices, err := u.deviceRepo.FindByPushTokens(ctx, tokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load devices: %v", err)
+	}
+	signedIn := indexByToken(devices)
+	anonymous := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if _, ok := signedIn[token]; !ok {
+			anonymous = append(anonymous, token)
+		}
+	}
+
+	result := &dto.TopicManagementResponse{
+		SuccessTokens: make([]string, 0, len(tokens)),
+		FailureTokens: make([]dto.TopicManagementError, 0),
+	}
+	if len(anonymous) > 0 {
+		if result, err = u.push.fcm.SubscribeToTopic(ctx, anonymous, topic); err != nil {
+			return nil, err
+		}
+	}
+	for token := range signedIn {
+		result.SuccessTokens = append(result.SuccessTokens, token)
+	}
+	return result, nil
 }
 
 func (u *notificationUsecase) UnsubscribeFromTopic(ctx context.Context, tokens []string, topic string) (*dto.TopicManagementResponse, error) {
-	return u.fcm.UnsubscribeFromTopic(ctx, tokens, topic)
+	return u.push.fcm.UnsubscribeFromTopic(ctx, tokens, topic)
 }
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/config"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	OutboxKindAlarm     = "alarm"
+	OutboxKindBroadcast = "broadcast"
+	OutboxKindTargeted  = "targeted"
+
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusFailed  = "failed"
+)
+
+var ErrOutboxMessageNotFound = errors.New("outbox message not found")
+
+// Outbox queues sends for the worker. A send is written through the
+// transaction in ctx, if any, so it is only queued along with the record it
+// belongs to.
+type Outbox struct {
+	repo        repositories.OutboxRepository
+	maxAttempts int
+}
+
+// NewOutbox creates the outbox that alarm and notification sends are queued in.
+func NewOutbox(repo repositories.OutboxRepository, cfg *config.Config) *Outbox {
+	return &Outbox{repo: repo, maxAttempts: cfg.OutboxMaxAttempts}
+}
+
+// enqueue queues a send of kind. A message with a preset ID keeps it, so the
+// ID can be stored on the record before either is written.
+func (o *Outbox) enqueue(ctx context.Context, id uuid.UUID, kind string, payload interface{}, createdBy *uuid.UUID) (*entities.OutboxMessage, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s send: %v", kind, err)
+	}
+
+	message := &entities.OutboxMessage{
+		ID:            id,
+		Kind:          kind,
+		Payload:       datatypes.JSON(body),
+		Status:        OutboxStatusPending,
+		MaxAttempts:   o.maxAttempts,
+		NextAttemptAt: time.Now(),
+		CreatedBy:     createdBy,
+	}
+	if err := o.repo.Create(ctx, message); err != nil {
+		return nil, fmt.Errorf("failed to queue %s send: %v", kind, err)
+	}
+	return message, nil
+}
+
+// receipt acknowledges a queued send.
+func receipt(message *entities.OutboxMessage) *dto.DispatchReceipt {
+	return &dto.DispatchReceipt{TrackingID: message.ID, Status: message.Status}
+}
+
+// OutboxUsecase tracks queued sends and makes them on behalf of the worker.
+type OutboxUsecase interface {
+	FindByID(ctx context.Context, id uuid.UUID) (*entities.OutboxMessage, error)
+	// Run makes a queued send and returns its outcome. Errors wrapping
+	// ErrJobRejected cannot be fixed by retrying.
+	Run(ctx context.Context, message *entities.OutboxMessage) (json.RawMessage, error)
+}
+
+type outboxUsecase struct {
+	repo         repositories.OutboxRepository
+	alarm        AlarmUsecase
+	notification NotificationUsecase
+}
+
+func NewOutboxUsecase(repo repositories.OutboxRepository, alarm AlarmUsecase, notification NotificationUsecase) OutboxUsecase {
+	return &outboxUsecase{repo: repo, alarm: alarm, notification: notification}
+}
+
+func (u *outboxUsecase) FindByID(ctx context.Context, id uuid.UUID) (*entities.OutboxMessage, error) {
+	message, err := u.repo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOutboxMessageNotFound
+	}
+	return message, err
+}
+
+func (u *outboxUsecase) Run(ctx context.Context, message *entities.OutboxMessage) (json.RawMessage, error) {
+	var (
+		result interface{}
+		err    error
+	)
+	switch message.Kind {
+	case OutboxKindAlarm:
+		result, err = u.alarm.DeliverQueued(ctx, message)
+	case OutboxKindBroadcast, OutboxKindTargeted:
+		result, err = u.notification.DeliverQueued(ctx, message)
+	default:
+		return nil, fmt.Errorf("%w: unknown outbox message kind %q", ErrJobRejected, message.Kind)
+	}
+	if err != nil {
+		return nil, rejectPermanent(err)
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode send result: %v", err)
+	}
+	return body, nil
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+
+	"github.com/google/uuid"
+)
+
+const (
+	phoneVerificationTTL      = 10 * time.Minute
+	phoneVerificationCooldown = time.Minute
+	phoneVerificationAttempts = 5
+
+	// Codes texted per day to one number and on behalf of one user, so the
+	// endpoint cannot be used to flood a number or run up the SMS bill.
+	phoneVerificationWindow     = 24 * time.Hour
+	phoneVerificationsPerNumber = 5
+	phoneVerificationsPerUser   = 10
+)
+
+var (
+	ErrSMSNotConfigured        = errors.New("sms is not configured")
+	ErrVerificationNotFound    = errors.New("no pending phone verification")
+	ErrInvalidVerificationCode = errors.New("invalid verification code")
+	ErrTooManyAttempts         = errors.New("too many verification attempts")
+	ErrVerificationCooldown    = errors.New("verification code was sent recently")
+	ErrVerificationLimit       = errors.New("daily verification code limit reached")
+)
+
+// PhoneUsecase verifies the phone numbers users receive SMS alarms on.
+type PhoneUsecase interface {
+	RequestVerification(ctx context.Context, userID uuid.UUID, phoneNumber string) error
+	Verify(ctx context.Context, userID uuid.UUID, code string) (*entities.User, error)
+	Remove(ctx context.Context, userID uuid.UUID) error
+}
+
+type phoneUsecase struct {
+	sender        repositories.SMSSender
+	userRepo      repositories.UserRepository
+	verifications repositories.PhoneVerificationRepository
+}
+
+// NewPhoneUsecase creates the phone usecase. With a nil sender no number can
+// be verified.
+func NewPhoneUsecase(sender repositories.SMSSender, userRepo repositories.UserRepository, verifications repositories.PhoneVerificationRepository) PhoneUsecase {
+	return &phoneUsecase{sender: sender, userRepo: userRepo, verifications: verifications}
+}
+
+// RequestVerification texts a one-time code to phoneNumber. The number is
+// only stored on the user once the code comes back through Verify.
+func (u *phoneUsecase) RequestVerification(ctx context.Context, userID uuid.UUID, phoneNumber string) error {
+	if u.sender == nil {
+		return ErrSMSNotConfigured
+	}
+
+	pending, err := u.verifications.Find(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load phone verification: %v", err)
+	}
+	if pending != nil && time.Since(pending.SentAt) < phoneVerificationCooldown {
+		return ErrVerificationCooldown
+	}
+	if err := u.countSend(ctx, "number:"+phoneNumber, phoneVerificationsPerNumber); err != nil {
+		return err
+	}
+	if err := u.countSend(ctx, "user:"+userID.String(), phoneVerificationsPerUser); err != nil {
+		return err
+	}
+
+	code, err := verificationCode()
+	if err != nil {
+		return err
+	}
+	if err := u.verifications.Save(ctx, userID, &entities.PhoneVerification{
+		PhoneNumber: phoneNumber,
+		CodeHash:    hashVerificationCode(userID, code),
+		SentAt:      time.Now(),
+	}, phoneVerificationTTL); err != nil {
+		return fmt.Errorf("failed to save phone verification: %v", err)
+	}
+
+	text := fmt.Sprintf("%s is your PBMap verification code. It expires in %d minutes.", code, int(phoneVerificationTTL.Minutes()))
+	if _, err := u.sender.Send(ctx, phoneNumber, text); err != nil {
+		_ = u.verifications.Delete(ctx, userID)
+		return fmt.Errorf("failed to send verification code: %v", err)
+	}
+	return nil
+}
+
+// Verify checks code against the pending verification and, when it matches,
+// stores the number as the user's verified phone.
+func (u *phoneUsecase) Verify(ctx context.Context, userID uuid.UUID, code string) (*entities.User, error) {
+	pending, err := u.verifications.Find(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load phone verification: %v", err)
+	}
+	if pending == nil {
+		return nil, ErrVerificationNotFound
+	}
+
+	// The attempt is counted before the code is checked, so that concurrent
+	// guesses cannot all slip in under the limit.
+	attempts, err := u.verifications.IncrementAttempts(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record verification attempt: %v", err)
+	}
+	if attempts == 0 {
+		return nil, ErrVerificationNotFound
+	}
+	if attempts > phoneVerificationAttempts {
+		return nil, ErrTooManyAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashVerificationCode(userID, code)), []byte(pending.CodeHash)) != 1 {
+		if attempts == phoneVerificationAttempts {
+			return nil, ErrTooManyAttempts
+		}
+		return nil, ErrInvalidVerificationCode
+	}
+
+	now := time.Now()
+	if err := u.userRepo.UpdatePhone(ctx, userID, &pending.PhoneNumber, &now); err != nil {
+		return nil, fmt.Errorf("failed to save phone number: %v", err)
+	}
+	_ = u.verifications.Delete(ctx, userID)
+	return u.userRepo.FindByID(ctx, userID)
+}
+
+// Remove clears the user's phone number, which stops SMS alarms.
+func (u *phoneUsecase) Remove(ctx context.Context, userID uuid.UUID) error {
+	return u.userRepo.UpdatePhone(ctx, userID, nil, nil)
+}
+
+// countSend counts a code about to be sent under key, failing with
+// ErrVerificationLimit once more than limit were sent in the window.
+func (u *phoneUsecase) countSend(ctx context.Context, key string, limit int) error {
+	sent, err := u.verifications.CountSend(ctx, key, phoneVerificationWindow)
+	if err != nil {
+		return fmt.Errorf("failed to count verification codes: %v", err)
+	}
+	if sent > limit {
+		return ErrVerificationLimit
+	}
+	return nil
+}
+
+// verificationCode returns a random six-digit code.
+func verificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %v", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func hashVerificationCode(userID uuid.UUID, code string) string {
+	sum := sha256.Sum256([]byte(userID.String() + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
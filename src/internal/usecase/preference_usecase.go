@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/config"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLineNotLinked     = errors.New("account is not linked to LINE")
+	ErrInvalidQuietHours = errors.New("invalid quiet hours")
+)
+
+// PreferenceUsecase manages how each user wants to be notified.
+type PreferenceUsecase interface {
+	Get(ctx context.Context, userID uuid.UUID) (*dto.PreferencesResponse, error)
+	Update(ctx context.Context, userID uuid.UUID, req *dto.UpdatePreferencesRequest) (*dto.PreferencesResponse, error)
+}
+
+type preferenceUsecase struct {
+	userRepo        repositories.UserRepository
+	preferenceRepo  repositories.NotificationPreferenceRepository
+	topics          TopicUsecase
+	defaultTimeZone string
+}
+
+func NewPreferenceUsecase(userRepo repositories.UserRepository, preferenceRepo repositories.NotificationPreferenceRepository, topics TopicUsecase, cfg *config.Config) PreferenceUsecase {
+	return &preferenceUsecase{
+		userRepo:        userRepo,
+		preferenceRepo:  preferenceRepo,
+		topics:          topics,
+		defaultTimeZone: cfg.DefaultTimeZone,
+	}
+}
+
+func (u *preferenceUsecase) Get(ctx context.Context, userID uuid.UUID) (*dto.PreferencesResponse, error) {
+	preference, err := u.find(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	linked, err := u.lineLinked(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return u.response(preference, linked), nil
+}
+
+// Update saves the preferences set in req. LINE delivery can only be enabled
+// by users who signed in with LINE. Muting a topic also unsubscribes the
+// user's devices from it; subscribing to it again lifts the mute.
+func (u *preferenceUsecase) Update(ctx context.Context, userID uuid.UUID, req *dto.UpdatePreferencesRequest) (*dto.PreferencesResponse, error) {
+	preference, err := u.find(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	linked, err := u.lineLinked(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PushEnabled != nil {
+		preference.PushEnabled = *req.PushEnabled
+	}
+	if req.LineEnabled != nil {
+		if *req.LineEnabled && !linked {
+			return nil, ErrLineNotLinked
+		}
+		preference.LineEnabled = *req.LineEnabled
+	}
+	if req.SMSEnabled != nil {
+		preference.SMSEnabled = *req.SMSEnabled
+	}
+	if req.MutedHazards != nil {
+		hazards := slices.Clone(*req.MutedHazards)
+		slices.Sort(hazards)
+		preference.MutedHazards = datatypes.NewJSONSlice(slices.Compact(hazards))
+	}
+	var newlyMuted []uuid.UUID
+	if req.MutedTopicIDs != nil {
+		topicIDs := slices.Clone(*req.MutedTopicIDs)
+		slices.SortFunc(topicIDs, func(a, b uuid.UUID) int { return slices.Compare(a[:], b[:]) })
+		topicIDs = slices.Compact(topicIDs)
+		for _, id := range topicIDs {
+			if _, err := u.topics.FindByID(ctx, id); err != nil {
+				return nil, err
+			}
+			if !slices.Contains(preference.MutedTopicIDs, id) {
+				newlyMuted = append(newlyMuted, id)
+			}
+		}
+		preference.MutedTopicIDs = datatypes.NewJSONSlice(topicIDs)
+	}
+	if req.QuietHours != nil {
+		if err := setQuietHours(preference, req.QuietHours); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.preferenceRepo.Upsert(ctx, preference); err != nil {
+		return nil, fmt.Errorf("failed to save preferences: %v", err)
+	}
+	if err := u.leaveTopics(ctx, userID, newlyMuted); err != nil {
+		return nil, err
+	}
+	return u.response(preference, linked), nil
+}
+
+// leaveTopics unsubscribes the user's subscribed devices from topicIDs.
+func (u *preferenceUsecase) leaveTopics(ctx context.Context, userID uuid.UUID, topicIDs []uuid.UUID) error {
+	if len(topicIDs) == 0 {
+		return nil
+	}
+	subscriptions, err := u.topics.ListSubscriptions(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load topic subscriptions: %v", err)
+	}
+	for _, s := range subscriptions {
+		if !slices.Contains(topicIDs, s.Topic.ID) {
+			continue
+		}
+		if _, err := u.topics.Unsubscribe(ctx, userID, s.Topic.ID, s.DeviceIDs); err != nil {
+			return fmt.Errorf("failed to unsubscribe from muted topic %s: %v", s.Topic.Name, err)
+		}
+	}
+	return nil
+}
+
+// find returns the user's stored preferences, or the defaults when none are stored.
+func (u *preferenceUsecase) find(ctx context.Context, userID uuid.UUID) (*entities.NotificationPreference, error) {
+	preference, err := u.preferenceRepo.FindByUserID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultPreference(userID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load preferences: %v", err)
+	}
+	return preference, nil
+}
+
+func (u *preferenceUsecase) lineLinked(ctx context.Context, userID uuid.UUID) (bool, error) {
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load user: %v", err)
+	}
+	for _, account := range user.SocialAccounts {
+		if account.Provider == "line" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (u *preferenceUsecase) response(preference *entities.NotificationPreference, lineLinked bool) *dto.PreferencesResponse {
+	resp := &dto.PreferencesResponse{
+		PushEnabled:   preference.PushEnabled,
+		LineEnabled:   preference.LineEnabled,
+		LineLinked:    lineLinked,
+		SMSEnabled:    preference.SMSEnabled,
+		MutedTopicIDs: make([]uuid.UUID, 0, len(preference.MutedTopicIDs)),
+		MutedHazards:  make([]string, 0, len(preference.MutedHazards)),
+		QuietHours: dto.QuietHours{
+			Enabled:  preference.QuietStart != "",
+			Start:    preference.QuietStart,
+			End:      preference.QuietEnd,
+			TimeZone: preference.TimeZone,
+		},
+	}
+	resp.MutedTopicIDs = append(resp.MutedTopicIDs, preference.MutedTopicIDs...)
+	resp.MutedHazards = append(resp.MutedHazards, preference.MutedHazards...)
+	if resp.QuietHours.TimeZone == "" {
+		resp.QuietHours.TimeZone = u.defaultTimeZone
+	}
+	return resp
+}
+
+// setQuietHours stores the quiet hours as zero-padded HH:MM, or clears them.
+func setQuietHours(preference *entities.NotificationPreference, req *dto.QuietHoursRequest) error {
+	preference.TimeZone = req.TimeZone
+	if !req.Enabled {
+		preference.QuietStart, preference.QuietEnd = "", ""
+		return nil
+	}
+	start, err := time.Parse(quietHoursLayout, req.Start)
+	if err != nil {
+		return fmt.Errorf("%w: start must be HH:MM", ErrInvalidQuietHours)
+	}
+	end, err := time.Parse(quietHoursLayout, req.End)
+	if err != nil {
+		return fmt.Errorf("%w: end must be HH:MM", ErrInvalidQuietHours)
+	}
+	if start.Equal(end) {
+		return fmt.Errorf("%w: start and end must differ", ErrInvalidQuietHours)
+	}
+	preference.QuietStart = start.Format(quietHoursLayout)
+	preference.QuietEnd = end.Format(quietHoursLayout)
+	return nil
+}
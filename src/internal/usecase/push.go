@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"sort"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+)
+
+// Push providers a UserDevice can be registered with.
+const (
+	ProviderFCM     = "fcm"
+	ProviderAPNs    = "apns"
+	ProviderWebPush = "webpush"
+)
+
+// PushSenders maps a provider to its sender.
+type PushSenders map[string]repositories.PushSender
+
+// PushRouter picks the sender for each device by its Provider. Devices
+// without a provider go through FCM, which is how every device was sent
+// before other providers existed. Devices of an unconfigured provider are
+// skipped.
+type PushRouter struct {
+	fcm     repositories.FCMRepository
+	senders PushSenders
+}
+
+// NewPushRouter creates a router over FCM and any additional senders, keyed by
+// provider.
+func NewPushRouter(fcm repositories.FCMRepository, senders PushSenders) *PushRouter {
+	all := PushSenders{ProviderFCM: fcm}
+	for provider, sender := range senders {
+		if sender != nil {
+			all[provider] = sender
+		}
+	}
+	return &PushRouter{fcm: fcm, senders: all}
+}
+
+// providerFor returns the provider a device is sent through, or "" when it
+// cannot be reached.
+func (r *PushRouter) providerFor(device entities.UserDevice) string {
+	if _, ok := r.senders[device.Provider]; ok {
+		return device.Provider
+	}
+	if device.Provider == "" {
+		return ProviderFCM
+	}
+	return ""
+}
+
+func (r *PushRouter) sender(provider string) repositories.PushSender {
+	return r.senders[provider]
+}
+
+// fcmDevices returns the devices sent through FCM, the only provider with topics.
+func (r *PushRouter) fcmDevices(devices []entities.UserDevice) []entities.UserDevice {
+	matched := make([]entities.UserDevice, 0, len(devices))
+	for _, d := range devices {
+		if r.providerFor(d) == ProviderFCM {
+			matched = append(matched, d)
+		}
+	}
+	return matched
+}
+
+// pushBatch is the tokens of one provider that are sent the same locale.
+type pushBatch struct {
+	Provider string
+	Locale   string
+	Tokens   []string
+}
+
+// batches groups devices by provider and by the locale each is sent, in a
+// stable order.
+func (r *PushRouter) batches(devices []entities.UserDevice, texts map[string]string, defaultLocale string) []pushBatch {
+	byProvider := make(map[string][]entities.UserDevice)
+	for _, d := range devices {
+		if provider := r.providerFor(d); provider != "" {
+			byProvider[provider] = append(byProvider[provider], d)
+		}
+	}
+
+	providers := make([]string, 0, len(byProvider))
+	for provider := range byProvider {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	var batches []pushBatch
+	for _, provider := range providers {
+		locales := tokensByLocale(byProvider[provider], texts, defaultLocale)
+		for _, locale := range sortedLocales(locales) {
+			batches = append(batches, pushBatch{Provider: provider, Locale: locale, Tokens: locales[locale]})
+		}
+	}
+	return batches
+}
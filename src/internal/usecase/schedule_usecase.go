@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/config"
+
+	"github.com/google/uuid"
+)
+
+const (
+	JobTypeBroadcast = "broadcast"
+	JobTypeAlarm     = "alarm"
+)
+
+const (
+	// jobReservationPendingTTL bounds how long a job run in progress holds
+	// the reservation on its job ID, so a run that died frees it soon.
+	jobReservationPendingTTL = time.Minute
+	// jobReservationTTL bounds how long a job that went out is remembered,
+	// well past the retries of the job.
+	jobReservationTTL = 24 * time.Hour
+)
+
+var (
+	ErrScheduledJobNotFound = errors.New("scheduled job not found")
+	ErrJobNotApprovable     = errors.New("only scheduled alarms can be approved")
+	// ErrJobRejected marks a job or outbox send that can never succeed; the
+	// worker gives up on it instead of retrying.
+	ErrJobRejected = errors.New("job rejected")
+)
+
+// ScheduleUsecase schedules broadcasts and alarms on the delayed job queue
+// and runs them when they come due.
+type ScheduleUsecase interface {
+	ScheduleBroadcast(ctx context.Context, req *dto.ScheduleBroadcastRequest, createdBy *uuid.UUID) (*entities.Job, error)
+	ScheduleAlarm(ctx context.Context, req *dto.ScheduleAlarmRequest, createdBy *uuid.UUID) (*entities.Job, error)
+	Approve(ctx context.Context, id string, approverID uuid.UUID) (*entities.Job, error)
+	Reschedule(ctx context.Context, id string, runAt time.Time) (*entities.Job, error)
+	Cancel(ctx context.Context, id string) error
+	ListScheduled(ctx context.Context) ([]entities.Job, error)
+	ListDead(ctx context.Context) ([]entities.Job, error)
+	Run(ctx context.Context, job *entities.Job) error
+}
+
+type scheduleUsecase struct {
+	queue        repositories.JobQueue
+	idempotency  repositories.IdempotencyRepository
+	notification NotificationUsecase
+	alarm        AlarmUsecase
+
+	maxAttempts   int
+	defaultLocale string
+}
+
+// NewScheduleUsecase creates the schedule usecase.
+func NewScheduleUsecase(queue repositories.JobQueue, idempotency repositories.IdempotencyRepository, notification NotificationUsecase, alarm AlarmUsecase, cfg *config.Config) ScheduleUsecase {
+	return &scheduleUsecase{
+		queue:         queue,
+		idempotency:   idempotency,
+		notification:  notification,
+		alarm:         alarm,
+		maxAttempts:   cfg.JobMaxAttempts,
+		defaultLocale: cfg.DefaultLocale,
+	}
+}
+
+func (u *scheduleUsecase) ScheduleBroadcast(ctx context.Context, req *dto.ScheduleBroadcastRequest, createdBy *uuid.UUID) (*entities.Job, error) {
+	titles := req.Broadcast.Title.Resolve(u.defaultLocale)
+	bodies := req.Broadcast.Body.Resolve(u.defaultLocale)
+	if titles[u.defaultLocale] == "" || bodies[u.defaultLocale] == "" {
+		return nil, fmt.Errorf("%w: title and body must include the default locale %q", ErrInvalidContent, u.defaultLocale)
+	}
+	return u.enqueue(ctx, JobTypeBroadcast, req.Broadcast, req.RunAt, createdBy)
+}
+
+func (u *scheduleUsecase) ScheduleAlarm(ctx context.Context, req *dto.ScheduleAlarmRequest, createdBy *uuid.UUID) (*entities.Job, error) {
+	if err := u.alarm.ValidateAlarm(ctx, &req.Alarm); err != nil {
+		return nil, err
+	}
+	return u.enqueue(ctx, JobTypeAlarm, req.Alarm, req.RunAt, createdBy)
+}
+
+// Approve records a second officer's approval on a scheduled alarm so that it
+// is dispatched straight away when it comes due.
+func (u *scheduleUsecase) Approve(ctx context.Context, id string, approverID uuid.UUID) (*entities.Job, error) {
+	job, err := u.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Type != JobTypeAlarm {
+		return nil, ErrJobNotApprovable
+	}
+	if job.CreatedBy != nil && *job.CreatedBy == approverID {
+		return nil, ErrAlarmSelfApproval
+	}
+
+	now := time.Now()
+	job.ApprovedBy = &approverID
+	job.ApprovedAt = &now
+	if err := u.queue.Update(ctx, job); err != nil {
+		return nil, u.queueError(err)
+	}
+	return job, nil
+}
+
+func (u *scheduleUsecase) Reschedule(ctx context.Context, id string, runAt time.Time) (*entities.Job, error) {
+	if err := u.queue.Reschedule(ctx, id, runAt); err != nil {
+		return nil, u.queueError(err)
+	}
+	return u.get(ctx, id)
+}
+
+func (u *scheduleUsecase) Cancel(ctx context.Context, id string) error {
+	return u.queueError(u.queue.Cancel(ctx, id))
+}
+
+func (u *scheduleUsecase) ListScheduled(ctx context.Context) ([]entities.Job, error) {
+	return u.queue.ListScheduled(ctx)
+}
+
+func (u *scheduleUsecase) ListDead(ctx context.Context) ([]entities.Job, error) {
+	return u.queue.ListDead(ctx)
+}
+
+// Run executes a due job. A job may run twice under at-least-once delivery:
+// alarm dispatch is idempotent on alarm_id, and a broadcast is queued under a
+// reservation on the job ID, so either goes out only once.
+func (u *scheduleUsecase) Run(ctx context.Context, job *entities.Job) error {
+	switch job.Type {
+	case JobTypeBroadcast:
+		var req dto.BroadcastRequest
+		if err := json.Unmarshal(job.Payload, &req); err != nil {
+			return fmt.Errorf("%w: %v", ErrJobRejected, err)
+		}
+		return u.runBroadcast(ctx, job, &req)
+	case JobTypeAlarm:
+		var req dto.AlarmDispatchRequest
+		if err := json.Unmarshal(job.Payload, &req); err != nil {
+			return fmt.Errorf("%w: %v", ErrJobRejected, err)
+		}
+		var err error
+		if job.ApprovedBy != nil && job.ApprovedAt != nil {
+			_, err = u.alarm.DispatchApprovedAlarm(ctx, &req, job.CreatedBy, AlarmApproval{ApprovedBy: *job.ApprovedBy, ApprovedAt: *job.ApprovedAt})
+		} else {
+			_, err = u.alarm.DispatchAlarm(ctx, &req, job.CreatedBy)
+		}
+		return rejectPermanent(err)
+	default:
+		return fmt.Errorf("%w: unknown job type %q", ErrJobRejected, job.Type)
+	}
+}
+
+func (u *scheduleUsecase) enqueue(ctx context.Context, jobType string, payload interface{}, runAt time.Time, createdBy *uuid.UUID) (*entities.Job, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %v", err)
+	}
+
+	job := &entities.Job{
+		Type:        jobType,
+		Payload:     body,
+		RunAt:       runAt,
+		MaxAttempts: u.maxAttempts,
+		CreatedBy:   createdBy,
+	}
+	if err := u.queue.Enqueue(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to schedule job: %v", err)
+	}
+	return job, nil
+}
+
+func (u *scheduleUsecase) get(ctx context.Context, id string) (*entities.Job, error) {
+	job, err := u.queue.Get(ctx, id)
+	if err != nil {
+		return nil, u.queueError(err)
+	}
+	return job, nil
+}
+
+func (u *scheduleUsecase) queueError(err error) error {
+	if errors.Is(err, repositories.ErrJobNotFound) {
+		return ErrScheduledJobNotFound
+	}
+	return err
+}
+
+// rejectPermanent marks errors that retrying cannot fix.
+// runBroadcast queues a scheduled broadcast unless an earlier run of the job
+// already did.
+func (u *scheduleUsecase) runBroadcast(ctx context.Context, job *entities.Job, req *dto.BroadcastRequest) error {
+	key := "job:" + job.ID
+	record, err := u.idempotency.Reserve(ctx, key, "", jobReservationPendingTTL)
+	switch {
+	case err != nil:
+		return fmt.Errorf("failed to reserve job %s: %v", job.ID, err)
+	case record != nil && record.Response != nil:
+		return nil
+	case record != nil:
+		return fmt.Errorf("job %s is already running", job.ID)
+	}
+
+	receipt, err := u.notification.Broadcast(ctx, req)
+	if err != nil {
+		_ = u.idempotency.Release(ctx, key)
+		return rejectPermanent(err)
+	}
+	body, err := json.Marshal(receipt)
+	if err == nil {
+		err = u.idempotency.Complete(ctx, key, &entities.IdempotencyRecord{Response: body}, jobReservationTTL)
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to record scheduled broadcast %s as queued: %v\n", job.ID, err)
+	}
+	return nil
+}
+
+func rejectPermanent(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrInvalidArea),
+		errors.Is(err, ErrInvalidContent),
+		errors.Is(err, ErrMissingVariables),
+		errors.Is(err, ErrAlarmTemplateNotFound),
+		errors.Is(err, ErrAlarmConflict),
+		errors.Is(err, ErrAlarmSelfApproval):
+		return fmt.Errorf("%w: %v", ErrJobRejected, err)
+	default:
+		return err
+	}
+}
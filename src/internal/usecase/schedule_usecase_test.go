@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/config"
+
+	"github.com/google/uuid"
+)
+
+// stubIdempotencyRepo keeps reservations in memory.
+type stubIdempotencyRepo struct {
+	records map[string]*entities.IdempotencyRecord
+}
+
+var _ repositories.IdempotencyRepository = (*stubIdempotencyRepo)(nil)
+
+func (r *stubIdempotencyRepo) Reserve(ctx context.Context, key, payloadHash string, ttl time.Duration) (*entities.IdempotencyRecord, error) {
+	if record, ok := r.records[key]; ok {
+		return record, nil
+	}
+	r.records[key] = &entities.IdempotencyRecord{PayloadHash: payloadHash}
+	return nil, nil
+}
+
+func (r *stubIdempotencyRepo) Complete(ctx context.Context, key string, record *entities.IdempotencyRecord, ttl time.Duration) error {
+	r.records[key] = record
+	return nil
+}
+
+func (r *stubIdempotencyRepo) Release(ctx context.Context, key string) error {
+	delete(r.records, key)
+	return nil
+}
+
+// stubBroadcaster counts the broadcasts queued.
+type stubBroadcaster struct {
+	NotificationUsecase
+	queued int
+}
+
+func (n *stubBroadcaster) Broadcast(ctx context.Context, req *dto.BroadcastRequest) (*dto.DispatchReceipt, error) {
+	n.queued++
+	return &dto.DispatchReceipt{TrackingID: uuid.New(), Status: OutboxStatusPending}, nil
+}
+
+func TestRunBroadcastOnce(t *testing.T) {
+	idempotency := &stubIdempotencyRepo{records: make(map[string]*entities.IdempotencyRecord)}
+	notification := &stubBroadcaster{}
+	schedule := NewScheduleUsecase(nil, idempotency, notification, nil, &config.Config{DefaultLocale: "th"})
+
+	payload, err := json.Marshal(dto.BroadcastRequest{Title: dto.LocalizedText{"th": "หัวข้อ"}, Body: dto.LocalizedText{"th": "ข้อความ"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := &entities.Job{ID: uuid.NewString(), Type: JobTypeBroadcast, Payload: payload}
+
+	for range 2 {
+		if err := schedule.Run(context.Background(), job); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	}
+	if notification.queued != 1 {
+		t.Fatalf("broadcast queued %d times, want once however often the job runs", notification.queued)
+	}
+
+	other := &entities.Job{ID: uuid.NewString(), Type: JobTypeBroadcast, Payload: payload}
+	if err := schedule.Run(context.Background(), other); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if notification.queued != 2 {
+		t.Fatalf("broadcast queued %d times, want another job to queue its own", notification.queued)
+	}
+}
+
+func TestRunBroadcastInProgress(t *testing.T) {
+	job := &entities.Job{ID: uuid.NewString(), Type: JobTypeBroadcast, Payload: json.RawMessage(`{}`)}
+	idempotency := &stubIdempotencyRepo{records: map[string]*entities.IdempotencyRecord{"job:" + job.ID: {}}}
+	notification := &stubBroadcaster{}
+	schedule := NewScheduleUsecase(nil, idempotency, notification, nil, &config.Config{DefaultLocale: "th"})
+
+	if err := schedule.Run(context.Background(), job); err == nil {
+		t.Fatal("Run succeeded while another run held the job")
+	}
+	if notification.queued != 0 {
+		t.Fatal("broadcast queued while another run held the job")
+	}
+}
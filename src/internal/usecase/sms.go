@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/pkg/geo"
+)
+
+const (
+	// A single SMS segment holds 160 GSM-7 characters, or 70 UCS-2 characters
+	// once the text has anything outside ASCII, such as Thai.
+	smsGSMLength  = 160
+	smsUCS2Length = 70
+
+	smsConcurrency = 8
+)
+
+// smsStatusLabels prefix follow-up alarm SMS, by language.
+var smsStatusLabels = map[string]map[string]string{
+	"th": {AlarmMessageUpdate: "อัปเดต", AlarmMessageCancel: "ยกเลิก", AlarmMessageExpired: "สิ้นสุด"},
+	"en": {AlarmMessageUpdate: "UPDATE", AlarmMessageCancel: "CANCELLED", AlarmMessageExpired: "ENDED"},
+}
+
+// SMSChannel sends alarms by SMS to users in the area who have no device that
+// can receive push, such as elderly citizens without a smartphone.
+type SMSChannel struct {
+	sender   repositories.SMSSender
+	userRepo repositories.UserRepository
+}
+
+// NewSMSChannel creates the SMS channel. With a nil sender nothing is sent.
+func NewSMSChannel(sender repositories.SMSSender, userRepo repositories.UserRepository) *SMSChannel {
+	return &SMSChannel{sender: sender, userRepo: userRepo}
+}
+
+func (s *SMSChannel) enabled() bool {
+	return s != nil && s.sender != nil
+}
+
+// recipients returns the users an alarm is sent by SMS: drill-enrolled users
+// for drills and users whose home is inside the area otherwise.
+func (s *SMSChannel) recipients(ctx context.Context, alarm *entities.Alarm, area *alarmArea) ([]entities.User, error) {
+	if alarm.Mode == AlarmModeDrill {
+		users, err := s.userRepo.FindSMSDrillRecipients(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select drill group SMS recipients: %v", err)
+		}
+		return users, nil
+	}
+
+	candidates, err := s.userRepo.FindSMSRecipientsWithinBounds(ctx, area.bounds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to select SMS recipients: %v", err)
+	}
+	users := make([]entities.User, 0, len(candidates))
+	for _, u := range candidates {
+		if u.HomeLatitude != nil && u.HomeLongitude != nil && area.contains(geo.Point{Lat: *u.HomeLatitude, Lng: *u.HomeLongitude}) {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+// sendAll sends text to every user's phone an earlier attempt did not reach,
+// recording each outcome in trail, and returns how many were accepted.
+func (s *SMSChannel) sendAll(ctx context.Context, users []entities.User, text string, trail *deliveryTrail) int {
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		delivered int
+	)
+	slots := make(chan struct{}, smsConcurrency)
+	for _, user := range users {
+		if user.PhoneNumber == nil {
+			continue
+		}
+		if trail.reached(channelSMS, *user.PhoneNumber) {
+			mu.Lock()
+			delivered++
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(user entities.User, to string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			messageID, err := s.sender.Send(ctx, to, text)
+			trail.sms(user, to, messageID, err)
+			if err != nil {
+				fmt.Printf("Warning: failed to send SMS: %v\n", err)
+				return
+			}
+			mu.Lock()
+			delivered++
+			mu.Unlock()
+		}(user, *user.PhoneNumber)
+	}
+	wg.Wait()
+	return delivered
+}
+
+// alarmSMSText fits an alarm into a single SMS segment, prefixing follow-ups
+// with their status.
+func alarmSMSText(signal, content, status, locale string) string {
+	text := signal + ": " + content
+	if label := smsStatusLabel(status, locale); label != "" {
+		text = "[" + label + "] " + text
+	}
+	return truncateSMS(text)
+}
+
+func smsStatusLabel(status, locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+	labels, ok := smsStatusLabels[language]
+	if !ok {
+		labels = smsStatusLabels["en"]
+	}
+	return labels[status]
+}
+
+// truncateSMS shortens text to one SMS segment, marking the cut.
+func truncateSMS(text string) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+
+	limit, ellipsis, width := smsGSMLength, []rune("..."), gsmWidth
+	for _, r := range runes {
+		if r > 0x7E {
+			limit, ellipsis, width = smsUCS2Length, []rune("…"), func(rune) int { return 1 }
+			break
+		}
+	}
+
+	used := 0
+	for _, r := range runes {
+		used += width(r)
+	}
+	if used <= limit {
+		return string(runes)
+	}
+
+	used = len(ellipsis)
+	cut := 0
+	for cut < len(runes) && used+width(runes[cut]) <= limit {
+		used += width(runes[cut])
+		cut++
+	}
+	return strings.TrimSpace(string(runes[:cut])) + string(ellipsis)
+}
+
+// gsmWidth is how many GSM-7 septets an ASCII character takes; characters
+// from the extension table take an escape septet too.
+func gsmWidth(r rune) int {
+	if strings.ContainsRune("[]{}|\\^~", r) {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateSMS(t *testing.T) {
+	thai := strings.Repeat("ก", smsUCS2Length)
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"short", "Flood warning", "Flood warning"},
+		{"whitespace collapsed", "  Flood\n\twarning  ", "Flood warning"},
+		{"exactly one GSM segment", strings.Repeat("a", smsGSMLength), strings.Repeat("a", smsGSMLength)},
+		{"one over GSM segment", strings.Repeat("a", smsGSMLength+1), strings.Repeat("a", smsGSMLength-3) + "..."},
+		{"extended GSM characters count twice", strings.Repeat("[", smsGSMLength/2), strings.Repeat("[", smsGSMLength/2)},
+		{"extended GSM characters cut", strings.Repeat("[", smsGSMLength/2+1), strings.Repeat("[", (smsGSMLength-3)/2) + "..."},
+		{"exactly one UCS-2 segment", thai, thai},
+		{"one over UCS-2 segment", thai + "ก", strings.Repeat("ก", smsUCS2Length-1) + "…"},
+		{"one non-GSM character switches to UCS-2", "é" + strings.Repeat("a", smsUCS2Length), "é" + strings.Repeat("a", smsUCS2Length-2) + "…"},
+		{"no space before the ellipsis", strings.Repeat("a", smsGSMLength-4) + " bbbb", strings.Repeat("a", smsGSMLength-4) + "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateSMS(tt.text)
+			if got != tt.want {
+				t.Fatalf("truncateSMS() = %q (%d runes), want %q (%d runes)", got, utf8.RuneCountInString(got), tt.want, utf8.RuneCountInString(tt.want))
+			}
+		})
+	}
+}
+
+func TestAlarmSMSText(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		locale  string
+		content string
+		want    string
+	}{
+		{"new alarm", AlarmMessageNew, "en", "Leave now", "Evacuate: Leave now"},
+		{"update in English", AlarmMessageUpdate, "en-US", "Leave now", "[UPDATE] Evacuate: Leave now"},
+		{"cancel in Thai", AlarmMessageCancel, "th", "Leave now", "[ยกเลิก] Evacuate: Leave now"},
+		{"unknown language falls back to English", AlarmMessageExpired, "lo", "Leave now", "[ENDED] Evacuate: Leave now"},
+		{"long content truncated", AlarmMessageNew, "en", strings.Repeat("a", 200), "Evacuate: " + strings.Repeat("a", smsGSMLength-13) + "..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := alarmSMSText("Evacuate", tt.content, tt.status, tt.locale); got != tt.want {
+				t.Fatalf("alarmSMSText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	"pbmap_api/src/pkg/metrics"
+)
+
+// TokenPruner feeds per-token provider errors back into the device store so
+// dead push tokens are no longer sent to.
+type TokenPruner interface {
+	// Prune marks the devices behind dead tokens in failures as invalid and
+	// drops their topic subscriptions. attempted is the number of tokens the
+	// failures were reported for. Errors are logged, never returned, so a
+	// failed prune does not fail the send that reported it.
+	Prune(ctx context.Context, attempted int, failures []dto.TopicManagementError)
+}
+
+type tokenPruner struct {
+	deviceRepo       repositories.DeviceRepository
+	subscriptionRepo repositories.TopicSubscriptionRepository
+}
+
+func NewTokenPruner(deviceRepo repositories.DeviceRepository, subscriptionRepo repositories.TopicSubscriptionRepository) TokenPruner {
+	return &tokenPruner{deviceRepo: deviceRepo, subscriptionRepo: subscriptionRepo}
+}
+
+func (p *tokenPruner) Prune(ctx context.Context, attempted int, failures []dto.TopicManagementError) {
+	metrics.PushTokensChecked.Add(int64(attempted))
+
+	dead := make(map[string][]string)
+	for _, f := range failures {
+		if f.Code == dto.TokenErrorUnregistered || f.Code == dto.TokenErrorInvalidArgument {
+			dead[f.Code] = append(dead[f.Code], f.Token)
+		}
+	}
+	// invalid-argument can be about the message as much as the token; it only
+	// points at the token when other tokens of the same request succeeded.
+	if len(failures) >= attempted {
+		delete(dead, dto.TokenErrorInvalidArgument)
+	}
+
+	for code, tokens := range dead {
+		ids, err := p.deviceRepo.InvalidatePushTokens(ctx, tokens, code)
+		if err != nil {
+			fmt.Printf("Failed to invalidate %d push tokens (%s): %v\n", len(tokens), code, err)
+			continue
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		metrics.PushTokensPruned.Add(code, int64(len(ids)))
+		if err := p.subscriptionRepo.DeleteByDevices(ctx, ids); err != nil {
+			fmt.Printf("Failed to remove topic subscriptions of %d pruned devices: %v\n", len(ids), err)
+		}
+		fmt.Printf("Pruned %d devices with dead push tokens (%s)\n", len(ids), code)
+	}
+}
@@ -0,0 +1,336 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTopicNotFound  = errors.New("topic not found")
+	ErrInvalidTopic   = errors.New("invalid topic")
+	ErrDeviceNotFound = errors.New("device not found")
+)
+
+// topicNamePattern is the character set FCM accepts in topic names.
+var topicNamePattern = regexp.MustCompile(`^[a-zA-Z0-9\-_.~%]+$`)
+
+// TopicUsecase manages named topics and the devices subscribed to them.
+type TopicUsecase interface {
+	Create(ctx context.Context, input dto.CreateTopicInput, creatorID *uuid.UUID) (*entities.Topic, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*entities.Topic, error)
+	Update(ctx context.Context, id uuid.UUID, input dto.UpdateTopicInput) (*entities.Topic, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindAll(ctx context.Context, category string) ([]entities.Topic, error)
+	Subscribe(ctx context.Context, userID, topicID uuid.UUID, deviceIDs []uuid.UUID) (*dto.TopicSubscriptionResponse, error)
+	Unsubscribe(ctx context.Context, userID, topicID uuid.UUID, deviceIDs []uuid.UUID) (*dto.TopicSubscriptionResponse, error)
+	ListSubscriptions(ctx context.Context, userID uuid.UUID) ([]dto.SubscribedTopic, error)
+	ResyncDevice(ctx context.Context, deviceID uuid.UUID, oldToken, newToken string) error
+	LeaveBroadcastTopic(ctx context.Context, device entities.UserDevice) error
+}
+
+type topicUsecase struct {
+	push             *PushRouter
+	topicRepo        repositories.TopicRepository
+	subscriptionRepo repositories.TopicSubscriptionRepository
+	deviceRepo       repositories.DeviceRepository
+	preferenceRepo   repositories.NotificationPreferenceRepository
+	pruner           TokenPruner
+}
+
+func NewTopicUsecase(push *PushRouter, topicRepo repositories.TopicRepository, subscriptionRepo repositories.TopicSubscriptionRepository, deviceRepo repositories.DeviceRepository, preferenceRepo repositories.NotificationPreferenceRepository, pruner TokenPruner) TopicUsecase {
+	return &topicUsecase{
+		push:             push,
+		topicRepo:        topicRepo,
+		subscriptionRepo: subscriptionRepo,
+		deviceRepo:       deviceRepo,
+		preferenceRepo:   preferenceRepo,
+		pruner:           pruner,
+	}
+}
+
+func (u *topicUsecase) Create(ctx context.Context, input dto.CreateTopicInput, creatorID *uuid.UUID) (*entities.Topic, error) {
+	if !topicNamePattern.MatchString(input.Name) {
+		return nil, fmt.Errorf("%w: name may only contain letters, digits and -_.~%%", ErrInvalidTopic)
+	}
+
+	topic := &entities.Topic{
+		Name:        input.Name,
+		Category:    input.Category,
+		DisplayName: input.DisplayName,
+		Description: input.Description,
+		CreatedBy:   creatorID,
+	}
+	if err := u.topicRepo.Create(ctx, topic); err != nil {
+		return nil, err
+	}
+	return topic, nil
+}
+
+func (u *topicUsecase) FindByID(ctx context.Context, id uuid.UUID) (*entities.Topic, error) {
+	topic, err := u.topicRepo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTopicNotFound
+	}
+	return topic, err
+}
+
+func (u *topicUsecase) Update(ctx context.Context, id uuid.UUID, input dto.UpdateTopicInput) (*entities.Topic, error) {
+	topic, err := u.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Category != nil {
+		topic.Category = *input.Category
+	}
+	if input.DisplayName != nil {
+		topic.DisplayName = *input.DisplayName
+	}
+	if input.Description != nil {
+		topic.Description = *input.Description
+	}
+
+	if err := u.topicRepo.Update(ctx, topic); err != nil {
+		return nil, err
+	}
+	return topic, nil
+}
+
+// Delete unsubscribes every member device from the FCM topic before removing
+// the topic and its subscriptions.
+func (u *topicUsecase) Delete(ctx context.Context, id uuid.UUID) error {
+	topic, err := u.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	subscriptions, err := u.subscriptionRepo.FindByTopic(ctx, topic.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load subscriptions: %v", err)
+	}
+	tokens := make([]string, 0, len(subscriptions))
+	for _, s := range subscriptions {
+		if s.Device != nil && s.Device.PushToken != "" {
+			tokens = append(tokens, s.Device.PushToken)
+		}
+	}
+	if len(tokens) > 0 {
+		if _, err := u.push.fcm.UnsubscribeFromTopic(ctx, tokens, topic.Name); err != nil {
+			return err
+		}
+	}
+
+	err = u.topicRepo.Delete(ctx, topic.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTopicNotFound
+	}
+	return err
+}
+
+func (u *topicUsecase) FindAll(ctx context.Context, category string) ([]entities.Topic, error) {
+	return u.topicRepo.FindAll(ctx, category)
+}
+
+// Subscribe subscribes the user's devices to the topic and records a
+// subscription for every device FCM accepted. Subscribing lifts any mute the
+// user put on the topic.
+func (u *topicUsecase) Subscribe(ctx context.Context, userID, topicID uuid.UUID, deviceIDs []uuid.UUID) (*dto.TopicSubscriptionResponse, error) {
+	topic, err := u.FindByID(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := u.ownedDevices(ctx, userID, deviceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := u.push.fcm.SubscribeToTopic(ctx, pushTokens(devices), topic.Name)
+	if err != nil {
+		return nil, err
+	}
+	u.pruner.Prune(ctx, len(devices), result.FailureTokens)
+
+	subscribed := devicesByToken(devices, result.SuccessTokens)
+	subscriptions := make([]entities.TopicSubscription, 0, len(subscribed))
+	for _, d := range subscribed {
+		subscriptions = append(subscriptions, entities.TopicSubscription{
+			TopicID:  topic.ID,
+			DeviceID: d.ID,
+			UserID:   userID,
+		})
+	}
+	if err := u.subscriptionRepo.Create(ctx, subscriptions); err != nil {
+		return nil, fmt.Errorf("failed to record subscriptions: %v", err)
+	}
+	if err := u.preferenceRepo.UnmuteTopic(ctx, userID, topic.ID); err != nil {
+		return nil, fmt.Errorf("failed to unmute topic: %v", err)
+	}
+
+	return topicSubscriptionResponse(topic, subscribed, result), nil
+}
+
+// Unsubscribe unsubscribes the user's devices from the topic and drops the
+// subscriptions FCM confirmed.
+func (u *topicUsecase) Unsubscribe(ctx context.Context, userID, topicID uuid.UUID, deviceIDs []uuid.UUID) (*dto.TopicSubscriptionResponse, error) {
+	topic, err := u.FindByID(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := u.ownedDevices(ctx, userID, deviceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := u.push.fcm.UnsubscribeFromTopic(ctx, pushTokens(devices), topic.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	unsubscribed := devicesByToken(devices, result.SuccessTokens)
+	ids := make([]uuid.UUID, 0, len(unsubscribed))
+	for _, d := range unsubscribed {
+		ids = append(ids, d.ID)
+	}
+	if err := u.subscriptionRepo.Delete(ctx, topic.ID, ids); err != nil {
+		return nil, fmt.Errorf("failed to remove subscriptions: %v", err)
+	}
+
+	return topicSubscriptionResponse(topic, unsubscribed, result), nil
+}
+
+func (u *topicUsecase) ListSubscriptions(ctx context.Context, userID uuid.UUID) ([]dto.SubscribedTopic, error) {
+	subscriptions, err := u.subscriptionRepo.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	topics := make([]dto.SubscribedTopic, 0)
+	index := make(map[uuid.UUID]int)
+	for _, s := range subscriptions {
+		if s.Topic == nil {
+			continue
+		}
+		i, ok := index[s.TopicID]
+		if !ok {
+			i = len(topics)
+			index[s.TopicID] = i
+			topics = append(topics, dto.SubscribedTopic{Topic: *s.Topic, DeviceIDs: make([]uuid.UUID, 0, 1)})
+		}
+		topics[i].DeviceIDs = append(topics[i].DeviceIDs, s.DeviceID)
+	}
+	return topics, nil
+}
+
+// ResyncDevice moves the device's topic subscriptions from its old push token
+// to its new one. Unsubscribing the old token is best effort: FCM usually no
+// longer knows it once it has been rotated.
+func (u *topicUsecase) ResyncDevice(ctx context.Context, deviceID uuid.UUID, oldToken, newToken string) error {
+	subscriptions, err := u.subscriptionRepo.FindByDevice(ctx, deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to load subscriptions: %v", err)
+	}
+
+	for _, s := range subscriptions {
+		if s.Topic == nil {
+			continue
+		}
+		result, err := u.push.fcm.SubscribeToTopic(ctx, []string{newToken}, s.Topic.Name)
+		if err != nil {
+			return err
+		}
+		u.pruner.Prune(ctx, 1, result.FailureTokens)
+		if len(result.FailureTokens) > 0 {
+			return fmt.Errorf("failed to resubscribe to topic %s: %s", s.Topic.Name, result.FailureTokens[0].Reason)
+		}
+		if oldToken != "" {
+			if _, err := u.push.fcm.UnsubscribeFromTopic(ctx, []string{oldToken}, s.Topic.Name); err != nil {
+				fmt.Printf("Failed to unsubscribe rotated token from topic %s: %v\n", s.Topic.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// LeaveBroadcastTopic takes a signed-in device's token out of BroadcastTopic,
+// since broadcasts now reach the device directly.
+func (u *topicUsecase) LeaveBroadcastTopic(ctx context.Context, device entities.UserDevice) error {
+	if device.PushToken == "" || u.push.providerFor(device) != ProviderFCM {
+		return nil
+	}
+	if _, err := u.push.fcm.UnsubscribeFromTopic(ctx, []string{device.PushToken}, BroadcastTopic); err != nil {
+		return fmt.Errorf("failed to take device %s out of topic %s: %v", device.ID, BroadcastTopic, err)
+	}
+	return nil
+}
+
+// ownedDevices returns the user's FCM devices, restricted to deviceIDs when
+// any are given. Devices of other providers cannot join FCM topics.
+func (u *topicUsecase) ownedDevices(ctx context.Context, userID uuid.UUID, deviceIDs []uuid.UUID) ([]entities.UserDevice, error) {
+	devices, err := u.deviceRepo.FindByUserIDs(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load devices: %v", err)
+	}
+	devices = u.push.fcmDevices(devices)
+	if len(deviceIDs) == 0 {
+		return devices, nil
+	}
+
+	owned := make(map[uuid.UUID]entities.UserDevice, len(devices))
+	for _, d := range devices {
+		owned[d.ID] = d
+	}
+	selected := make([]entities.UserDevice, 0, len(deviceIDs))
+	for _, id := range deviceIDs {
+		d, ok := owned[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrDeviceNotFound, id)
+		}
+		selected = append(selected, d)
+	}
+	return selected, nil
+}
+
+func pushTokens(devices []entities.UserDevice) []string {
+	tokens := make([]string, 0, len(devices))
+	for _, d := range devices {
+		tokens = append(tokens, d.PushToken)
+	}
+	return tokens
+}
+
+// devicesByToken returns the devices whose push token is in tokens.
+func devicesByToken(devices []entities.UserDevice, tokens []string) []entities.UserDevice {
+	wanted := make(map[string]bool, len(tokens))
+	for _, t := range tokens {
+		wanted[t] = true
+	}
+	matched := make([]entities.UserDevice, 0, len(tokens))
+	for _, d := range devices {
+		if wanted[d.PushToken] {
+			matched = append(matched, d)
+		}
+	}
+	return matched
+}
+
+func topicSubscriptionResponse(topic *entities.Topic, devices []entities.UserDevice, result *dto.TopicManagementResponse) *dto.TopicSubscriptionResponse {
+	ids := make([]uuid.UUID, 0, len(devices))
+	for _, d := range devices {
+		ids = append(ids, d.ID)
+	}
+	return &dto.TopicSubscriptionResponse{
+		TopicID:       topic.ID,
+		Topic:         topic.Name,
+		DeviceIDs:     ids,
+		FailureTokens: result.FailureTokens,
+	}
+}
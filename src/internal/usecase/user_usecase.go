@@ -2,11 +2,14 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"pbmap_api/src/internal/domain/entities"
 	"pbmap_api/src/internal/domain/repositories"
 	"pbmap_api/src/internal/dto"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type UserUsecase interface {
@@ -16,18 +19,23 @@ type UserUsecase interface {
 	DeleteUser(ctx context.Context, id uuid.UUID) error
 	ListUsers(ctx context.Context) ([]entities.User, error)
 	SyncUserFromSocial(ctx context.Context, input dto.CreateUserFromSocialInput) (*entities.User, error)
-	UpsertDevice(ctx context.Context, device *entities.UserDevice) error
+	UpsertDevice(ctx context.Context, device *entities.UserDevice) (func(ctx context.Context) error, error)
+	UpdateDeviceLocation(ctx context.Context, userID, deviceID uuid.UUID, lat, lng float64) error
+	UpdateHomeLocation(ctx context.Context, userID uuid.UUID, lat, lng *float64) error
+	RotatePushToken(ctx context.Context, userID, deviceID uuid.UUID, token string) error
 }
 
 type userUsecase struct {
 	userRepo   repositories.UserRepository
 	deviceRepo repositories.DeviceRepository
+	topics     TopicUsecase
 }
 
-func NewUserUsecase(userRepo repositories.UserRepository, deviceRepo repositories.DeviceRepository) UserUsecase {
+func NewUserUsecase(userRepo repositories.UserRepository, deviceRepo repositories.DeviceRepository, topics TopicUsecase) UserUsecase {
 	return &userUsecase{
 		userRepo:   userRepo,
 		deviceRepo: deviceRepo,
+		topics:     topics,
 	}
 }
 
@@ -79,6 +87,75 @@ func (u *userUsecase) SyncUserFromSocial(ctx context.Context, input dto.CreateUs
 	return newUser, nil
 }
 
-func (u *userUsecase) UpsertDevice(ctx context.Context, device *entities.UserDevice) error {
-	return u.deviceRepo.UpsertDevice(ctx, device)
+// UpsertDevice registers a signed-in device; a device ID of another user is
+// registered as a new device. It returns the topic changes the device needs:
+// leaving BroadcastTopic and, for a known device back with a new push token,
+// moving its topic subscriptions to it. They call FCM, so the caller runs
+// them once the device is committed rather than inside a transaction.
+func (u *userUsecase) UpsertDevice(ctx context.Context, device *entities.UserDevice) (func(ctx context.Context) error, error) {
+	oldToken := ""
+	if device.ID != uuid.Nil {
+		existing, err := u.deviceRepo.FindByID(ctx, device.ID)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && existing.UserID != device.UserID):
+			device.ID = uuid.Nil
+		case err != nil:
+			return nil, err
+		default:
+			oldToken = existing.PushToken
+		}
+	}
+
+	if err := u.deviceRepo.UpsertDevice(ctx, device); err != nil {
+		return nil, err
+	}
+	saved := *device
+	return func(ctx context.Context) error {
+		return u.syncTopics(ctx, saved, oldToken)
+	}, nil
+}
+
+// syncTopics takes device out of BroadcastTopic and, when its push token
+// replaced oldToken, moves its topic subscriptions to the new token.
+func (u *userUsecase) syncTopics(ctx context.Context, device entities.UserDevice, oldToken string) error {
+	err := u.topics.LeaveBroadcastTopic(ctx, device)
+	if oldToken != "" && device.PushToken != "" && oldToken != device.PushToken {
+		err = errors.Join(err, u.topics.ResyncDevice(ctx, device.ID, oldToken, device.PushToken))
+	}
+	return err
+}
+
+func (u *userUsecase) UpdateDeviceLocation(ctx context.Context, userID, deviceID uuid.UUID, lat, lng float64) error {
+	err := u.deviceRepo.UpdateLocation(ctx, userID, deviceID, lat, lng)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrDeviceNotFound
+	}
+	return err
+}
+
+// UpdateHomeLocation sets the user's home, or clears it when lat and lng are nil.
+func (u *userUsecase) UpdateHomeLocation(ctx context.Context, userID uuid.UUID, lat, lng *float64) error {
+	return u.userRepo.UpdateHomeLocation(ctx, userID, lat, lng)
+}
+
+// RotatePushToken replaces the push token of one of the user's devices and
+// moves its topic subscriptions to the new token.
+func (u *userUsecase) RotatePushToken(ctx context.Context, userID, deviceID uuid.UUID, token string) error {
+	device, err := u.deviceRepo.FindByID(ctx, deviceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && device.UserID != userID) {
+		return ErrDeviceNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if device.PushToken == token {
+		return nil
+	}
+
+	if err := u.deviceRepo.UpdatePushToken(ctx, deviceID, token); err != nil {
+		return fmt.Errorf("failed to update push token: %v", err)
+	}
+	oldToken := device.PushToken
+	device.PushToken = token
+	return u.syncTopics(ctx, *device, oldToken)
 }
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/dto"
+	implRepositories "pbmap_api/src/internal/repositories"
+	"pbmap_api/src/pkg/config"
+	"pbmap_api/src/pkg/webpush"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWebPushNotConfigured = errors.New("web push is not configured")
+	ErrInvalidSubscription  = errors.New("invalid web push subscription")
+)
+
+// WebPushUsecase registers browsers as web push devices.
+type WebPushUsecase interface {
+	Subscribe(ctx context.Context, userID uuid.UUID, req *dto.WebPushSubscriptionRequest) (*entities.UserDevice, error)
+	Unsubscribe(ctx context.Context, userID, deviceID uuid.UUID) error
+	PublicKey() (string, error)
+}
+
+type webPushUsecase struct {
+	deviceRepo       repositories.DeviceRepository
+	subscriptionRepo repositories.WebPushSubscriptionRepository
+	tm               implRepositories.TransactionManager
+	publicKey        string
+}
+
+func NewWebPushUsecase(deviceRepo repositories.DeviceRepository, subscriptionRepo repositories.WebPushSubscriptionRepository, tm implRepositories.TransactionManager, cfg *config.Config) WebPushUsecase {
+	u := &webPushUsecase{deviceRepo: deviceRepo, subscriptionRepo: subscriptionRepo, tm: tm}
+	if cfg.VAPIDPrivateKey != "" {
+		if vapid, err := webpush.NewVAPID(cfg.VAPIDPrivateKey, cfg.VAPIDSubject); err == nil {
+			u.publicKey = vapid.PublicKey()
+		}
+	}
+	return u
+}
+
+func (u *webPushUsecase) PublicKey() (string, error) {
+	if u.publicKey == "" {
+		return "", ErrWebPushNotConfigured
+	}
+	return u.publicKey, nil
+}
+
+// Subscribe stores the subscription and registers its endpoint as the push
+// token of a web device, creating the device unless req.DeviceID names one.
+func (u *webPushUsecase) Subscribe(ctx context.Context, userID uuid.UUID, req *dto.WebPushSubscriptionRequest) (*entities.UserDevice, error) {
+	if u.publicKey == "" {
+		return nil, ErrWebPushNotConfigured
+	}
+	if _, err := webpush.ParseSubscription(req.Endpoint, req.Keys.P256dh, req.Keys.Auth); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+
+	var device *entities.UserDevice
+	err := u.tm.Do(ctx, func(ctx context.Context) error {
+		if req.DeviceID != nil {
+			existing, err := u.ownedDevice(ctx, userID, *req.DeviceID)
+			if err != nil {
+				return err
+			}
+			if existing.Provider != ProviderWebPush {
+				return fmt.Errorf("%w: device %s is not a web push device", ErrInvalidSubscription, existing.ID)
+			}
+			if existing.PushToken != req.Endpoint {
+				if err := u.deviceRepo.UpdatePushToken(ctx, existing.ID, req.Endpoint); err != nil {
+					return fmt.Errorf("failed to update push token: %v", err)
+				}
+				existing.PushToken = req.Endpoint
+				existing.InvalidatedAt = nil
+				existing.InvalidReason = ""
+			}
+			device = existing
+		} else {
+			device = &entities.UserDevice{
+				UserID:     userID,
+				Provider:   ProviderWebPush,
+				DeviceType: "web",
+				PushToken:  req.Endpoint,
+				Locale:     normalizeLocale(req.Locale),
+				LastSeen:   time.Now(),
+			}
+			if err := u.deviceRepo.UpsertDevice(ctx, device); err != nil {
+				return fmt.Errorf("failed to register device: %v", err)
+			}
+		}
+
+		return u.subscriptionRepo.Upsert(ctx, &entities.WebPushSubscription{
+			DeviceID: device.ID,
+			Endpoint: req.Endpoint,
+			P256dh:   req.Keys.P256dh,
+			Auth:     req.Keys.Auth,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// Unsubscribe drops the device's subscription and stops sending to it.
+func (u *webPushUsecase) Unsubscribe(ctx context.Context, userID, deviceID uuid.UUID) error {
+	device, err := u.ownedDevice(ctx, userID, deviceID)
+	if err != nil {
+		return err
+	}
+	return u.tm.Do(ctx, func(ctx context.Context) error {
+		if err := u.subscriptionRepo.DeleteByDevice(ctx, device.ID); err != nil {
+			return err
+		}
+		_, err := u.deviceRepo.InvalidatePushTokens(ctx, []string{device.PushToken}, "unsubscribed")
+		return err
+	})
+}
+
+func (u *webPushUsecase) ownedDevice(ctx context.Context, userID, deviceID uuid.UUID) (*entities.UserDevice, error) {
+	device, err := u.deviceRepo.FindByID(ctx, deviceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && device.UserID != userID) {
+		return nil, ErrDeviceNotFound
+	}
+	return device, err
+}
@@ -0,0 +1,116 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/usecase"
+)
+
+const (
+	// claimBatchSize bounds how many due jobs or outbox messages one poll
+	// takes on, one at a time.
+	claimBatchSize = 10
+	// maxRetryBackoff caps the exponential backoff between attempts.
+	maxRetryBackoff = 30 * time.Minute
+)
+
+// jobRunner drains due jobs from the queue.
+type jobRunner struct {
+	queue   *RedisQueue
+	run     func(ctx context.Context, job *entities.Job) error
+	lease   time.Duration
+	backoff time.Duration
+}
+
+// poll hands expired leases back to the queue, then claims and runs due jobs
+// one at a time, keeping the lease on each while it runs. Failed jobs are
+// retried with exponential backoff until they run out of attempts or fail
+// permanently, and then moved to the dead-letter list.
+func (r *jobRunner) poll(ctx context.Context) error {
+	if n, err := r.queue.RequeueExpired(ctx); err != nil {
+		return fmt.Errorf("failed to requeue expired jobs: %v", err)
+	} else if n > 0 {
+		fmt.Printf("Requeued %d jobs whose lease expired\n", n)
+	}
+
+	for range claimBatchSize {
+		jobs, err := r.queue.Claim(ctx, 1, r.lease)
+		if err != nil {
+			return fmt.Errorf("failed to claim jobs: %v", err)
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+		if err := r.execute(ctx, &jobs[0]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *jobRunner) execute(ctx context.Context, job *entities.Job) error {
+	job.Attempts++
+	release := keepLeased(ctx, r.lease, "job "+job.ID, func(ctx context.Context) error {
+		return r.queue.ExtendLease(ctx, job.ID, r.lease)
+	})
+	runErr := r.run(ctx, job)
+	release()
+	if runErr == nil {
+		return r.queue.Ack(ctx, job)
+	}
+
+	job.LastError = runErr.Error()
+	if errors.Is(runErr, usecase.ErrJobRejected) || job.Attempts >= job.MaxAttempts {
+		now := time.Now()
+		job.FailedAt = &now
+		fmt.Printf("Warning: job %s (%s) moved to dead-letter list after %d attempts: %v\n", job.ID, job.Type, job.Attempts, runErr)
+		return r.queue.Bury(ctx, job)
+	}
+
+	job.RunAt = time.Now().Add(retryDelay(r.backoff, job.Attempts))
+	fmt.Printf("Warning: job %s (%s) failed, retrying at %s: %v\n", job.ID, job.Type, job.RunAt.Format(time.RFC3339), runErr)
+	return r.queue.Retry(ctx, job)
+}
+
+// retryDelay doubles the base backoff for every attempt made so far.
+func retryDelay(backoff time.Duration, attempts int) time.Duration {
+	delay := backoff
+	for i := 1; i < attempts && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+	return delay
+}
+
+// keepLeased calls extend every third of lease until the returned function is
+// called, so that a long run is not handed to another worker while it is
+// still going.
+func keepLeased(ctx context.Context, lease time.Duration, what string, extend func(ctx context.Context) error) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := extend(ctx); err != nil && ctx.Err() == nil {
+					fmt.Printf("Warning: failed to extend lease on %s: %v\n", what, err)
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
@@ -1,12 +1,74 @@
 package worker
 
 import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/usecase"
 	"pbmap_api/src/pkg/config"
 )
 
+// Dependencies holds the usecases that background jobs operate on.
+type Dependencies struct {
+	Alarm      usecase.AlarmUsecase
+	Schedule   usecase.ScheduleUsecase
+	Queue      *RedisQueue
+	Outbox     usecase.OutboxUsecase
+	OutboxRepo repositories.OutboxRepository
+}
+
 // StartBackgroundJobs starts background jobs. Returns a cleanup function.
-// Adapter/data_sync were removed; add new jobs here when needed.
-func StartBackgroundJobs(cfg *config.Config) func() {
-	_ = cfg
-	return func() {}
+func StartBackgroundJobs(cfg *config.Config, deps Dependencies) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	runEvery(ctx, &wg, "alarm expiry", cfg.AlarmExpiryInterval, deps.Alarm.ExpireDueAlarms)
+
+	outbox := &outboxRunner{
+		repo:    deps.OutboxRepo,
+		run:     deps.Outbox.Run,
+		lease:   cfg.OutboxLease,
+		backoff: cfg.OutboxRetryBackoff,
+	}
+	runEvery(ctx, &wg, "outbox", cfg.OutboxPollInterval, outbox.poll)
+
+	if deps.Queue != nil && deps.Queue.client != nil {
+		runner := &jobRunner{
+			queue:   deps.Queue,
+			run:     deps.Schedule.Run,
+			lease:   cfg.JobLease,
+			backoff: cfg.JobRetryBackoff,
+		}
+		runEvery(ctx, &wg, "scheduled jobs", cfg.JobPollInterval, runner.poll)
+	}
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// runEvery runs job on every tick of interval until ctx is cancelled.
+func runEvery(ctx context.Context, wg *sync.WaitGroup, name string, interval time.Duration, job func(ctx context.Context) error) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := job(ctx); err != nil {
+					fmt.Printf("Warning: background job %q failed: %v\n", name, err)
+				}
+			}
+		}
+	}()
 }
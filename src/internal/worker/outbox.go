@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+	"pbmap_api/src/internal/usecase"
+
+	"gorm.io/datatypes"
+)
+
+// outboxRunner delivers the sends queued in the outbox.
+type outboxRunner struct {
+	repo    repositories.OutboxRepository
+	run     func(ctx context.Context, message *entities.OutboxMessage) (json.RawMessage, error)
+	lease   time.Duration
+	backoff time.Duration
+}
+
+// poll claims and delivers due messages one at a time, so that an alarm
+// queued meanwhile is next in line. Failed sends are retried with exponential
+// backoff until they run out of attempts or fail permanently. A message whose
+// worker died mid-send is claimed again once its lease runs out, so every
+// send is made at least once.
+func (r *outboxRunner) poll(ctx context.Context) error {
+	for range claimBatchSize {
+		message, err := r.repo.Claim(ctx, r.lease)
+		if err != nil {
+			return fmt.Errorf("failed to claim outbox message: %v", err)
+		}
+		if message == nil {
+			return nil
+		}
+		if err := r.deliver(ctx, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *outboxRunner) deliver(ctx context.Context, message *entities.OutboxMessage) error {
+	release := keepLeased(ctx, r.lease, "outbox send "+message.ID.String(), func(ctx context.Context) error {
+		return r.repo.ExtendLease(ctx, message.ID, r.lease)
+	})
+	result, runErr := r.run(ctx, message)
+	release()
+
+	now := time.Now()
+	message.LockedUntil = nil
+	switch {
+	case runErr == nil:
+		message.Status = usecase.OutboxStatusSent
+		message.Result = datatypes.JSON(result)
+		message.LastError = ""
+		message.SentAt = &now
+	case errors.Is(runErr, usecase.ErrJobRejected) || message.Attempts >= message.MaxAttempts:
+		message.Status = usecase.OutboxStatusFailed
+		message.LastError = runErr.Error()
+		message.FailedAt = &now
+		fmt.Printf("Warning: outbox %s send %s failed after %d attempts: %v\n", message.Kind, message.ID, message.Attempts, runErr)
+	default:
+		message.LastError = runErr.Error()
+		message.NextAttemptAt = now.Add(retryDelay(r.backoff, message.Attempts))
+		fmt.Printf("Warning: outbox %s send %s failed, retrying at %s: %v\n", message.Kind, message.ID, message.NextAttemptAt.Format(time.RFC3339), runErr)
+	}
+
+	if err := r.repo.Update(ctx, message); err != nil {
+		return fmt.Errorf("failed to save outbox message %s: %v", message.ID, err)
+	}
+	return nil
+}
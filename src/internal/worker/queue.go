@@ -0,0 +1,396 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"pbmap_api/src/internal/domain/entities"
+	"pbmap_api/src/internal/domain/repositories"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Ensure RedisQueue implements repositories.JobQueue.
+var _ repositories.JobQueue = (*RedisQueue)(nil)
+
+const (
+	scheduledKey  = "jobs:scheduled"  // sorted set of job IDs scored by run time (unix ms)
+	processingKey = "jobs:processing" // sorted set of claimed job IDs scored by lease expiry (unix ms)
+	dataKey       = "jobs:data"       // hash of job ID to job JSON
+	deadKey       = "jobs:dead"       // list of failed job JSON, newest first
+
+	// deadLetterLimit caps the dead-letter list.
+	deadLetterLimit = 1000
+)
+
+// claimScript moves up to ARGV[3] due jobs from the scheduled set to the
+// processing set with a lease until ARGV[2].
+var claimScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[3]))
+for _, id in ipairs(ids) do
+	redis.call('ZREM', KEYS[1], id)
+	redis.call('ZADD', KEYS[2], ARGV[2], id)
+end
+return ids
+`)
+
+// writeScheduledScript stores a job and its run time, but only while the job
+// is still waiting in the scheduled set.
+var writeScheduledScript = redis.NewScript(`
+if not redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[3])
+return 1
+`)
+
+// cancelScript removes a waiting job.
+var cancelScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HDEL', KEYS[2], ARGV[1])
+return 1
+`)
+
+// RedisQueue is a delayed job queue on Redis with at-least-once delivery:
+// a claimed job stays leased in the processing set until it is acknowledged,
+// retried or buried, and is handed out again if its lease runs out.
+type RedisQueue struct {
+	client *redis.Client
+}
+
+// NewRedisQueue creates the Redis-backed job queue.
+func NewRedisQueue(client *redis.Client) *RedisQueue {
+	return &RedisQueue{client: client}
+}
+
+func (q *RedisQueue) Enqueue(ctx context.Context, job *entities.Job) error {
+	if q.client == nil {
+		return fmt.Errorf("redis client is not initialized")
+	}
+
+	if job.ID == "" {
+		job.ID = uuid.NewString()
+	}
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now()
+	}
+	val, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, dataKey, job.ID, val)
+		pipe.ZAdd(ctx, scheduledKey, redis.Z{Score: score(job.RunAt), Member: job.ID})
+		return nil
+	})
+	return err
+}
+
+func (q *RedisQueue) Get(ctx context.Context, id string) (*entities.Job, error) {
+	if q.client == nil {
+		return nil, fmt.Errorf("redis client is not initialized")
+	}
+
+	if err := q.client.ZScore(ctx, scheduledKey, id).Err(); err != nil {
+		if err == redis.Nil {
+			return nil, repositories.ErrJobNotFound
+		}
+		return nil, err
+	}
+
+	val, err := q.client.HGet(ctx, dataKey, id).Bytes()
+	if err == redis.Nil {
+		return nil, repositories.ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeJob(val)
+}
+
+func (q *RedisQueue) Update(ctx context.Context, job *entities.Job) error {
+	return q.writeScheduled(ctx, job)
+}
+
+func (q *RedisQueue) Reschedule(ctx context.Context, id string, runAt time.Time) error {
+	job, err := q.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	job.RunAt = runAt
+	return q.writeScheduled(ctx, job)
+}
+
+func (q *RedisQueue) Cancel(ctx context.Context, id string) error {
+	if q.client == nil {
+		return fmt.Errorf("redis client is not initialized")
+	}
+
+	removed, err := cancelScript.Run(ctx, q.client, []string{scheduledKey, dataKey}, id).Int()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return repositories.ErrJobNotFound
+	}
+	return nil
+}
+
+// ListScheduled returns the jobs being run, followed by those waiting to run
+// in run-time order.
+func (q *RedisQueue) ListScheduled(ctx context.Context) ([]entities.Job, error) {
+	if q.client == nil {
+		return nil, fmt.Errorf("redis client is not initialized")
+	}
+
+	var processing, scheduled *redis.StringSliceCmd
+	_, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		processing = pipe.ZRange(ctx, processingKey, 0, -1)
+		scheduled = pipe.ZRange(ctx, scheduledKey, 0, -1)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return q.load(ctx, append(processing.Val(), scheduled.Val()...))
+}
+
+func (q *RedisQueue) ListDead(ctx context.Context) ([]entities.Job, error) {
+	if q.client == nil {
+		return nil, fmt.Errorf("redis client is not initialized")
+	}
+
+	vals, err := q.client.LRange(ctx, deadKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]entities.Job, 0, len(vals))
+	for _, val := range vals {
+		job, err := decodeJob([]byte(val))
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, nil
+}
+
+// Claim leases up to limit due jobs to the caller for the given duration.
+func (q *RedisQueue) Claim(ctx context.Context, limit int, lease time.Duration) ([]entities.Job, error) {
+	if q.client == nil {
+		return nil, fmt.Errorf("redis client is not initialized")
+	}
+
+	now := time.Now()
+	ids, err := claimScript.Run(ctx, q.client, []string{scheduledKey, processingKey},
+		score(now), score(now.Add(lease)), limit).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	return q.load(ctx, ids)
+}
+
+// ExtendLease pushes the lease on a claimed job back to lease from now. A job
+// no longer being processed is left alone.
+func (q *RedisQueue) ExtendLease(ctx context.Context, id string, lease time.Duration) error {
+	return q.client.ZAddXX(ctx, processingKey, redis.Z{Score: score(time.Now().Add(lease)), Member: id}).Err()
+}
+
+// RequeueExpired hands jobs whose lease has run out back to the scheduled set,
+// so that a job claimed by a worker that died is run again. The lost run
+// counts as an attempt, and a job out of attempts goes to the dead-letter
+// list instead, so that a job that kills its worker is not retried forever.
+func (q *RedisQueue) RequeueExpired(ctx context.Context) (int, error) {
+	if q.client == nil {
+		return 0, fmt.Errorf("redis client is not initialized")
+	}
+
+	now := time.Now()
+	ids, err := q.client.ZRangeByScore(ctx, processingKey, &redis.ZRangeBy{Min: "-inf", Max: strconv.FormatInt(now.UnixMilli(), 10)}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	requeued := 0
+	for _, id := range ids {
+		err := q.client.Watch(ctx, func(tx *redis.Tx) error {
+			return q.requeue(ctx, tx, id, now)
+		}, processingKey, dataKey)
+		if errors.Is(err, redis.TxFailedErr) {
+			// Another worker acked, extended or requeued a job meanwhile; the
+			// next poll looks again.
+			continue
+		}
+		if err != nil {
+			return requeued, err
+		}
+		requeued++
+	}
+	return requeued, nil
+}
+
+// requeue hands one expired job back, within a transaction watching the
+// processing set and job data.
+func (q *RedisQueue) requeue(ctx context.Context, tx *redis.Tx, id string, now time.Time) error {
+	lease, err := tx.ZScore(ctx, processingKey, id).Result()
+	if errors.Is(err, redis.Nil) || (err == nil && lease > score(now)) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	val, err := tx.HGet(ctx, dataKey, id).Result()
+	if errors.Is(err, redis.Nil) {
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZRem(ctx, processingKey, id)
+			return nil
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	var job entities.Job
+	if err := json.Unmarshal([]byte(val), &job); err != nil {
+		return fmt.Errorf("failed to decode job %s: %v", id, err)
+	}
+	job.Attempts++
+	job.LastError = "lease expired before the job finished"
+	if job.Attempts >= job.MaxAttempts {
+		job.FailedAt = &now
+	}
+	updated, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, processingKey, id)
+		if job.FailedAt != nil {
+			pipe.HDel(ctx, dataKey, id)
+			pipe.LPush(ctx, deadKey, updated)
+			pipe.LTrim(ctx, deadKey, 0, deadLetterLimit-1)
+			return nil
+		}
+		pipe.HSet(ctx, dataKey, id, updated)
+		pipe.ZAdd(ctx, scheduledKey, redis.Z{Score: score(now), Member: id})
+		return nil
+	})
+	return err
+}
+
+// Ack removes a claimed job that ran successfully.
+func (q *RedisQueue) Ack(ctx context.Context, job *entities.Job) error {
+	_, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, processingKey, job.ID)
+		pipe.HDel(ctx, dataKey, job.ID)
+		return nil
+	})
+	return err
+}
+
+// Retry puts a claimed job back on the schedule at job.RunAt.
+func (q *RedisQueue) Retry(ctx context.Context, job *entities.Job) error {
+	val, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, processingKey, job.ID)
+		pipe.HSet(ctx, dataKey, job.ID, val)
+		pipe.ZAdd(ctx, scheduledKey, redis.Z{Score: score(job.RunAt), Member: job.ID})
+		return nil
+	})
+	return err
+}
+
+// Bury moves a claimed job to the dead-letter list.
+func (q *RedisQueue) Bury(ctx context.Context, job *entities.Job) error {
+	val, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, processingKey, job.ID)
+		pipe.HDel(ctx, dataKey, job.ID)
+		pipe.LPush(ctx, deadKey, val)
+		pipe.LTrim(ctx, deadKey, 0, deadLetterLimit-1)
+		return nil
+	})
+	return err
+}
+
+func (q *RedisQueue) writeScheduled(ctx context.Context, job *entities.Job) error {
+	if q.client == nil {
+		return fmt.Errorf("redis client is not initialized")
+	}
+
+	val, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	written, err := writeScheduledScript.Run(ctx, q.client, []string{scheduledKey, dataKey},
+		job.ID, score(job.RunAt), val).Int()
+	if err != nil {
+		return err
+	}
+	if written == 0 {
+		return repositories.ErrJobNotFound
+	}
+	return nil
+}
+
+// load fetches the jobs with the given IDs in order. IDs whose data is gone,
+// e.g. because the job was cancelled while being claimed, are skipped and
+// dropped from the processing set.
+func (q *RedisQueue) load(ctx context.Context, ids []string) ([]entities.Job, error) {
+	jobs := make([]entities.Job, 0, len(ids))
+	if len(ids) == 0 {
+		return jobs, nil
+	}
+
+	vals, err := q.client.HMGet(ctx, dataKey, ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, val := range vals {
+		s, ok := val.(string)
+		if !ok {
+			q.client.ZRem(ctx, processingKey, ids[i])
+			continue
+		}
+		job, err := decodeJob([]byte(s))
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, nil
+}
+
+func decodeJob(val []byte) (*entities.Job, error) {
+	var job entities.Job
+	if err := json.Unmarshal(val, &job); err != nil {
+		return nil, fmt.Errorf("failed to decode job: %v", err)
+	}
+	return &job, nil
+}
+
+func score(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
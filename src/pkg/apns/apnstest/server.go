@@ -0,0 +1,131 @@
+// Package apnstest provides a fake APNs server that records the notifications
+// it receives, for tests and local development.
+package apnstest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pbmap_api/src/pkg/apns"
+)
+
+// Request is a notification the fake server accepted.
+type Request struct {
+	DeviceToken string          `json:"device_token"`
+	Topic       string          `json:"topic"`
+	PushType    string          `json:"push_type"`
+	Priority    string          `json:"priority"`
+	Expiration  string          `json:"expiration,omitempty"`
+	CollapseID  string          `json:"collapse_id,omitempty"`
+	Payload     json.RawMessage `json:"payload"`
+	ReceivedAt  time.Time       `json:"received_at"`
+}
+
+// Server is a fake APNs endpoint. It accepts any well-formed notification
+// carrying a provider token, except for device tokens marked unregistered.
+type Server struct {
+	mu           sync.Mutex
+	requests     []Request
+	unregistered map[string]bool
+}
+
+func NewServer() *Server {
+	return &Server{unregistered: make(map[string]bool)}
+}
+
+// StartTLS serves the fake over HTTPS with HTTP/2, like the real service.
+// Use the returned server's Client() to talk to it.
+func (s *Server) StartTLS() *httptest.Server {
+	srv := httptest.NewUnstartedServer(s)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	return srv
+}
+
+// Unregister makes the server answer 410 Unregistered for token.
+func (s *Server) Unregister(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unregistered[token] = true
+}
+
+// Requests returns the notifications accepted so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Reset forgets recorded notifications and unregistered tokens.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.unregistered = make(map[string]bool)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.URL.Path, "/3/device/")
+	if !ok || r.Method != http.MethodPost {
+		reject(w, http.StatusNotFound, "BadPath")
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("authorization"), "bearer ") || strings.Count(r.Header.Get("authorization"), ".") != 2 {
+		reject(w, http.StatusForbidden, "InvalidProviderToken")
+		return
+	}
+	if r.Header.Get("apns-topic") == "" {
+		reject(w, http.StatusBadRequest, "MissingTopic")
+		return
+	}
+	if _, err := hex.DecodeString(token); err != nil || token == "" {
+		reject(w, http.StatusBadRequest, apns.ReasonBadDeviceToken)
+		return
+	}
+
+	var payload struct {
+		APS json.RawMessage `json:"aps"`
+	}
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil || json.Unmarshal(raw, &payload) != nil || payload.APS == nil {
+		reject(w, http.StatusBadRequest, "BadPayload")
+		return
+	}
+
+	s.mu.Lock()
+	if s.unregistered[token] {
+		s.mu.Unlock()
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusGone)
+		_ = json.NewEncoder(w).Encode(map[string]any{"reason": apns.ReasonUnregistered, "timestamp": time.Now().UnixMilli()})
+		return
+	}
+	s.requests = append(s.requests, Request{
+		DeviceToken: token,
+		Topic:       r.Header.Get("apns-topic"),
+		PushType:    r.Header.Get("apns-push-type"),
+		Priority:    r.Header.Get("apns-priority"),
+		Expiration:  r.Header.Get("apns-expiration"),
+		CollapseID:  r.Header.Get("apns-collapse-id"),
+		Payload:     raw,
+		ReceivedAt:  time.Now(),
+	})
+	s.mu.Unlock()
+
+	w.Header().Set("apns-id", uuid.NewString())
+	w.WriteHeader(http.StatusOK)
+}
+
+func reject(w http.ResponseWriter, status int, reason string) {
+	w.Header().Set("content-type", "application/json")
+	w.Header().Set("apns-id", uuid.NewString())
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"reason": reason})
+}
@@ -0,0 +1,229 @@
+package apns
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	ProductionEndpoint  = "https://api.push.apple.com"
+	DevelopmentEndpoint = "https://api.sandbox.push.apple.com"
+
+	// Apple rejects provider tokens older than an hour and throttles clients
+	// that refresh them more often than every 20 minutes.
+	providerTokenTTL = 50 * time.Minute
+)
+
+// Reasons APNs gives for rejecting a notification.
+const (
+	ReasonBadDeviceToken         = "BadDeviceToken"
+	ReasonDeviceTokenNotForTopic = "DeviceTokenNotForTopic"
+	ReasonUnregistered           = "Unregistered"
+	ReasonExpiredProviderToken   = "ExpiredProviderToken"
+)
+
+// Push types and priorities.
+const (
+	PushTypeAlert      = "alert"
+	PushTypeBackground = "background"
+
+	PriorityImmediate = 10
+	PriorityThrottled = 5
+)
+
+// MaxCollapseIDLength is the longest apns-collapse-id APNs accepts, in bytes.
+const MaxCollapseIDLength = 64
+
+// Config holds the token-based (.p8 key) credentials of an APNs provider.
+type Config struct {
+	Endpoint string // ProductionEndpoint, DevelopmentEndpoint or a fake server
+	KeyID    string
+	TeamID   string
+	Topic    string // the app's bundle ID
+	Key      []byte // contents of the .p8 file
+}
+
+// Notification is a single push to one device.
+type Notification struct {
+	DeviceToken string
+	PushType    string
+	Priority    int
+	Expiration  *time.Time
+	CollapseID  string
+	Payload     []byte
+}
+
+// Response is APNs' answer for one notification.
+type Response struct {
+	StatusCode int
+	ID         string // apns-id
+	Reason     string
+	Timestamp  int64 // for 410 responses, when the token stopped being valid (ms)
+}
+
+// Sent reports whether APNs accepted the notification.
+func (r *Response) Sent() bool {
+	return r.StatusCode == http.StatusOK
+}
+
+// Client sends notifications to APNs over HTTP/2.
+type Client struct {
+	endpoint string
+	keyID    string
+	teamID   string
+	topic    string
+	key      *ecdsa.PrivateKey
+	http     *http.Client
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewClient creates an APNs client. httpClient may be nil, in which case an
+// HTTP/2 client suited to cfg.Endpoint is used; plain http:// endpoints are
+// spoken to over unencrypted HTTP/2, as the local fake server does.
+func NewClient(cfg Config, httpClient *http.Client) (*Client, error) {
+	if cfg.KeyID == "" || cfg.TeamID == "" || cfg.Topic == "" {
+		return nil, errors.New("apns: key ID, team ID and topic are required")
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(cfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("apns: invalid .p8 key: %v", err)
+	}
+
+	endpoint := strings.TrimRight(cfg.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = ProductionEndpoint
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Transport: newTransport(endpoint), Timeout: 30 * time.Second}
+	}
+
+	return &Client{
+		endpoint: endpoint,
+		keyID:    cfg.KeyID,
+		teamID:   cfg.TeamID,
+		topic:    cfg.Topic,
+		key:      key,
+		http:     httpClient,
+	}, nil
+}
+
+func newTransport(endpoint string) *http.Transport {
+	transport := &http.Transport{
+		ForceAttemptHTTP2:   true,
+		MaxIdleConnsPerHost: 4,
+		IdleConnTimeout:     5 * time.Minute,
+	}
+	if strings.HasPrefix(endpoint, "http://") {
+		var protocols http.Protocols
+		protocols.SetUnencryptedHTTP2(true)
+		transport.Protocols = &protocols
+	}
+	return transport
+}
+
+// Push sends n and returns APNs' response. An error is only returned when no
+// response was received.
+func (c *Client) Push(ctx context.Context, n *Notification) (*Response, error) {
+	resp, err := c.push(ctx, n)
+	if err == nil && resp.Reason == ReasonExpiredProviderToken {
+		c.resetProviderToken()
+		resp, err = c.push(ctx, n)
+	}
+	return resp, err
+}
+
+func (c *Client) push(ctx context.Context, n *Notification) (*Response, error) {
+	token, err := c.providerToken()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/3/device/"+n.DeviceToken, bytes.NewReader(n.Payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("authorization", "bearer "+token)
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("apns-topic", c.topic)
+	if n.PushType != "" {
+		req.Header.Set("apns-push-type", n.PushType)
+	}
+	if n.Priority != 0 {
+		req.Header.Set("apns-priority", strconv.Itoa(n.Priority))
+	}
+	if n.Expiration != nil {
+		req.Header.Set("apns-expiration", strconv.FormatInt(n.Expiration.Unix(), 10))
+	}
+	if n.CollapseID != "" {
+		req.Header.Set("apns-collapse-id", n.CollapseID)
+	}
+
+	httpResp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	resp := &Response{StatusCode: httpResp.StatusCode, ID: httpResp.Header.Get("apns-id")}
+	if resp.Sent() {
+		return resp, nil
+	}
+
+	var body struct {
+		Reason    string `json:"reason"`
+		Timestamp int64  `json:"timestamp"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(httpResp.Body, 4096))
+	if err := json.Unmarshal(data, &body); err != nil {
+		body.Reason = http.StatusText(httpResp.StatusCode)
+	}
+	resp.Reason = body.Reason
+	resp.Timestamp = body.Timestamp
+	return resp, nil
+}
+
+// providerToken returns the cached ES256 provider token, signing a new one
+// when it is about to expire.
+func (c *Client) providerToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.token != "" && now.Sub(c.issuedAt) < providerTokenTTL {
+		return c.token, nil
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": c.teamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = c.keyID
+	signed, err := token.SignedString(c.key)
+	if err != nil {
+		return "", fmt.Errorf("apns: failed to sign provider token: %v", err)
+	}
+	c.token = signed
+	c.issuedAt = now
+	return signed, nil
+}
+
+func (c *Client) resetProviderToken() {
+	c.mu.Lock()
+	c.token = ""
+	c.mu.Unlock()
+}
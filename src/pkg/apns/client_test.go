@@ -0,0 +1,124 @@
+package apns_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"pbmap_api/src/pkg/apns"
+	"pbmap_api/src/pkg/apns/apnstest"
+)
+
+const deviceToken = "a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"
+
+// p8Key returns a freshly generated .p8 key in PEM form.
+func p8Key(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func newClient(t *testing.T) (*apns.Client, *apnstest.Server) {
+	t.Helper()
+	fake := apnstest.NewServer()
+	srv := fake.StartTLS()
+	t.Cleanup(srv.Close)
+
+	client, err := apns.NewClient(apns.Config{
+		Endpoint: srv.URL,
+		KeyID:    "KEY123",
+		TeamID:   "TEAM123",
+		Topic:    "com.example.pbmap",
+		Key:      p8Key(t),
+	}, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, fake
+}
+
+func TestPush(t *testing.T) {
+	client, fake := newClient(t)
+
+	expiration := time.Unix(1900000000, 0)
+	resp, err := client.Push(context.Background(), &apns.Notification{
+		DeviceToken: deviceToken,
+		PushType:    apns.PushTypeAlert,
+		Priority:    apns.PriorityImmediate,
+		Expiration:  &expiration,
+		CollapseID:  "alarm-1",
+		Payload:     []byte(`{"aps":{"alert":"hello"}}`),
+	})
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if !resp.Sent() || resp.ID == "" {
+		t.Fatalf("response = %+v, want sent with an apns-id", resp)
+	}
+
+	requests := fake.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	got := requests[0]
+	if got.DeviceToken != deviceToken || got.Topic != "com.example.pbmap" || got.PushType != "alert" ||
+		got.Priority != "10" || got.Expiration != "1900000000" || got.CollapseID != "alarm-1" {
+		t.Fatalf("request = %+v", got)
+	}
+}
+
+func TestPushRejected(t *testing.T) {
+	client, fake := newClient(t)
+	fake.Unregister(deviceToken)
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+		wantReason string
+	}{
+		{"unregistered", deviceToken, http.StatusGone, apns.ReasonUnregistered},
+		{"bad token", "not-hex", http.StatusBadRequest, apns.ReasonBadDeviceToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Push(context.Background(), &apns.Notification{
+				DeviceToken: tt.token,
+				Payload:     []byte(`{"aps":{}}`),
+			})
+			if err != nil {
+				t.Fatalf("Push: %v", err)
+			}
+			if resp.Sent() || resp.StatusCode != tt.wantStatus || resp.Reason != tt.wantReason {
+				t.Fatalf("response = %+v, want %d %s", resp, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+	if n := len(fake.Requests()); n != 0 {
+		t.Fatalf("fake recorded %d rejected requests", n)
+	}
+}
+
+func TestNewClientRequiresCredentials(t *testing.T) {
+	if _, err := apns.NewClient(apns.Config{Key: p8Key(t)}, nil); err == nil {
+		t.Fatal("expected missing key ID, team ID and topic to fail")
+	}
+	_, err := apns.NewClient(apns.Config{KeyID: "K", TeamID: "T", Topic: "t", Key: []byte("not a key")}, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid .p8 key") {
+		t.Fatalf("error = %v, want invalid key", err)
+	}
+}
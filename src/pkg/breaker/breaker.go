@@ -0,0 +1,148 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// States of a Breaker.
+const (
+	StateClosed   = "closed"    // calls go through
+	StateOpen     = "open"      // calls fail fast until the cooldown is over
+	StateHalfOpen = "half_open" // one trial call decides whether to close again
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+// Config tunes a Breaker.
+type Config struct {
+	Threshold int           // consecutive failures that open the breaker; 5 when 0
+	Cooldown  time.Duration // how long it stays open before a trial call; 30s when 0
+}
+
+// Status is a snapshot of a Breaker.
+type Status struct {
+	State               string
+	ConsecutiveFailures int
+	OpenedAt            *time.Time
+	RetryAt             *time.Time // when the next trial call is let through, while open
+}
+
+// Breaker stops calls to a failing dependency. After Threshold consecutive
+// failures it opens and refuses calls for Cooldown; the first call after that
+// is a trial whose outcome closes or reopens it.
+//
+// Every state change starts a new generation. Allow hands out the current
+// one, and outcomes reported for an earlier generation are ignored, so that a
+// slow call made while closed neither closes a half-open breaker nor pushes
+// back the cooldown of an open one.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu         sync.Mutex
+	state      string
+	generation uint64
+	failures   int
+	openedAt   time.Time
+	trial      bool // a half-open trial call is in flight
+}
+
+func New(cfg Config) *Breaker {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 5
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	return &Breaker{threshold: cfg.Threshold, cooldown: cfg.Cooldown, now: time.Now, state: StateClosed}
+}
+
+// Allow reports whether a call may be made, returning ErrOpen when not.
+// Every allowed call must be followed by Success, Failure or Abort with the
+// generation returned.
+func (b *Breaker) Allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Before(b.openedAt.Add(b.cooldown)) {
+			return 0, ErrOpen
+		}
+		b.setState(StateHalfOpen)
+		b.trial = true
+	case StateHalfOpen:
+		if b.trial {
+			return 0, ErrOpen
+		}
+		b.trial = true
+	}
+	return b.generation, nil
+}
+
+// Success records a call that went through and closes the breaker.
+func (b *Breaker) Success(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if generation != b.generation {
+		return
+	}
+	b.failures = 0
+	b.trial = false
+	if b.state != StateClosed {
+		b.setState(StateClosed)
+	}
+}
+
+// Failure records a failed call, opening the breaker once the threshold is
+// reached or when a trial call fails.
+func (b *Breaker) Failure(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if generation != b.generation {
+		return
+	}
+	b.failures++
+	b.trial = false
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.setState(StateOpen)
+		b.openedAt = b.now()
+	}
+}
+
+// Abort records a call whose outcome says nothing about the dependency, such
+// as one the caller cancelled. A half-open breaker lets another trial through.
+func (b *Breaker) Abort(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if generation != b.generation {
+		return
+	}
+	b.trial = false
+}
+
+// setState moves the breaker to state and starts a new generation. b.mu must
+// be held.
+func (b *Breaker) setState(state string) {
+	b.state = state
+	b.generation++
+}
+
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := Status{State: b.state, ConsecutiveFailures: b.failures}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	if b.state == StateOpen {
+		retryAt := b.openedAt.Add(b.cooldown)
+		status.RetryAt = &retryAt
+	}
+	return status
+}
@@ -0,0 +1,161 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+)
+
+// testBreaker returns a breaker with a threshold of 3 and a one-minute
+// cooldown whose clock only moves through advance.
+func testBreaker() (*Breaker, func(time.Duration)) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	b := New(Config{Threshold: 3, Cooldown: time.Minute})
+	b.now = func() time.Time { return now }
+	return b, func(d time.Duration) { now = now.Add(d) }
+}
+
+func mustAllow(t *testing.T, b *Breaker) uint64 {
+	t.Helper()
+	generation, err := b.Allow()
+	if err != nil {
+		t.Fatalf("Allow() in state %s: %v", b.Status().State, err)
+	}
+	return generation
+}
+
+func mustRefuse(t *testing.T, b *Breaker) {
+	t.Helper()
+	if _, err := b.Allow(); err != ErrOpen {
+		t.Fatalf("Allow() in state %s = %v, want ErrOpen", b.Status().State, err)
+	}
+}
+
+func wantState(t *testing.T, b *Breaker, state string) {
+	t.Helper()
+	if got := b.Status().State; got != state {
+		t.Fatalf("state = %s, want %s", got, state)
+	}
+}
+
+// trip opens b with threshold consecutive failures.
+func trip(t *testing.T, b *Breaker) {
+	t.Helper()
+	for range b.threshold {
+		b.Failure(mustAllow(t, b))
+	}
+	wantState(t, b, StateOpen)
+}
+
+func TestBreakerOpensAtThreshold(t *testing.T) {
+	b, _ := testBreaker()
+	b.Failure(mustAllow(t, b))
+	b.Failure(mustAllow(t, b))
+	b.Success(mustAllow(t, b))
+	if got := b.Status().ConsecutiveFailures; got != 0 {
+		t.Fatalf("failures after a success = %d, want 0", got)
+	}
+	b.Failure(mustAllow(t, b))
+	b.Failure(mustAllow(t, b))
+	wantState(t, b, StateClosed)
+	b.Failure(mustAllow(t, b))
+	wantState(t, b, StateOpen)
+	mustRefuse(t, b)
+}
+
+func TestBreakerCooldown(t *testing.T) {
+	b, advance := testBreaker()
+	trip(t, b)
+
+	status := b.Status()
+	if status.RetryAt == nil || !status.RetryAt.Equal(status.OpenedAt.Add(time.Minute)) {
+		t.Fatalf("status = %+v, want a retry one cooldown after opening", status)
+	}
+	advance(time.Minute - time.Second)
+	mustRefuse(t, b)
+
+	advance(time.Second)
+	trial := mustAllow(t, b)
+	wantState(t, b, StateHalfOpen)
+	mustRefuse(t, b) // one trial at a time
+	b.Success(trial)
+	wantState(t, b, StateClosed)
+	mustAllow(t, b)
+}
+
+func TestBreakerFailedTrialReopens(t *testing.T) {
+	b, advance := testBreaker()
+	trip(t, b)
+	advance(time.Minute)
+
+	b.Failure(mustAllow(t, b))
+	wantState(t, b, StateOpen)
+	mustRefuse(t, b)
+	advance(time.Minute)
+	mustAllow(t, b)
+}
+
+func TestBreakerAbortedTrialLetsAnotherThrough(t *testing.T) {
+	b, advance := testBreaker()
+	trip(t, b)
+	advance(time.Minute)
+
+	b.Abort(mustAllow(t, b))
+	wantState(t, b, StateHalfOpen)
+	b.Success(mustAllow(t, b))
+	wantState(t, b, StateClosed)
+}
+
+func TestBreakerIgnoresStaleOutcomes(t *testing.T) {
+	t.Run("closed-era success does not close a half-open breaker", func(t *testing.T) {
+		b, advance := testBreaker()
+		slow := mustAllow(t, b)
+		trip(t, b)
+		advance(time.Minute)
+		trial := mustAllow(t, b)
+
+		b.Success(slow)
+		wantState(t, b, StateHalfOpen)
+		mustRefuse(t, b)
+		b.Failure(trial)
+		wantState(t, b, StateOpen)
+	})
+
+	t.Run("late failure does not push back the cooldown", func(t *testing.T) {
+		b, advance := testBreaker()
+		slow := mustAllow(t, b)
+		trip(t, b)
+		openedAt := *b.Status().OpenedAt
+
+		advance(30 * time.Second)
+		b.Failure(slow)
+		if got := *b.Status().OpenedAt; !got.Equal(openedAt) {
+			t.Fatalf("opened at %s after a late failure, want %s", got, openedAt)
+		}
+		advance(30 * time.Second)
+		mustAllow(t, b)
+	})
+
+	t.Run("closed-era abort does not free the trial", func(t *testing.T) {
+		b, advance := testBreaker()
+		slow := mustAllow(t, b)
+		trip(t, b)
+		advance(time.Minute)
+		mustAllow(t, b)
+
+		b.Abort(slow)
+		mustRefuse(t, b)
+	})
+
+	t.Run("failure from before a reopen does not count", func(t *testing.T) {
+		b, advance := testBreaker()
+		trip(t, b)
+		advance(time.Minute)
+		b.Success(mustAllow(t, b))
+
+		stale := uint64(0)
+		b.Failure(stale)
+		if got := b.Status().ConsecutiveFailures; got != 0 {
+			t.Fatalf("failures = %d, want the stale failure ignored", got)
+		}
+	})
+}
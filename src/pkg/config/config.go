@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -16,11 +17,45 @@ type Config struct {
 	DBZone                  string
 	JWTSecret               string
 	FirebaseCredentialsPath string
+	FCMProvider             string
+	FCMEmulatorLog          string
+	FCMCallTimeout          time.Duration
+	FCMMaxRetries           int
+	FCMRetryBackoff         time.Duration
+	FCMBreakerThreshold     int
+	FCMBreakerCooldown      time.Duration
+	APNsKeyPath             string
+	APNsKeyID               string
+	APNsTeamID              string
+	APNsTopic               string
+	APNsEndpoint            string
+	VAPIDPrivateKey         string
+	VAPIDSubject            string
 	RedisHost               string
 	RedisPort               string
 	RedisPass               string
 	GoogleClientID          string
 	LineChannelID           string
+	LineChannelAccessToken  string
+	LineAPIEndpoint         string
+	LineRateLimit           int
+	SMSProvider             string
+	SMSGatewayURL           string
+	SMSGatewayAPIKey        string
+	SMSSenderName           string
+	AlarmExpiryInterval     time.Duration
+	AlarmApprovalWindow     time.Duration
+	CAPSender               string
+	DefaultLocale           string
+	DefaultTimeZone         string
+	JobPollInterval         time.Duration
+	JobLease                time.Duration
+	JobRetryBackoff         time.Duration
+	JobMaxAttempts          int
+	OutboxPollInterval      time.Duration
+	OutboxLease             time.Duration
+	OutboxRetryBackoff      time.Duration
+	OutboxMaxAttempts       int
 }
 
 func LoadConfig() *Config {
@@ -41,11 +76,45 @@ func LoadConfig() *Config {
 		DBZone:                  getEnv("DB_ZONE", "Asia/Bangkok"),
 		JWTSecret:               getEnv("JWT_SECRET", "super-secret-key"),
 		FirebaseCredentialsPath: getEnv("FIREBASE_CREDENTIALS_PATH", ""),
+		FCMProvider:             getEnv("FCM_PROVIDER", "firebase"),
+		FCMEmulatorLog:          getEnv("FCM_EMULATOR_LOG", "fcm-emulator.jsonl"),
+		FCMCallTimeout:          getEnvDuration("FCM_CALL_TIMEOUT", 10*time.Second),
+		FCMMaxRetries:           getEnvCount("FCM_MAX_RETRIES", 2),
+		FCMRetryBackoff:         getEnvDuration("FCM_RETRY_BACKOFF", 500*time.Millisecond),
+		FCMBreakerThreshold:     getEnvInt("FCM_BREAKER_THRESHOLD", 5),
+		FCMBreakerCooldown:      getEnvDuration("FCM_BREAKER_COOLDOWN", 30*time.Second),
+		APNsKeyPath:             getEnv("APNS_KEY_PATH", ""),
+		APNsKeyID:               getEnv("APNS_KEY_ID", ""),
+		APNsTeamID:              getEnv("APNS_TEAM_ID", ""),
+		APNsTopic:               getEnv("APNS_TOPIC", ""),
+		APNsEndpoint:            getEnv("APNS_ENDPOINT", "https://api.push.apple.com"),
+		VAPIDPrivateKey:         getEnv("VAPID_PRIVATE_KEY", ""),
+		VAPIDSubject:            getEnv("VAPID_SUBJECT", "mailto:admin@example.com"),
 		RedisHost:               getEnv("REDIS_HOST", "localhost"),
 		RedisPort:               getEnv("REDIS_PORT", "6379"),
 		RedisPass:               getEnv("REDIS_PASS", ""),
 		GoogleClientID:          getEnv("GOOGLE_CLIENT_ID", ""),
 		LineChannelID:           getEnv("LINE_CHANNEL_ID", ""),
+		LineChannelAccessToken:  getEnv("LINE_CHANNEL_ACCESS_TOKEN", ""),
+		LineAPIEndpoint:         getEnv("LINE_API_ENDPOINT", "https://api.line.me"),
+		LineRateLimit:           getEnvInt("LINE_RATE_LIMIT", 100),
+		SMSProvider:             getEnv("SMS_PROVIDER", ""),
+		SMSGatewayURL:           getEnv("SMS_GATEWAY_URL", ""),
+		SMSGatewayAPIKey:        getEnv("SMS_GATEWAY_API_KEY", ""),
+		SMSSenderName:           getEnv("SMS_SENDER_NAME", "PBMap"),
+		AlarmExpiryInterval:     getEnvDuration("ALARM_EXPIRY_INTERVAL", time.Minute),
+		AlarmApprovalWindow:     getEnvDuration("ALARM_APPROVAL_WINDOW", 15*time.Minute),
+		CAPSender:               getEnv("CAP_SENDER", "pbmap_api"),
+		DefaultLocale:           getEnv("DEFAULT_LOCALE", "th"),
+		DefaultTimeZone:         getEnv("DEFAULT_TIMEZONE", "Asia/Bangkok"),
+		JobPollInterval:         getEnvDuration("JOB_POLL_INTERVAL", 5*time.Second),
+		JobLease:                getEnvDuration("JOB_LEASE", 5*time.Minute),
+		JobRetryBackoff:         getEnvDuration("JOB_RETRY_BACKOFF", 30*time.Second),
+		JobMaxAttempts:          getEnvInt("JOB_MAX_ATTEMPTS", 5),
+		OutboxPollInterval:      getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		OutboxLease:             getEnvDuration("OUTBOX_LEASE", 2*time.Minute),
+		OutboxRetryBackoff:      getEnvDuration("OUTBOX_RETRY_BACKOFF", 2*time.Second),
+		OutboxMaxAttempts:       getEnvInt("OUTBOX_MAX_ATTEMPTS", 10),
 	}
 }
 
@@ -55,3 +124,36 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvInt reads a positive integer, falling back when the value is missing,
+// malformed or not positive.
+func getEnvInt(key string, fallback int) int {
+	if value, ok := os.LookupEnv(key); ok {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			return n
+		}
+	}
+	return fallback
+}
+
+// getEnvCount is getEnvInt for settings where zero is allowed.
+func getEnvCount(key string, fallback int) int {
+	if value, ok := os.LookupEnv(key); ok {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return fallback
+}
+
+// getEnvDuration reads a positive duration, falling back when the value is
+// missing, malformed or not positive; intervals feed time.NewTicker, which
+// panics on anything else.
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, ok := os.LookupEnv(key); ok {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,83 @@
+package geo
+
+import "math"
+
+// EarthRadius is the mean Earth radius in meters.
+const EarthRadius = 6371000.0
+
+// Distance returns the great-circle distance in meters between two points.
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lng2 - lng1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// Bounds is an axis-aligned latitude/longitude box.
+type Bounds struct {
+	MinLat float64
+	MaxLat float64
+	MinLng float64
+	MaxLng float64
+}
+
+// CircleBounds returns a box that fully contains the circle around (lat, lng).
+// It is meant as a cheap index-friendly pre-filter before an exact Distance check.
+// A circle around a pole or across the antimeridian gets every longitude, as
+// a box wrapping around would take two ranges in every query.
+func CircleBounds(lat, lng float64, radius float64) Bounds {
+	angular := radius / EarthRadius
+	dLat := angular * 180 / math.Pi
+	b := Bounds{
+		MinLat: math.Max(-90, lat-dLat),
+		MaxLat: math.Min(90, lat+dLat),
+		MinLng: -180,
+		MaxLng: 180,
+	}
+	if lat-dLat <= -90 || lat+dLat >= 90 {
+		return b
+	}
+
+	// The widest point of the circle is not at its center's latitude but
+	// closer to the pole, which this accounts for.
+	dLng := math.Asin(math.Sin(angular)/math.Cos(lat*math.Pi/180)) * 180 / math.Pi
+	if lng-dLng < -180 || lng+dLng > 180 {
+		return b
+	}
+	b.MinLng = lng - dLng
+	b.MaxLng = lng + dLng
+	return b
+}
+
+// Point is a WGS84 coordinate.
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// EnclosingCircle returns a center and radius in meters that cover all points.
+// The center is the arithmetic mean of the points, which is close enough to
+// optimal for the small areas alarms are sent to.
+func EnclosingCircle(points []Point) (Point, float64) {
+	if len(points) == 0 {
+		return Point{}, 0
+	}
+
+	var center Point
+	for _, p := range points {
+		center.Lat += p.Lat
+		center.Lng += p.Lng
+	}
+	center.Lat /= float64(len(points))
+	center.Lng /= float64(len(points))
+
+	radius := 0.0
+	for _, p := range points {
+		radius = math.Max(radius, Distance(center.Lat, center.Lng, p.Lat, p.Lng))
+	}
+	return center, radius
+}
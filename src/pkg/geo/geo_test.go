@@ -0,0 +1,51 @@
+package geo
+
+import "testing"
+
+func TestCircleBounds(t *testing.T) {
+	tests := []struct {
+		name   string
+		center Point
+		radius float64
+		inside []Point
+	}{
+		{
+			name:   "bangkok",
+			center: Point{Lat: 13.75, Lng: 100.5},
+			radius: 10000,
+			inside: []Point{{Lat: 13.75, Lng: 100.59}, {Lat: 13.67, Lng: 100.5}},
+		},
+		{
+			name:   "across the antimeridian",
+			center: Point{Lat: -17.7, Lng: 179.95},
+			radius: 20000,
+			inside: []Point{{Lat: -17.7, Lng: -179.95}, {Lat: -17.7, Lng: 179.85}},
+		},
+		{
+			name:   "around a pole",
+			center: Point{Lat: 89.95, Lng: 0},
+			radius: 20000,
+			inside: []Point{{Lat: 89.95, Lng: 180}, {Lat: 89.9, Lng: -90}},
+		},
+		{
+			name:   "far from the equator",
+			center: Point{Lat: 70, Lng: 20},
+			radius: 500000,
+			inside: []Point{{Lat: 70.5, Lng: 33.2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := CircleBounds(tt.center.Lat, tt.center.Lng, tt.radius)
+			for _, p := range tt.inside {
+				if d := Distance(tt.center.Lat, tt.center.Lng, p.Lat, p.Lng); d > tt.radius {
+					t.Fatalf("test point %+v is %.0fm away, outside the circle", p, d)
+				}
+				if p.Lat < b.MinLat || p.Lat > b.MaxLat || p.Lng < b.MinLng || p.Lng > b.MaxLng {
+					t.Errorf("bounds %+v leave out %+v", b, p)
+				}
+			}
+		})
+	}
+}
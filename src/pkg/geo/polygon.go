@@ -0,0 +1,278 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// maxVertices bounds the size of a geometry accepted from clients so that the
+// quadratic self-intersection check stays cheap.
+const maxVertices = 5000
+
+// Ring is a closed linear ring: the first and last points are equal.
+type Ring []Point
+
+// Polygon is an outer ring followed by zero or more holes.
+type Polygon []Ring
+
+// MultiPolygon is a set of polygons treated as one area.
+type MultiPolygon []Polygon
+
+// ParseGeoJSON decodes the coordinates of a GeoJSON Polygon or MultiPolygon
+// and validates the resulting geometry.
+func ParseGeoJSON(geometryType string, coordinates json.RawMessage) (MultiPolygon, error) {
+	var mp MultiPolygon
+	switch geometryType {
+	case "Polygon":
+		var raw [][][]float64
+		if err := json.Unmarshal(coordinates, &raw); err != nil {
+			return nil, fmt.Errorf("invalid Polygon coordinates: %v", err)
+		}
+		p, err := toPolygon(raw)
+		if err != nil {
+			return nil, err
+		}
+		mp = MultiPolygon{p}
+	case "MultiPolygon":
+		var raw [][][][]float64
+		if err := json.Unmarshal(coordinates, &raw); err != nil {
+			return nil, fmt.Errorf("invalid MultiPolygon coordinates: %v", err)
+		}
+		for _, rp := range raw {
+			p, err := toPolygon(rp)
+			if err != nil {
+				return nil, err
+			}
+			mp = append(mp, p)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported geometry type %q", geometryType)
+	}
+
+	if err := mp.Validate(); err != nil {
+		return nil, err
+	}
+	return mp, nil
+}
+
+// toPolygon converts GeoJSON positions, which are [longitude, latitude], to a Polygon.
+func toPolygon(raw [][][]float64) (Polygon, error) {
+	polygon := make(Polygon, 0, len(raw))
+	for _, rr := range raw {
+		ring := make(Ring, 0, len(rr))
+		for _, pos := range rr {
+			if len(pos) < 2 {
+				return nil, errors.New("position must have longitude and latitude")
+			}
+			ring = append(ring, Point{Lat: pos[1], Lng: pos[0]})
+		}
+		polygon = append(polygon, ring)
+	}
+	return polygon, nil
+}
+
+// Validate checks that every polygon has an outer ring, every ring is closed
+// with at least four positions inside WGS84 bounds, and no edges cross.
+func (mp MultiPolygon) Validate() error {
+	if len(mp) == 0 {
+		return errors.New("geometry has no polygons")
+	}
+
+	vertices := 0
+	for pi, polygon := range mp {
+		if len(polygon) == 0 {
+			return fmt.Errorf("polygon %d has no rings", pi)
+		}
+		for ri, ring := range polygon {
+			vertices += len(ring)
+			if vertices > maxVertices {
+				return fmt.Errorf("geometry has more than %d vertices", maxVertices)
+			}
+			if len(ring) < 4 {
+				return fmt.Errorf("polygon %d ring %d needs at least four positions", pi, ri)
+			}
+			if ring[0] != ring[len(ring)-1] {
+				return fmt.Errorf("polygon %d ring %d is not closed", pi, ri)
+			}
+			for _, p := range ring {
+				if p.Lat < -90 || p.Lat > 90 || p.Lng < -180 || p.Lng > 180 {
+					return fmt.Errorf("polygon %d ring %d has a position out of range", pi, ri)
+				}
+			}
+		}
+		if polygon.selfIntersects() {
+			return fmt.Errorf("polygon %d is self-intersecting", pi)
+		}
+		for hi, hole := range polygon[1:] {
+			// With no edges crossing, a hole lies inside a ring exactly when
+			// any one of its vertices does.
+			if !polygon[0].contains(hole[0]) {
+				return fmt.Errorf("polygon %d hole %d is not inside the outer ring", pi, hi+1)
+			}
+			for oi, other := range polygon[1:] {
+				if oi != hi && other.contains(hole[0]) {
+					return fmt.Errorf("polygon %d hole %d is inside hole %d", pi, hi+1, oi+1)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Contains reports whether p lies inside any polygon of the area.
+func (mp MultiPolygon) Contains(p Point) bool {
+	for _, polygon := range mp {
+		if polygon.Contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Bounds returns the bounding box of the area.
+func (mp MultiPolygon) Bounds() Bounds {
+	b := Bounds{MinLat: 90, MaxLat: -90, MinLng: 180, MaxLng: -180}
+	for _, polygon := range mp {
+		for _, p := range polygon[0] {
+			b.MinLat = math.Min(b.MinLat, p.Lat)
+			b.MaxLat = math.Max(b.MaxLat, p.Lat)
+			b.MinLng = math.Min(b.MinLng, p.Lng)
+			b.MaxLng = math.Max(b.MaxLng, p.Lng)
+		}
+	}
+	return b
+}
+
+// Points returns the vertices of all outer rings.
+func (mp MultiPolygon) Points() []Point {
+	var points []Point
+	for _, polygon := range mp {
+		points = append(points, polygon[0][:len(polygon[0])-1]...)
+	}
+	return points
+}
+
+// Contains reports whether p lies inside the outer ring and outside every hole.
+func (polygon Polygon) Contains(p Point) bool {
+	if !polygon[0].contains(p) {
+		return false
+	}
+	for _, hole := range polygon[1:] {
+		if hole.contains(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// contains is the even-odd ray casting test, treating coordinates as planar.
+func (ring Ring) contains(p Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+type segment struct {
+	a, b Point
+	ring int
+	idx  int
+}
+
+func (polygon Polygon) selfIntersects() bool {
+	var segments []segment
+	for ri, ring := range polygon {
+		for i := 0; i < len(ring)-1; i++ {
+			segments = append(segments, segment{a: ring[i], b: ring[i+1], ring: ri, idx: i})
+		}
+	}
+
+	for i := 0; i < len(segments); i++ {
+		for j := i + 1; j < len(segments); j++ {
+			s, t := segments[i], segments[j]
+			if s.ring == t.ring && adjacent(s.idx, t.idx, len(polygon[s.ring])-1) {
+				// Edges meeting at a vertex only intersect if they fold back
+				// over each other.
+				if overlaps(s, t) {
+					return true
+				}
+				continue
+			}
+			if segmentsIntersect(s.a, s.b, t.a, t.b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// adjacent reports whether edges i and j of a ring with n edges share a
+// vertex, including the first and last edges, which meet where the ring closes.
+func adjacent(i, j, n int) bool {
+	if i > j {
+		i, j = j, i
+	}
+	return j-i == 1 || (i == 0 && j == n-1)
+}
+
+// overlaps reports whether two edges sharing a vertex run back along each
+// other, as at a spike of zero width.
+func overlaps(s, t segment) bool {
+	shared, p, q := s.b, s.a, t.b
+	if s.b != t.a {
+		// The first and last edges of a ring meet at its closing vertex.
+		shared, p, q = s.a, s.b, t.a
+	}
+	if orientation(shared, p, q) != 0 {
+		return false
+	}
+	// Collinear edges overlap when both leave the shared vertex the same way.
+	return (p.Lng-shared.Lng)*(q.Lng-shared.Lng)+(p.Lat-shared.Lat)*(q.Lat-shared.Lat) > 0
+}
+
+func segmentsIntersect(p1, p2, p3, p4 Point) bool {
+	d1 := orientation(p3, p4, p1)
+	d2 := orientation(p3, p4, p2)
+	d3 := orientation(p1, p2, p3)
+	d4 := orientation(p1, p2, p4)
+
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	return (d1 == 0 && onSegment(p3, p4, p1)) ||
+		(d2 == 0 && onSegment(p3, p4, p2)) ||
+		(d3 == 0 && onSegment(p1, p2, p3)) ||
+		(d4 == 0 && onSegment(p1, p2, p4))
+}
+
+func orientation(a, b, c Point) float64 {
+	return (b.Lng-a.Lng)*(c.Lat-a.Lat) - (b.Lat-a.Lat)*(c.Lng-a.Lng)
+}
+
+func onSegment(a, b, p Point) bool {
+	return math.Min(a.Lng, b.Lng) <= p.Lng && p.Lng <= math.Max(a.Lng, b.Lng) &&
+		math.Min(a.Lat, b.Lat) <= p.Lat && p.Lat <= math.Max(a.Lat, b.Lat)
+}
+
+// CirclePolygon approximates a circle with an n-sided polygon.
+func CirclePolygon(center Point, radius float64, n int) Polygon {
+	ring := make(Ring, 0, n+1)
+	dLat := radius / EarthRadius * 180 / math.Pi
+	dLng := dLat / math.Max(math.Cos(center.Lat*math.Pi/180), 1e-9)
+	for i := 0; i < n; i++ {
+		theta := 2 * math.Pi * float64(i) / float64(n)
+		ring = append(ring, Point{
+			Lat: center.Lat + dLat*math.Sin(theta),
+			Lng: center.Lng + dLng*math.Cos(theta),
+		})
+	}
+	ring = append(ring, ring[0])
+	return Polygon{ring}
+}
@@ -0,0 +1,200 @@
+package geo
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// square is a closed ring of [lng, lat] positions around (lat, lng) with half-width d.
+func square(lat, lng, d float64) [][]float64 {
+	return [][]float64{
+		{lng - d, lat - d},
+		{lng + d, lat - d},
+		{lng + d, lat + d},
+		{lng - d, lat + d},
+		{lng - d, lat - d},
+	}
+}
+
+func polygonJSON(t *testing.T, rings ...[][]float64) json.RawMessage {
+	t.Helper()
+	raw, err := json.Marshal(rings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestParseGeoJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		rings   [][][]float64
+		wantErr string
+	}{
+		{
+			name:  "square",
+			rings: [][][]float64{square(13.7, 100.5, 0.1)},
+		},
+		{
+			name:  "square with hole",
+			rings: [][][]float64{square(13.7, 100.5, 0.1), square(13.7, 100.5, 0.05)},
+		},
+		{
+			name:  "two holes",
+			rings: [][][]float64{square(13.7, 100.5, 0.1), square(13.65, 100.45, 0.01), square(13.75, 100.55, 0.01)},
+		},
+		{
+			name:    "too few positions",
+			rings:   [][][]float64{{{100, 13}, {101, 13}, {100, 13}}},
+			wantErr: "needs at least four positions",
+		},
+		{
+			name:    "open ring",
+			rings:   [][][]float64{{{100, 13}, {101, 13}, {101, 14}, {100, 14}}},
+			wantErr: "is not closed",
+		},
+		{
+			name:    "out of range",
+			rings:   [][][]float64{{{100, 91}, {101, 91}, {101, 92}, {100, 91}}},
+			wantErr: "out of range",
+		},
+		{
+			name:    "bow tie",
+			rings:   [][][]float64{{{100, 13}, {101, 14}, {101, 13}, {100, 14}, {100, 13}}},
+			wantErr: "self-intersecting",
+		},
+		{
+			name:    "spike at closing vertex",
+			rings:   [][][]float64{{{100, 13}, {102, 13}, {101, 14}, {101, 13}, {100, 13}}},
+			wantErr: "self-intersecting",
+		},
+		{
+			name:    "zero-area triangle",
+			rings:   [][][]float64{{{100, 13}, {102, 13}, {101, 13}, {100, 13}}},
+			wantErr: "self-intersecting",
+		},
+		{
+			name:    "spike mid ring",
+			rings:   [][][]float64{{{100, 13}, {102, 13}, {101, 13}, {101, 14}, {100, 13}}},
+			wantErr: "self-intersecting",
+		},
+		{
+			name:    "hole outside",
+			rings:   [][][]float64{square(13.7, 100.5, 0.1), square(15, 100.5, 0.05)},
+			wantErr: "not inside the outer ring",
+		},
+		{
+			name:    "hole crossing outer ring",
+			rings:   [][][]float64{square(13.7, 100.5, 0.1), square(13.8, 100.5, 0.05)},
+			wantErr: "self-intersecting",
+		},
+		{
+			name:    "hole inside hole",
+			rings:   [][][]float64{square(13.7, 100.5, 0.1), square(13.7, 100.5, 0.05), square(13.7, 100.5, 0.01)},
+			wantErr: "is inside hole",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseGeoJSON("Polygon", polygonJSON(t, tt.rings...))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseGeoJSONRejectsTooManyVertices(t *testing.T) {
+	circle := CirclePolygon(Point{Lat: 13.7, Lng: 100.5}, 1000, maxVertices)
+	rings := make([][]float64, 0, len(circle[0]))
+	for _, p := range circle[0] {
+		rings = append(rings, []float64{p.Lng, p.Lat})
+	}
+	_, err := ParseGeoJSON("Polygon", polygonJSON(t, rings))
+	if err == nil || !strings.Contains(err.Error(), "more than") {
+		t.Fatalf("error = %v, want vertex limit", err)
+	}
+}
+
+func TestParseGeoJSONMultiPolygon(t *testing.T) {
+	raw, err := json.Marshal([][][][]float64{
+		{square(13.7, 100.5, 0.1)},
+		{square(14.7, 100.5, 0.1)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp, err := ParseGeoJSON("MultiPolygon", raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mp) != 2 {
+		t.Fatalf("got %d polygons, want 2", len(mp))
+	}
+	if _, err := ParseGeoJSON("Point", raw); err == nil {
+		t.Fatal("expected unsupported geometry type to fail")
+	}
+}
+
+func TestMultiPolygonContains(t *testing.T) {
+	mp, err := ParseGeoJSON("Polygon", polygonJSON(t, square(13.7, 100.5, 0.1), square(13.7, 100.5, 0.05)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		p    Point
+		want bool
+	}{
+		{"inside ring", Point{Lat: 13.62, Lng: 100.5}, true},
+		{"inside hole", Point{Lat: 13.7, Lng: 100.5}, false},
+		{"outside", Point{Lat: 14, Lng: 100.5}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mp.Contains(tt.p); got != tt.want {
+				t.Fatalf("Contains(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiPolygonBounds(t *testing.T) {
+	mp, err := ParseGeoJSON("Polygon", polygonJSON(t, square(13, 100, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := mp.Bounds()
+	if b.MinLat != 12 || b.MaxLat != 14 || b.MinLng != 99 || b.MaxLng != 101 {
+		t.Fatalf("Bounds() = %+v", b)
+	}
+}
+
+func TestAdjacent(t *testing.T) {
+	tests := []struct {
+		i, j, n int
+		want    bool
+	}{
+		{0, 1, 4, true},
+		{1, 0, 4, true},
+		{2, 3, 4, true},
+		{0, 3, 4, true},
+		{3, 0, 4, true},
+		{0, 2, 4, false},
+		{1, 3, 4, false},
+	}
+	for _, tt := range tests {
+		if got := adjacent(tt.i, tt.j, tt.n); got != tt.want {
+			t.Errorf("adjacent(%d, %d, %d) = %v, want %v", tt.i, tt.j, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package line
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+)
+
+const (
+	DefaultEndpoint = "https://api.line.me"
+
+	// MaxRecipients is the most user IDs a single multicast request may carry.
+	MaxRecipients = 500
+	// MaxMessages is the most messages a single request may carry.
+	MaxMessages = 5
+
+	// DefaultRequestsPerSecond stays under LINE's multicast limit of 200
+	// requests per second, leaving room for other callers of the channel.
+	DefaultRequestsPerSecond = 100
+)
+
+// Config holds the credentials of a Messaging API channel.
+type Config struct {
+	Endpoint           string // DefaultEndpoint or a fake server
+	ChannelAccessToken string
+	RequestsPerSecond  int // client-side rate limit; DefaultRequestsPerSecond when 0
+}
+
+// Response is LINE's answer to one push or multicast request.
+type Response struct {
+	StatusCode int
+	RequestID  string // x-line-request-id
+	Message    string
+	Details    []ErrorDetail
+}
+
+// ErrorDetail points at the part of a request LINE rejected.
+type ErrorDetail struct {
+	Message  string `json:"message"`
+	Property string `json:"property"`
+}
+
+// Sent reports whether LINE accepted the messages.
+func (r *Response) Sent() bool {
+	return r.StatusCode == http.StatusOK
+}
+
+// RateLimited reports whether the request was refused for exceeding a rate
+// limit or the channel's monthly message quota.
+func (r *Response) RateLimited() bool {
+	return r.StatusCode == http.StatusTooManyRequests
+}
+
+// Client sends messages through the LINE Messaging API.
+type Client struct {
+	endpoint string
+	token    string
+	http     *http.Client
+	limiter  *rate.Limiter
+}
+
+// NewClient creates a Messaging API client. httpClient may be nil, in which
+// case a client with a 30 second timeout is used.
+func NewClient(cfg Config, httpClient *http.Client) (*Client, error) {
+	if cfg.ChannelAccessToken == "" {
+		return nil, errors.New("line: channel access token is required")
+	}
+
+	endpoint := strings.TrimRight(cfg.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	rps := cfg.RequestsPerSecond
+	if rps <= 0 {
+		rps = DefaultRequestsPerSecond
+	}
+
+	return &Client{
+		endpoint: endpoint,
+		token:    cfg.ChannelAccessToken,
+		http:     httpClient,
+		limiter:  rate.NewLimiter(rate.Limit(rps), rps),
+	}, nil
+}
+
+// Push sends messages to a single user.
+func (c *Client) Push(ctx context.Context, to string, messages ...Message) (*Response, error) {
+	return c.send(ctx, "/v2/bot/message/push", map[string]any{"to": to, "messages": messages}, len(messages))
+}
+
+// Multicast sends messages to up to MaxRecipients users at once.
+func (c *Client) Multicast(ctx context.Context, to []string, messages ...Message) (*Response, error) {
+	if len(to) > MaxRecipients {
+		return nil, fmt.Errorf("line: multicast to %d users exceeds %d", len(to), MaxRecipients)
+	}
+	return c.send(ctx, "/v2/bot/message/multicast", map[string]any{"to": to, "messages": messages}, len(messages))
+}
+
+// send posts body, retrying once with the same retry key when no response was
+// received. An error is only returned when no response was received.
+func (c *Client) send(ctx context.Context, path string, body map[string]any, messages int) (*Response, error) {
+	if messages == 0 || messages > MaxMessages {
+		return nil, fmt.Errorf("line: a request carries 1 to %d messages, got %d", MaxMessages, messages)
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("line: failed to encode request: %v", err)
+	}
+
+	// LINE accepts a request with a given retry key only once, so a retry
+	// after a lost response cannot deliver the messages twice.
+	retryKey := uuid.NewString()
+	resp, err := c.post(ctx, path, payload, retryKey)
+	if err != nil && ctx.Err() == nil {
+		resp, err = c.post(ctx, path, payload, retryKey)
+	}
+	if err == nil && resp.StatusCode == http.StatusConflict {
+		// The first attempt went through.
+		resp.StatusCode = http.StatusOK
+	}
+	return resp, err
+}
+
+func (c *Client) post(ctx context.Context, path string, payload []byte, retryKey string) (*Response, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("authorization", "Bearer "+c.token)
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("x-line-retry-key", retryKey)
+
+	httpResp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	resp := &Response{StatusCode: httpResp.StatusCode, RequestID: httpResp.Header.Get("x-line-request-id")}
+	if resp.Sent() {
+		_, _ = io.Copy(io.Discard, httpResp.Body)
+		return resp, nil
+	}
+
+	var errBody struct {
+		Message string        `json:"message"`
+		Details []ErrorDetail `json:"details"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(httpResp.Body, 4096))
+	if err := json.Unmarshal(data, &errBody); err != nil || errBody.Message == "" {
+		errBody.Message = http.StatusText(httpResp.StatusCode)
+	}
+	resp.Message = errBody.Message
+	resp.Details = errBody.Details
+	return resp, nil
+}
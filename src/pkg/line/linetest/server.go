@@ -0,0 +1,147 @@
+// Package linetest provides a fake LINE Messaging API server that records the
+// messages it receives, for tests and local development.
+package linetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pbmap_api/src/pkg/line"
+)
+
+// Request is a push or multicast the fake server accepted.
+type Request struct {
+	Path       string          `json:"path"`
+	To         []string        `json:"to"`
+	Messages   json.RawMessage `json:"messages"`
+	RetryKey   string          `json:"retry_key,omitempty"`
+	ReceivedAt time.Time       `json:"received_at"`
+}
+
+// Server is a fake Messaging API endpoint. It accepts any well-formed request
+// carrying the expected channel access token, unless told to answer as if
+// the channel's quota were used up.
+type Server struct {
+	token string
+
+	mu             sync.Mutex
+	requests       []Request
+	retryKeys      map[string]bool
+	quotaExhausted bool
+}
+
+// NewServer creates a fake that accepts token, or any bearer token when
+// token is empty.
+func NewServer(token string) *Server {
+	return &Server{token: token, retryKeys: make(map[string]bool)}
+}
+
+// Start serves the fake over plain HTTP. Point line.Config.Endpoint at the
+// returned server's URL.
+func (s *Server) Start() *httptest.Server {
+	return httptest.NewServer(s)
+}
+
+// SetQuotaExhausted makes the server answer 429 to every request, as LINE does
+// once the monthly message quota is used up.
+func (s *Server) SetQuotaExhausted(exhausted bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quotaExhausted = exhausted
+}
+
+// Requests returns the requests accepted so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Reset forgets recorded requests and clears the quota flag.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.retryKeys = make(map[string]bool)
+	s.quotaExhausted = false
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || (r.URL.Path != "/v2/bot/message/push" && r.URL.Path != "/v2/bot/message/multicast") {
+		reject(w, http.StatusNotFound, "Not found")
+		return
+	}
+	bearer, ok := strings.CutPrefix(r.Header.Get("authorization"), "Bearer ")
+	if !ok || bearer == "" || (s.token != "" && bearer != s.token) {
+		reject(w, http.StatusUnauthorized, "Authentication failed. Confirm that the access token in the authorization header is valid.")
+		return
+	}
+
+	var body struct {
+		To       json.RawMessage   `json:"to"`
+		Messages []json.RawMessage `json:"messages"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		reject(w, http.StatusBadRequest, "The request body has 1 error(s)")
+		return
+	}
+	var to []string
+	if r.URL.Path == "/v2/bot/message/push" {
+		var single string
+		if json.Unmarshal(body.To, &single) != nil || single == "" {
+			reject(w, http.StatusBadRequest, "The property, 'to', in the request body is invalid")
+			return
+		}
+		to = []string{single}
+	} else if json.Unmarshal(body.To, &to) != nil || len(to) == 0 || len(to) > line.MaxRecipients {
+		reject(w, http.StatusBadRequest, "The property, 'to', in the request body is invalid")
+		return
+	}
+	if len(body.Messages) == 0 || len(body.Messages) > line.MaxMessages {
+		reject(w, http.StatusBadRequest, "The property, 'messages', in the request body is invalid")
+		return
+	}
+	messages, _ := json.Marshal(body.Messages)
+
+	retryKey := r.Header.Get("x-line-retry-key")
+	s.mu.Lock()
+	if s.quotaExhausted {
+		s.mu.Unlock()
+		reject(w, http.StatusTooManyRequests, "You have reached your monthly limit.")
+		return
+	}
+	if retryKey != "" && s.retryKeys[retryKey] {
+		s.mu.Unlock()
+		reject(w, http.StatusConflict, "The retry key is already accepted")
+		return
+	}
+	if retryKey != "" {
+		s.retryKeys[retryKey] = true
+	}
+	s.requests = append(s.requests, Request{
+		Path:       r.URL.Path,
+		To:         to,
+		Messages:   messages,
+		RetryKey:   retryKey,
+		ReceivedAt: time.Now(),
+	})
+	s.mu.Unlock()
+
+	w.Header().Set("content-type", "application/json")
+	w.Header().Set("x-line-request-id", uuid.NewString())
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("{}"))
+}
+
+func reject(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("content-type", "application/json")
+	w.Header().Set("x-line-request-id", uuid.NewString())
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
@@ -0,0 +1,92 @@
+package line
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// MaxAltTextLength is the longest alt text LINE accepts for a Flex message.
+const MaxAltTextLength = 400
+
+// Message is a Messaging API message object. Only text and Flex messages are
+// built by this package.
+type Message struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	AltText  string `json:"altText,omitempty"`
+	Contents any    `json:"contents,omitempty"`
+}
+
+// TextMessage creates a plain text message.
+func TextMessage(text string) Message {
+	return Message{Type: "text", Text: text}
+}
+
+// Alert is the content of an alert card.
+type Alert struct {
+	Title    string
+	Body     string
+	Label    string // small caption above the title, e.g. the alert's status
+	Color    string // header background as #RRGGBB
+	MapURL   string // opened by the footer button when set
+	MapLabel string
+}
+
+// AlertMessage creates a Flex bubble with a colored header, the alert text and
+// a button opening a map. Chat lists and notifications show altText instead.
+func AlertMessage(altText string, alert Alert) Message {
+	header := []any{}
+	if alert.Label != "" {
+		header = append(header, map[string]any{
+			"type": "text", "text": alert.Label, "size": "xs", "color": "#FFFFFF",
+		})
+	}
+	header = append(header, map[string]any{
+		"type": "text", "text": alert.Title, "weight": "bold", "size": "lg", "color": "#FFFFFF", "wrap": true,
+	})
+
+	color := alert.Color
+	if color == "" {
+		color = "#1976D2"
+	}
+	bubble := map[string]any{
+		"type": "bubble",
+		"header": map[string]any{
+			"type": "box", "layout": "vertical", "backgroundColor": color, "contents": header,
+		},
+		"body": map[string]any{
+			"type": "box", "layout": "vertical",
+			"contents": []any{map[string]any{"type": "text", "text": alert.Body, "wrap": true}},
+		},
+	}
+	if alert.MapURL != "" {
+		label := alert.MapLabel
+		if label == "" {
+			label = "View map"
+		}
+		bubble["footer"] = map[string]any{
+			"type": "box", "layout": "vertical",
+			"contents": []any{map[string]any{
+				"type":   "button",
+				"style":  "primary",
+				"color":  color,
+				"action": map[string]any{"type": "uri", "label": label, "uri": alert.MapURL},
+			}},
+		}
+	}
+
+	return Message{Type: "flex", AltText: truncate(altText, MaxAltTextLength), Contents: bubble}
+}
+
+// MapURL links to a map pinned at the given coordinates.
+func MapURL(lat, lng float64) string {
+	return "https://www.google.com/maps/search/?api=1&query=" + url.QueryEscape(fmt.Sprintf("%.6f,%.6f", lat, lng))
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,30 @@
+package metrics
+
+import "expvar"
+
+// Push token health, published through expvar at /debug/vars.
+var (
+	// PushTokensChecked counts the push tokens a provider reported a result for.
+	PushTokensChecked = expvar.NewInt("push_tokens_checked_total")
+	// PushTokensPruned counts the push tokens marked invalid, by error code.
+	PushTokensPruned = expvar.NewMap("push_tokens_pruned_total")
+)
+
+func init() {
+	expvar.Publish("push_token_prune_rate", expvar.Func(pushTokenPruneRate))
+}
+
+// pushTokenPruneRate is the share of checked push tokens that were pruned.
+func pushTokenPruneRate() any {
+	checked := PushTokensChecked.Value()
+	if checked == 0 {
+		return 0.0
+	}
+	var pruned int64
+	PushTokensPruned.Do(func(kv expvar.KeyValue) {
+		if v, ok := kv.Value.(*expvar.Int); ok {
+			pruned += v.Value()
+		}
+	})
+	return float64(pruned) / float64(checked)
+}
@@ -0,0 +1,48 @@
+// Package placeholder fills {{name}} placeholders in alarm template text.
+package placeholder
+
+import (
+	"regexp"
+	"sort"
+)
+
+var pattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// Names returns the distinct placeholder names used in the given texts, sorted.
+func Names(texts ...string) []string {
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, text := range texts {
+		for _, m := range pattern.FindAllStringSubmatch(text, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				names = append(names, m[1])
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Missing returns the placeholder names in texts that have no value in vars.
+func Missing(vars map[string]string, texts ...string) []string {
+	missing := make([]string, 0)
+	for _, name := range Names(texts...) {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// Render replaces every placeholder that has a value in vars. Placeholders
+// without a value are left as they are; check Missing first.
+func Render(text string, vars map[string]string) string {
+	return pattern.ReplaceAllStringFunc(text, func(m string) string {
+		name := pattern.FindStringSubmatch(m)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return m
+	})
+}
@@ -0,0 +1,182 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Urgency values of the Urgency header (RFC 8030, section 5.3).
+const (
+	UrgencyVeryLow = "very-low"
+	UrgencyLow     = "low"
+	UrgencyNormal  = "normal"
+	UrgencyHigh    = "high"
+)
+
+// Keys are the user agent's encryption keys from a PushSubscription.
+type Keys struct {
+	P256dh []byte // uncompressed P-256 public key, 65 bytes
+	Auth   []byte // authentication secret, 16 bytes
+}
+
+// Subscription is a browser PushSubscription.
+type Subscription struct {
+	Endpoint string
+	Keys     Keys
+}
+
+// pushServiceHosts are the push services of the major browsers. Endpoints
+// are only accepted on these hosts and their subdomains, so a subscription
+// cannot make the server post to an arbitrary, e.g. internal, address.
+var pushServiceHosts = []string{
+	"fcm.googleapis.com",        // Chrome, Opera, Samsung Internet
+	"push.services.mozilla.com", // Firefox
+	"notify.windows.com",        // Edge
+	"push.apple.com",            // Safari
+}
+
+// ParseSubscription checks the endpoint and decodes the base64url-encoded
+// keys of a PushSubscription as browsers serialize it.
+func ParseSubscription(endpoint, p256dh, auth string) (*Subscription, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, errors.New("webpush: endpoint must be an https URL")
+	}
+	if !knownPushService(u) {
+		return nil, fmt.Errorf("webpush: %s is not a known push service", u.Hostname())
+	}
+	public, err := decodeBase64(p256dh)
+	if err != nil || len(public) != 65 || public[0] != 0x04 {
+		return nil, errors.New("webpush: p256dh must be an uncompressed P-256 public key")
+	}
+	secret, err := decodeBase64(auth)
+	if err != nil || len(secret) != 16 {
+		return nil, errors.New("webpush: auth must be a 16-byte secret")
+	}
+	return &Subscription{Endpoint: endpoint, Keys: Keys{P256dh: public, Auth: secret}}, nil
+}
+
+// Options are the delivery headers of a push message.
+type Options struct {
+	TTL     time.Duration // how long the push service keeps an undelivered message
+	Urgency string
+	Topic   string // replaces an undelivered message with the same topic
+}
+
+// Response is the push service's answer.
+type Response struct {
+	StatusCode int
+	Location   string // message resource, on success
+	Reason     string
+}
+
+// Sent reports whether the push service accepted the message.
+func (r *Response) Sent() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// Gone reports whether the subscription has expired or been unsubscribed.
+func (r *Response) Gone() bool {
+	return r.StatusCode == http.StatusNotFound || r.StatusCode == http.StatusGone
+}
+
+// Client sends encrypted push messages signed with VAPID.
+type Client struct {
+	vapid *VAPID
+	http  *http.Client
+}
+
+// NewClient creates a Web Push client. httpClient may be nil, in which case
+// redirects are not followed, keeping requests on the push service.
+func NewClient(vapid *VAPID, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout: 30 * time.Second,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+	return &Client{vapid: vapid, http: httpClient}
+}
+
+// VAPID returns the application server identity the client signs with.
+func (c *Client) VAPID() *VAPID {
+	return c.vapid
+}
+
+// Send encrypts payload for sub and posts it to the subscription's push
+// service. An error is only returned when no response was received.
+func (c *Client) Send(ctx context.Context, sub *Subscription, payload []byte, opts Options) (*Response, error) {
+	body, err := Encrypt(payload, sub.Keys)
+	if err != nil {
+		return nil, err
+	}
+	authorization, err := c.vapid.Authorization(sub.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(opts.TTL.Seconds())))
+	if opts.Urgency != "" {
+		req.Header.Set("Urgency", opts.Urgency)
+	}
+	if opts.Topic != "" {
+		req.Header.Set("Topic", opts.Topic)
+	}
+
+	httpResp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	resp := &Response{StatusCode: httpResp.StatusCode, Location: httpResp.Header.Get("Location")}
+	if !resp.Sent() {
+		data, _ := io.ReadAll(io.LimitReader(httpResp.Body, 512))
+		resp.Reason = strings.TrimSpace(string(data))
+		if resp.Reason == "" {
+			resp.Reason = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		}
+	}
+	return resp, nil
+}
+
+// knownPushService reports whether u is on the default port of one of the
+// pushServiceHosts.
+func knownPushService(u *url.URL) bool {
+	if u.User != nil || (u.Port() != "" && u.Port() != "443") {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, h := range pushServiceHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeBase64 accepts base64url with or without padding, as well as standard
+// base64, since browsers and libraries differ in what they emit.
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,63 @@
+package webpush
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	testP256dh = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	testAuth   = "BTBZMqHH6r4Tts7J_aSIgg"
+)
+
+func TestParseSubscription(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		p256dh   string
+		auth     string
+		wantErr  string
+	}{
+		{name: "chrome", endpoint: "https://fcm.googleapis.com/fcm/send/abc"},
+		{name: "firefox", endpoint: "https://updates.push.services.mozilla.com/wpush/v2/abc"},
+		{name: "edge", endpoint: "https://wns2-par02p.notify.windows.com/w/?token=abc"},
+		{name: "safari", endpoint: "https://web.push.apple.com/abc"},
+		{name: "explicit default port", endpoint: "https://fcm.googleapis.com:443/fcm/send/abc"},
+		{name: "padded standard base64 keys", endpoint: "https://fcm.googleapis.com/fcm/send/abc", auth: "BTBZMqHH6r4Tts7J/aSIgg=="},
+		{name: "http", endpoint: "http://fcm.googleapis.com/fcm/send/abc", wantErr: "https URL"},
+		{name: "loopback", endpoint: "https://127.0.0.1/push", wantErr: "not a known push service"},
+		{name: "private address", endpoint: "https://10.0.0.5/push", wantErr: "not a known push service"},
+		{name: "metadata service", endpoint: "https://169.254.169.254/latest/meta-data", wantErr: "not a known push service"},
+		{name: "lookalike host", endpoint: "https://fcm.googleapis.com.evil.example/push", wantErr: "not a known push service"},
+		{name: "suffix without dot", endpoint: "https://evilpush.apple.com/push", wantErr: "not a known push service"},
+		{name: "other port", endpoint: "https://fcm.googleapis.com:8443/push", wantErr: "not a known push service"},
+		{name: "credentials", endpoint: "https://user@fcm.googleapis.com/push", wantErr: "not a known push service"},
+		{name: "short p256dh", endpoint: "https://fcm.googleapis.com/fcm/send/abc", p256dh: "BCVx", wantErr: "p256dh"},
+		{name: "short auth", endpoint: "https://fcm.googleapis.com/fcm/send/abc", auth: "BTBZ", wantErr: "auth"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p256dh, auth := tt.p256dh, tt.auth
+			if p256dh == "" {
+				p256dh = testP256dh
+			}
+			if auth == "" {
+				auth = testAuth
+			}
+			sub, err := ParseSubscription(tt.endpoint, p256dh, auth)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if sub.Endpoint != tt.endpoint || len(sub.Keys.P256dh) != 65 || len(sub.Keys.Auth) != 16 {
+					t.Fatalf("subscription = %+v", sub)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
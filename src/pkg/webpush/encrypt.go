@@ -0,0 +1,105 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// recordSize is the aes128gcm record size; a push message is one record.
+	recordSize = 4096
+	// MaxPayloadSize is the largest plaintext that fits in a single record
+	// (RFC 8291, section 4).
+	MaxPayloadSize = 3993
+)
+
+var ErrPayloadTooLarge = errors.New("webpush: payload too large")
+
+// Encrypt encrypts plaintext for the user agent holding keys, using the
+// aes128gcm content coding as specified by RFC 8291 and RFC 8188.
+func Encrypt(plaintext []byte, keys Keys) ([]byte, error) {
+	if len(plaintext) > MaxPayloadSize {
+		return nil, ErrPayloadTooLarge
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encrypt(plaintext, keys, asPrivate, salt)
+}
+
+// encrypt encrypts with the given ephemeral sender key and salt.
+func encrypt(plaintext []byte, keys Keys, asPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	uaPublic, err := ecdh.P256().NewPublicKey(keys.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("webpush: invalid p256dh key: %v", err)
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	secret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	cek, nonce, err := deriveKeys(secret, keys.Auth, keys.P256dh, asPublic, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: salt | record size | key id length | key id (the sender's public key).
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	// The single (and so last) record is terminated by the 0x02 padding delimiter.
+	record := append(append(make([]byte, 0, len(plaintext)+1), plaintext...), 0x02)
+	return gcm.Seal(header, nonce, record, nil), nil
+}
+
+// deriveKeys derives the content encryption key and nonce (RFC 8291, section 3.4).
+func deriveKeys(secret, authSecret, uaPublic, asPublic, salt []byte) (cek, nonce []byte, err error) {
+	prkKey, err := hkdf.Extract(sha256.New, secret, authSecret)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyInfo := "WebPush: info\x00" + string(uaPublic) + string(asPublic)
+	ikm, err := hkdf.Expand(sha256.New, prkKey, keyInfo, 32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cek, err = hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16); err != nil {
+		return nil, nil, err
+	}
+	if nonce, err = hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12); err != nil {
+		return nil, nil, err
+	}
+	return cek, nonce, nil
+}
@@ -0,0 +1,55 @@
+package webpush
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"encoding/base64"
+	"testing"
+)
+
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// TestEncryptRFC8291 checks encrypt against the example of RFC 8291,
+// Appendix A.
+func TestEncryptRFC8291(t *testing.T) {
+	plaintext := []byte("When I grow up, I want to be a watermelon")
+	asPrivate, err := ecdh.P256().NewPrivateKey(mustDecode(t, "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := Keys{
+		P256dh: mustDecode(t, "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"),
+		Auth:   mustDecode(t, "BTBZMqHH6r4Tts7J_aSIgg"),
+	}
+	salt := mustDecode(t, "DGv6ra1nlYgDCS1FRnbzlw")
+	want := mustDecode(t, "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN")
+
+	got, err := encrypt(plaintext, keys, asPrivate, salt)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("encrypt() =\n%s\nwant\n%s", base64.RawURLEncoding.EncodeToString(got), base64.RawURLEncoding.EncodeToString(want))
+	}
+}
+
+func TestEncryptRejectsLargePayload(t *testing.T) {
+	uaPrivate, err := ecdh.P256().NewPrivateKey(mustDecode(t, "q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := Keys{P256dh: uaPrivate.PublicKey().Bytes(), Auth: make([]byte, 16)}
+	if _, err := Encrypt(make([]byte, MaxPayloadSize), keys); err != nil {
+		t.Fatalf("Encrypt(MaxPayloadSize): %v", err)
+	}
+	if _, err := Encrypt(make([]byte, MaxPayloadSize+1), keys); err != ErrPayloadTooLarge {
+		t.Fatalf("Encrypt(MaxPayloadSize+1) error = %v, want ErrPayloadTooLarge", err)
+	}
+}
@@ -0,0 +1,107 @@
+package webpush
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// vapidTokenTTL is how long a signed VAPID token is valid; push services
+// reject tokens that expire more than 24 hours ahead.
+const vapidTokenTTL = 12 * time.Hour
+
+// VAPID identifies the application server to push services (RFC 8292).
+type VAPID struct {
+	key       *ecdsa.PrivateKey
+	publicKey string
+	subject   string
+
+	mu     sync.Mutex
+	tokens map[string]vapidToken // by audience
+}
+
+type vapidToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// NewVAPID parses the base64url-encoded raw P-256 private key. subject is a
+// mailto: or https: contact for the push service operator.
+func NewVAPID(privateKey, subject string) (*VAPID, error) {
+	raw, err := decodeBase64(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("webpush: invalid VAPID private key: %v", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("webpush: invalid VAPID private key: %v", err)
+	}
+	public, err := key.PublicKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return &VAPID{
+		key:       key,
+		publicKey: base64.RawURLEncoding.EncodeToString(public),
+		subject:   subject,
+		tokens:    make(map[string]vapidToken),
+	}, nil
+}
+
+// GenerateVAPIDKeys returns a new base64url-encoded VAPID key pair.
+func GenerateVAPIDKeys() (privateKey, publicKey string, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	private, err := key.Bytes()
+	if err != nil {
+		return "", "", err
+	}
+	public, err := key.PublicKey.Bytes()
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(private), base64.RawURLEncoding.EncodeToString(public), nil
+}
+
+// PublicKey is the application server key browsers subscribe with.
+func (v *VAPID) PublicKey() string {
+	return v.publicKey
+}
+
+// Authorization returns the Authorization header value for a push to endpoint.
+func (v *VAPID) Authorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	audience := u.Scheme + "://" + u.Host
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := time.Now()
+	if t, ok := v.tokens[audience]; ok && now.Add(time.Hour).Before(t.expiresAt) {
+		return "vapid t=" + t.value + ", k=" + v.publicKey, nil
+	}
+
+	expiresAt := now.Add(vapidTokenTTL)
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": audience,
+		"exp": expiresAt.Unix(),
+		"sub": v.subject,
+	}).SignedString(v.key)
+	if err != nil {
+		return "", fmt.Errorf("webpush: failed to sign VAPID token: %v", err)
+	}
+	v.tokens[audience] = vapidToken{value: signed, expiresAt: expiresAt}
+	return "vapid t=" + signed + ", k=" + v.publicKey, nil
+}